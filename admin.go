@@ -114,17 +114,11 @@ func cmdGetAgencyState(cmd *cobra.Command, _ []string) {
 			d.GetName())
 	}
 
-	dnsName := k8sutil.CreatePodDNSName(d.GetObjectMeta(), v12.ServerGroupAgents.AsRole(), d.Status.Members.Agents[0].ID)
-	endpoint := getArangoEndpoint(d.Spec.IsSecure(), dnsName)
-	conn := createClient([]string{endpoint}, certCA, auth, connection.ApplicationJSON)
-	leaderID, err := getAgencyLeader(ctx, conn)
+	conn, _, err := getAgencyLeaderConnection(ctx, d, certCA, auth, connection.PlainText)
 	if err != nil {
 		cliLog.Fatal().Err(err).Msg("failed to get leader ID")
 	}
 
-	dnsLeaderName := k8sutil.CreatePodDNSName(d.GetObjectMeta(), v12.ServerGroupAgents.AsRole(), leaderID)
-	leaderEndpoint := getArangoEndpoint(d.Spec.IsSecure(), dnsLeaderName)
-	conn = createClient([]string{leaderEndpoint}, certCA, auth, connection.PlainText)
 	body, err := getAgencyState(ctx, conn)
 	if body != nil {
 		defer body.Close()
@@ -183,9 +177,8 @@ func getAgencyState(ctx context.Context, conn connection.Connection) (io.ReadClo
 func getDeploymentAndCredentials(ctx context.Context,
 	deploymentName string) (d v12.ArangoDeployment, certCA *x509.CertPool, auth connection.Authentication, err error) {
 
-	namespace := os.Getenv(constants.EnvOperatorPodNamespace)
-	if len(namespace) == 0 {
-		err = errors.New(fmt.Sprintf("\"%s\" environment variable missing", constants.EnvOperatorPodNamespace))
+	namespace, err := getNamespace()
+	if err != nil {
 		return
 	}
 
@@ -217,6 +210,16 @@ func getDeploymentAndCredentials(ctx context.Context,
 	return
 }
 
+// getNamespace returns the namespace in which deployments are looked up.
+func getNamespace() (string, error) {
+	namespace := os.Getenv(constants.EnvOperatorPodNamespace)
+	if len(namespace) == 0 {
+		return "", errors.New(fmt.Sprintf("\"%s\" environment variable missing", constants.EnvOperatorPodNamespace))
+	}
+
+	return namespace, nil
+}
+
 // getArangoEndpoint returns ArangoDB endpoint with scheme and port for the given dnsName.
 func getArangoEndpoint(secure bool, dnsName string) string {
 	if secure {
@@ -226,6 +229,27 @@ func getArangoEndpoint(secure bool, dnsName string) string {
 	return "http://" + net.JoinHostPort(dnsName, strconv.Itoa(k8sutil.ArangoPort))
 }
 
+// getAgencyLeaderConnection returns the connection to the leader of the agency and the leader ID.
+func getAgencyLeaderConnection(ctx context.Context, d v12.ArangoDeployment, certCA *x509.CertPool,
+	auth connection.Authentication, contentType string) (connection.Connection, string, error) {
+	if len(d.Status.Members.Agents) == 0 {
+		return nil, "", errors.New(fmt.Sprintf("the deployment \"%s\" does not have agents", d.GetName()))
+	}
+
+	dnsName := k8sutil.CreatePodDNSName(d.GetObjectMeta(), v12.ServerGroupAgents.AsRole(), d.Status.Members.Agents[0].ID)
+	endpoint := getArangoEndpoint(d.Spec.IsSecure(), dnsName)
+	conn := createClient([]string{endpoint}, certCA, auth, connection.ApplicationJSON)
+	leaderID, err := getAgencyLeader(ctx, conn)
+	if err != nil {
+		return nil, "", err
+	}
+
+	dnsLeaderName := k8sutil.CreatePodDNSName(d.GetObjectMeta(), v12.ServerGroupAgents.AsRole(), leaderID)
+	leaderEndpoint := getArangoEndpoint(d.Spec.IsSecure(), dnsLeaderName)
+
+	return createClient([]string{leaderEndpoint}, certCA, auth, contentType), leaderID, nil
+}
+
 // getAgencyLeader returns the leader ID of the agency.
 func getAgencyLeader(ctx context.Context, conn connection.Connection) (string, error) {
 	url := connection.NewUrl("_api", "agency", "config")
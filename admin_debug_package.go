@@ -0,0 +1,595 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/arangodb/go-driver/v2/connection"
+	v12 "github.com/arangodb/kube-arangodb/pkg/apis/deployment/v1"
+	extclient "github.com/arangodb/kube-arangodb/pkg/client"
+	"github.com/arangodb/kube-arangodb/pkg/generated/clientset/versioned"
+	"github.com/arangodb/kube-arangodb/pkg/util/constants"
+	"github.com/arangodb/kube-arangodb/pkg/util/k8sutil"
+	"github.com/arangodb/kube-arangodb/pkg/version"
+)
+
+const (
+	ArgDebugPackageFile     = "file"
+	ArgDebugPackageLogLines = "log-lines"
+	ArgDebugPackageLogBytes = "log-bytes"
+
+	debugPackageManifest = "manifest.json"
+	debugPackageRedacted = "<redacted>"
+)
+
+// debugPackageSensitiveEnv lists parts of environment variable and option names whose values are redacted.
+var debugPackageSensitiveEnv = []string{"TOKEN", "SECRET", "PASSWORD", "KEY", "CREDENTIAL"}
+
+func init() {
+	cmdAdmin.AddCommand(cmdAdminDebugPackage)
+	cmdAdminDebugPackage.Flags().StringP(ArgDeploymentName, "d", "",
+		"necessary when more than one deployment exist within on namespace")
+	cmdAdminDebugPackage.Flags().StringP(ArgDebugPackageFile, "f", "",
+		"path of the created tar.gz file (default \"debug-package-<deployment>-<timestamp>.tar.gz\")")
+	cmdAdminDebugPackage.Flags().Int64(ArgDebugPackageLogLines, 10000,
+		"maximum number of the most recent log lines collected per container")
+	cmdAdminDebugPackage.Flags().Int64(ArgDebugPackageLogBytes, 16*1024*1024,
+		"maximum size in bytes of the logs collected per container")
+}
+
+var cmdAdminDebugPackage = &cobra.Command{
+	Use:   "debug-package",
+	Short: "Collect debug package",
+	Long: "It collects the deployment, members, events, logs, volumes, agency dump and cluster health " +
+		"into one tar.gz file. Values of secrets are redacted",
+	Run: cmdGetDebugPackage,
+}
+
+// debugPackageManifestFile describes a single file of the debug package.
+type debugPackageManifestFile struct {
+	Name      string `json:"name"`
+	Size      int    `json:"size"`
+	Truncated bool   `json:"truncated,omitempty"`
+}
+
+// debugPackageManifestError describes a collector which failed.
+type debugPackageManifestError struct {
+	Collector string `json:"collector"`
+	Error     string `json:"error"`
+}
+
+// debugPackageManifestContent describes the content of the debug package.
+type debugPackageManifestContent struct {
+	Deployment string                      `json:"deployment"`
+	Namespace  string                      `json:"namespace"`
+	Created    time.Time                   `json:"created"`
+	Operator   version.InfoV1              `json:"operator"`
+	Files      []debugPackageManifestFile  `json:"files"`
+	Errors     []debugPackageManifestError `json:"errors,omitempty"`
+}
+
+// debugPackage gathers the files of the debug package. It is safe for concurrent use.
+// debugPackageLogLimits limits the size of the logs collected per container.
+type debugPackageLogLimits struct {
+	lines, bytes int64
+}
+
+type debugPackage struct {
+	lock     sync.Mutex
+	files    map[string][]byte
+	manifest debugPackageManifestContent
+}
+
+// add stores the file with the given name in the package.
+func (p *debugPackage) add(name string, data []byte, truncated bool) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.files[name] = data
+	p.manifest.Files = append(p.manifest.Files, debugPackageManifestFile{
+		Name:      name,
+		Size:      len(data),
+		Truncated: truncated,
+	})
+}
+
+// addYAML stores the object as YAML file with the given name in the package.
+func (p *debugPackage) addYAML(name string, object interface{}) error {
+	data, err := yaml.Marshal(object)
+	if err != nil {
+		return err
+	}
+
+	p.add(name, data, false)
+	return nil
+}
+
+// failed records the error of the given collector in the manifest.
+func (p *debugPackage) failed(collector string, err error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.manifest.Errors = append(p.manifest.Errors, debugPackageManifestError{
+		Collector: collector,
+		Error:     err.Error(),
+	})
+}
+
+// write writes the package with the manifest as tar.gz file.
+func (p *debugPackage) write(fileName string) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	sort.Slice(p.manifest.Files, func(i, j int) bool {
+		return p.manifest.Files[i].Name < p.manifest.Files[j].Name
+	})
+	sort.Slice(p.manifest.Errors, func(i, j int) bool {
+		return p.manifest.Errors[i].Collector < p.manifest.Errors[j].Collector
+	})
+
+	manifest, err := json.MarshalIndent(p.manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(fileName, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	if err := p.writeTo(f, strings.TrimSuffix(path.Base(fileName), ".tar.gz"), manifest); err != nil {
+		f.Close()
+		os.Remove(fileName)
+		return err
+	}
+
+	return f.Close()
+}
+
+// writeTo streams the package as tar.gz with all files in the given directory.
+func (p *debugPackage) writeTo(w io.Writer, dir string, manifest []byte) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	writeFile := func(name string, data []byte) error {
+		header := &tar.Header{
+			Name:    path.Join(dir, name),
+			Mode:    0644,
+			Size:    int64(len(data)),
+			ModTime: p.manifest.Created,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+
+	if err := writeFile(debugPackageManifest, manifest); err != nil {
+		return err
+	}
+	for _, f := range p.manifest.Files {
+		if err := writeFile(f.Name, p.files[f.Name]); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// debugPackageCollector collects a part of the debug package.
+type debugPackageCollector struct {
+	name    string
+	collect func(ctx context.Context, p *debugPackage) error
+}
+
+func cmdGetDebugPackage(cmd *cobra.Command, _ []string) {
+	deploymentName, _ := cmd.Flags().GetString(ArgDeploymentName)
+	fileName, _ := cmd.Flags().GetString(ArgDebugPackageFile)
+	logLimits := debugPackageLogLimits{}
+	logLimits.lines, _ = cmd.Flags().GetInt64(ArgDebugPackageLogLines)
+	logLimits.bytes, _ = cmd.Flags().GetInt64(ArgDebugPackageLogBytes)
+	ctx := getInterruptionContext()
+
+	namespace, err := getNamespace()
+	if err != nil {
+		cliLog.Fatal().Err(err).Msg("failed to get namespace")
+	}
+
+	kubeCli, err := k8sutil.NewKubeClient()
+	if err != nil {
+		cliLog.Fatal().Err(err).Msg("failed to create Kubernetes client")
+	}
+
+	extCli, err := extclient.NewClient()
+	if err != nil {
+		cliLog.Fatal().Err(err).Msg("failed to create Arango extension client")
+	}
+
+	d, err := getDeployment(ctx, namespace, deploymentName)
+	if err != nil {
+		cliLog.Fatal().Err(err).Msg("failed to get deployment")
+	}
+
+	now := time.Now().UTC()
+	if fileName == "" {
+		fileName = fmt.Sprintf("debug-package-%s-%s.tar.gz", d.GetName(), now.Format("20060102-150405"))
+	}
+
+	p := &debugPackage{
+		files: map[string][]byte{},
+		manifest: debugPackageManifestContent{
+			Deployment: d.GetName(),
+			Namespace:  d.GetNamespace(),
+			Created:    now,
+			Operator:   version.GetVersionV1(),
+		},
+	}
+
+	collectors := []debugPackageCollector{
+		{"deployment", func(ctx context.Context, p *debugPackage) error {
+			return p.addYAML("deployment.yaml", d)
+		}},
+		{"members", func(ctx context.Context, p *debugPackage) error {
+			return collectDebugPackageMembers(ctx, p, extCli, d)
+		}},
+		{"events", func(ctx context.Context, p *debugPackage) error {
+			return collectDebugPackageEvents(ctx, p, kubeCli, d)
+		}},
+		{"pods", func(ctx context.Context, p *debugPackage) error {
+			return collectDebugPackagePods(ctx, p, kubeCli, d, logLimits)
+		}},
+		{"operator", func(ctx context.Context, p *debugPackage) error {
+			return collectDebugPackageOperator(ctx, p, kubeCli, namespace, logLimits)
+		}},
+		{"volumes", func(ctx context.Context, p *debugPackage) error {
+			return collectDebugPackageVolumes(ctx, p, kubeCli, d)
+		}},
+		{"secrets", func(ctx context.Context, p *debugPackage) error {
+			return collectDebugPackageSecrets(ctx, p, kubeCli, d)
+		}},
+	}
+	if d.Spec.GetMode() == v12.DeploymentModeCluster {
+		collectors = append(collectors, debugPackageCollector{"agency", func(ctx context.Context, p *debugPackage) error {
+			return collectDebugPackageAgency(ctx, p, d.GetName())
+		}})
+	}
+
+	var wg sync.WaitGroup
+	for _, c := range collectors {
+		wg.Add(1)
+		go func(c debugPackageCollector) {
+			defer wg.Done()
+			if err := c.collect(ctx, p); err != nil {
+				cliLog.Warn().Err(err).Str("collector", c.name).Msg("failed to collect debug information")
+				p.failed(c.name, err)
+			}
+		}(c)
+	}
+	wg.Wait()
+
+	if err := p.write(fileName); err != nil {
+		cliLog.Fatal().Err(err).Msg("failed to write debug package")
+	}
+
+	cliLog.Info().Str("file", fileName).Int("errors", len(p.manifest.Errors)).Msg("debug package created")
+}
+
+// collectDebugPackageMembers collects the ArangoMembers of the deployment.
+func collectDebugPackageMembers(ctx context.Context, p *debugPackage, extCli versioned.Interface,
+	d v12.ArangoDeployment) error {
+	members, err := extCli.DatabaseV1().ArangoMembers(d.GetNamespace()).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	for _, m := range members.Items {
+		if m.Spec.DeploymentUID != "" && m.Spec.DeploymentUID != d.GetUID() {
+			continue
+		}
+		if m.Spec.Template != nil && m.Spec.Template.PodSpec != nil {
+			redactPodSpec(&m.Spec.Template.PodSpec.Spec)
+		}
+		if err := p.addYAML(path.Join("members", m.GetName()+".yaml"), m); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// collectDebugPackageEvents collects the events related to the deployment and its resources.
+func collectDebugPackageEvents(ctx context.Context, p *debugPackage, kubeCli kubernetes.Interface,
+	d v12.ArangoDeployment) error {
+	objects := map[string]bool{d.GetName(): true}
+	d.Status.Members.ForeachServerGroup(func(_ v12.ServerGroup, list v12.MemberStatusList) error {
+		for _, m := range list {
+			objects[m.PodName] = true
+			objects[m.PersistentVolumeClaimName] = true
+		}
+		return nil
+	})
+
+	events, err := kubeCli.CoreV1().Events(d.GetNamespace()).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	result := make([]core.Event, 0, len(events.Items))
+	for _, e := range events.Items {
+		if objects[e.InvolvedObject.Name] {
+			result = append(result, e)
+		}
+	}
+
+	return p.addYAML("events.yaml", result)
+}
+
+// collectDebugPackagePods collects the pods of the deployment and the logs of their containers.
+func collectDebugPackagePods(ctx context.Context, p *debugPackage, kubeCli kubernetes.Interface,
+	d v12.ArangoDeployment, logLimits debugPackageLogLimits) error {
+	pods, err := kubeCli.CoreV1().Pods(d.GetNamespace()).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", k8sutil.LabelKeyArangoDeployment, d.GetName()),
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, pod := range pods.Items {
+		collectDebugPackageLogs(ctx, p, kubeCli, pod, path.Join("pods", pod.GetName()), logLimits)
+
+		redactPodSpec(&pod.Spec)
+		if err := p.addYAML(path.Join("pods", pod.GetName(), "pod.yaml"), pod); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// collectDebugPackageOperator collects the logs of the operator pod in which the command is running.
+func collectDebugPackageOperator(ctx context.Context, p *debugPackage, kubeCli kubernetes.Interface,
+	namespace string, logLimits debugPackageLogLimits) error {
+	name := os.Getenv(constants.EnvOperatorPodName)
+	if len(name) == 0 {
+		return errors.New(fmt.Sprintf("\"%s\" environment variable missing", constants.EnvOperatorPodName))
+	}
+
+	pod, err := kubeCli.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	collectDebugPackageLogs(ctx, p, kubeCli, *pod, path.Join("operator", pod.GetName()), logLimits)
+	return nil
+}
+
+// collectDebugPackageLogs collects the most recent lines of the logs of all containers of the pod, up to the given limits.
+// Logs of the previous instance are collected as well for containers which have been restarted.
+func collectDebugPackageLogs(ctx context.Context, p *debugPackage, kubeCli kubernetes.Interface, pod core.Pod,
+	dir string, logLimits debugPackageLogLimits) {
+	restarted := map[string]bool{}
+	for _, cs := range pod.Status.ContainerStatuses {
+		restarted[cs.Name] = cs.RestartCount > 0
+	}
+
+	collect := func(container, name string, previous bool) {
+		data, err := kubeCli.CoreV1().Pods(pod.GetNamespace()).GetLogs(pod.GetName(), &core.PodLogOptions{
+			Container:  container,
+			TailLines:  &logLimits.lines,
+			LimitBytes: &logLimits.bytes,
+			Previous:   previous,
+		}).DoRaw(ctx)
+		if err != nil {
+			p.failed(path.Join(dir, name), err)
+			return
+		}
+
+		truncated := int64(bytes.Count(data, []byte("\n"))) >= logLimits.lines || int64(len(data)) >= logLimits.bytes
+		p.add(path.Join(dir, name+".log"), data, truncated)
+	}
+
+	for _, c := range pod.Spec.Containers {
+		collect(c.Name, c.Name, false)
+		if restarted[c.Name] {
+			collect(c.Name, c.Name+".previous", true)
+		}
+	}
+}
+
+// collectDebugPackageVolumes collects the PVCs of the deployment and the bound PVs.
+func collectDebugPackageVolumes(ctx context.Context, p *debugPackage, kubeCli kubernetes.Interface,
+	d v12.ArangoDeployment) error {
+	pvcs, err := kubeCli.CoreV1().PersistentVolumeClaims(d.GetNamespace()).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", k8sutil.LabelKeyArangoDeployment, d.GetName()),
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := p.addYAML("pvcs.yaml", pvcs.Items); err != nil {
+		return err
+	}
+
+	pvs := make([]core.PersistentVolume, 0, len(pvcs.Items))
+	for _, pvc := range pvcs.Items {
+		if pvc.Spec.VolumeName == "" {
+			continue
+		}
+
+		pv, err := kubeCli.CoreV1().PersistentVolumes().Get(ctx, pvc.Spec.VolumeName, metav1.GetOptions{})
+		if err != nil {
+			p.failed(path.Join("volumes", pvc.Spec.VolumeName), err)
+			continue
+		}
+		pvs = append(pvs, *pv)
+	}
+
+	return p.addYAML("pvs.yaml", pvs)
+}
+
+// collectDebugPackageSecrets collects the secrets owned by the deployment with redacted values.
+func collectDebugPackageSecrets(ctx context.Context, p *debugPackage, kubeCli kubernetes.Interface,
+	d v12.ArangoDeployment) error {
+	secrets, err := kubeCli.CoreV1().Secrets(d.GetNamespace()).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	result := make([]core.Secret, 0, len(secrets.Items))
+	for _, s := range secrets.Items {
+		if !isOwnedBy(s.GetOwnerReferences(), d.GetUID()) {
+			continue
+		}
+		result = append(result, redactSecret(s))
+	}
+
+	return p.addYAML("secrets.yaml", result)
+}
+
+// collectDebugPackageAgency collects the agency dump and the cluster health.
+func collectDebugPackageAgency(ctx context.Context, p *debugPackage, deploymentName string) error {
+	d, certCA, auth, err := getDeploymentAndCredentials(ctx, deploymentName)
+	if err != nil {
+		return err
+	}
+
+	endpoint := getArangoEndpoint(d.Spec.IsSecure(), k8sutil.CreateDatabaseClientServiceDNSName(d.GetObjectMeta()))
+	conn := createClient([]string{endpoint}, certCA, auth, connection.ApplicationJSON)
+
+	health, err := getClusterHealth(ctx, conn)
+	if err != nil {
+		p.failed("health", err)
+	} else if err := p.addYAML("health.yaml", health); err != nil {
+		return err
+	}
+
+	body, err := getAgencyDump(ctx, conn)
+	if body != nil {
+		defer body.Close()
+	}
+	if err != nil {
+		return err
+	}
+
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	p.add("agency-dump.json", data, false)
+	return nil
+}
+
+// isOwnedBy returns true when one of the owner references points to the object with the given UID.
+func isOwnedBy(owners []metav1.OwnerReference, uid types.UID) bool {
+	for _, o := range owners {
+		if o.UID == uid {
+			return true
+		}
+	}
+	return false
+}
+
+// redactSecret returns the copy of the secret with redacted values. Keys are kept.
+func redactSecret(s core.Secret) core.Secret {
+	result := *s.DeepCopy()
+	result.StringData = nil
+	for k := range result.Data {
+		result.Data[k] = []byte(debugPackageRedacted)
+	}
+	delete(result.Annotations, core.LastAppliedConfigAnnotation)
+
+	return result
+}
+
+// redactPodSpec redacts values of the environment variables and arguments which may contain credentials.
+func redactPodSpec(spec *core.PodSpec) {
+	redact := func(containers []core.Container) {
+		for i := range containers {
+			for j, env := range containers[i].Env {
+				if env.Value != "" && isSensitiveName(env.Name) {
+					containers[i].Env[j].Value = debugPackageRedacted
+				}
+			}
+			redactArgs(containers[i].Command)
+			redactArgs(containers[i].Args)
+		}
+	}
+
+	redact(spec.InitContainers)
+	redact(spec.Containers)
+}
+
+// redactArgs redacts values of the sensitive options given as `--name=value` or `--name value`.
+func redactArgs(args []string) {
+	for i := 0; i < len(args); i++ {
+		if !strings.HasPrefix(args[i], "-") {
+			continue
+		}
+		if k := strings.Index(args[i], "="); k >= 0 {
+			if isSensitiveName(args[i][:k]) {
+				args[i] = args[i][:k+1] + debugPackageRedacted
+			}
+			continue
+		}
+		if isSensitiveName(args[i]) && i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+			args[i+1] = debugPackageRedacted
+			i++
+		}
+	}
+}
+
+// isSensitiveName returns true if the name of the variable or option indicates that its value may contain credentials.
+func isSensitiveName(name string) bool {
+	name = strings.ToUpper(name)
+	for _, s := range debugPackageSensitiveEnv {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return false
+}
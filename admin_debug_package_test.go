@@ -0,0 +1,177 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRedactSecret(t *testing.T) {
+	s := core.Secret{
+		ObjectMeta: meta.ObjectMeta{
+			Name: "secret",
+			Annotations: map[string]string{
+				core.LastAppliedConfigAnnotation: `{"data":{"password":"c2VjcmV0"}}`,
+				"other":                          "value",
+			},
+		},
+		Data: map[string][]byte{
+			"username": []byte("root"),
+			"password": []byte("secret"),
+		},
+		StringData: map[string]string{
+			"token": "secret",
+		},
+	}
+
+	r := redactSecret(s)
+
+	assert.Equal(t, map[string][]byte{
+		"username": []byte(debugPackageRedacted),
+		"password": []byte(debugPackageRedacted),
+	}, r.Data)
+	assert.Nil(t, r.StringData)
+	assert.Equal(t, map[string]string{"other": "value"}, r.Annotations)
+
+	// The source secret is not modified
+	assert.Equal(t, []byte("secret"), s.Data["password"])
+	assert.Contains(t, s.Annotations, core.LastAppliedConfigAnnotation)
+}
+
+func TestRedactPodSpec(t *testing.T) {
+	spec := core.PodSpec{
+		InitContainers: []core.Container{
+			{
+				Name: "init",
+				Env: []core.EnvVar{
+					{Name: "ARANGODB_JWT_TOKEN", Value: "secret"},
+				},
+			},
+		},
+		Containers: []core.Container{
+			{
+				Name:    "server",
+				Command: []string{"/usr/sbin/arangod", "--server.jwt-secret=secret", "--database.directory=/data"},
+				Args:    []string{"--sync.master.keyfile", "secret", "--log.level", "INFO", "--auth.password"},
+				Env: []core.EnvVar{
+					{Name: "ROOT_PASSWORD", Value: "secret"},
+					{Name: "MY_POD_NAME", Value: "pod"},
+					{Name: "API_KEY", ValueFrom: &core.EnvVarSource{
+						SecretKeyRef: &core.SecretKeySelector{Key: "key"},
+					}},
+				},
+			},
+		},
+	}
+
+	redactPodSpec(&spec)
+
+	assert.Equal(t, debugPackageRedacted, spec.InitContainers[0].Env[0].Value)
+
+	c := spec.Containers[0]
+	assert.Equal(t, []string{"/usr/sbin/arangod", "--server.jwt-secret=" + debugPackageRedacted, "--database.directory=/data"}, c.Command)
+	assert.Equal(t, []string{"--sync.master.keyfile", debugPackageRedacted, "--log.level", "INFO", "--auth.password"}, c.Args)
+	assert.Equal(t, debugPackageRedacted, c.Env[0].Value)
+	assert.Equal(t, "pod", c.Env[1].Value)
+	assert.Equal(t, "", c.Env[2].Value)
+	assert.NotNil(t, c.Env[2].ValueFrom)
+}
+
+func TestDebugPackageWriteTo(t *testing.T) {
+	p := &debugPackage{files: map[string][]byte{}}
+	p.add("pods/pod-1/server.log", []byte("line\n"), false)
+
+	var buffer bytes.Buffer
+	require.NoError(t, p.writeTo(&buffer, "package", []byte("{}")))
+
+	gz, err := gzip.NewReader(&buffer)
+	require.NoError(t, err)
+	tr := tar.NewReader(gz)
+
+	files := map[string]string{}
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+
+		data, err := ioutil.ReadAll(tr)
+		require.NoError(t, err)
+		files[header.Name] = string(data)
+	}
+
+	assert.Equal(t, map[string]string{
+		"package/" + debugPackageManifest: "{}",
+		"package/pods/pod-1/server.log":   "line\n",
+	}, files)
+}
+
+func TestCollectDebugPackageLogs(t *testing.T) {
+	pod := core.Pod{
+		ObjectMeta: meta.ObjectMeta{Name: "pod-1", Namespace: "ns"},
+		Spec: core.PodSpec{
+			Containers: []core.Container{{Name: "server"}, {Name: "exporter"}},
+		},
+		Status: core.PodStatus{
+			ContainerStatuses: []core.ContainerStatus{{Name: "server", RestartCount: 1}},
+		},
+	}
+	kubeCli := fake.NewSimpleClientset(&pod)
+
+	truncated := func(limits debugPackageLogLimits) map[string]bool {
+		p := &debugPackage{files: map[string][]byte{}}
+		collectDebugPackageLogs(context.Background(), p, kubeCli, pod, "pods/pod-1", limits)
+		require.Empty(t, p.manifest.Errors)
+
+		result := map[string]bool{}
+		for _, f := range p.manifest.Files {
+			require.NotZero(t, f.Size)
+			result[f.Name] = f.Truncated
+		}
+		return result
+	}
+
+	assert.Equal(t, map[string]bool{
+		"pods/pod-1/server.log":          false,
+		"pods/pod-1/server.previous.log": false,
+		"pods/pod-1/exporter.log":        false,
+	}, truncated(debugPackageLogLimits{lines: 100, bytes: 1024}))
+
+	// Logs reaching the size limit are reported as truncated
+	assert.Equal(t, map[string]bool{
+		"pods/pod-1/server.log":          true,
+		"pods/pod-1/server.previous.log": true,
+		"pods/pod-1/exporter.log":        true,
+	}, truncated(debugPackageLogLimits{lines: 100, bytes: 4}))
+}
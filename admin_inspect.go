@@ -0,0 +1,403 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	driver "github.com/arangodb/go-driver"
+	"github.com/arangodb/go-driver/v2/connection"
+	v12 "github.com/arangodb/kube-arangodb/pkg/apis/deployment/v1"
+	"github.com/arangodb/kube-arangodb/pkg/deployment/agency"
+	"github.com/arangodb/kube-arangodb/pkg/util/k8sutil"
+)
+
+const (
+	ArgOutput = "output"
+
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+func init() {
+	for _, cmd := range []*cobra.Command{cmdAdminMembers, cmdAdminPlan, cmdAdminShards, cmdAdminHealth} {
+		cmdAdmin.AddCommand(cmd)
+		cmd.Flags().StringP(ArgDeploymentName, "d", "",
+			"necessary when more than one deployment exist within on namespace")
+		cmd.Flags().StringP(ArgOutput, "o", outputTable, "output format, one of: table, json")
+	}
+}
+
+var cmdAdminMembers = &cobra.Command{
+	Use:   "members",
+	Short: "List members of the deployment",
+	Long:  "It prints the members of the deployment with their phase, conditions, pod, PVC and image",
+	Run:   cmdGetMembers,
+}
+
+var cmdAdminPlan = &cobra.Command{
+	Use:   "plan",
+	Short: "Show plan of the deployment",
+	Long:  "It prints the high priority plan and the plan of the deployment in the order of execution",
+	Run:   cmdGetPlan,
+}
+
+var cmdAdminShards = &cobra.Command{
+	Use:   "shards",
+	Short: "Show shard distribution",
+	Long:  "It prints the number of leader and follower shards per DBServer according to the agency Plan and Current",
+	Run:   cmdGetShards,
+}
+
+var cmdAdminHealth = &cobra.Command{
+	Use:   "health",
+	Short: "Show cluster health",
+	Long:  "It prints the cluster health of all servers and marks the agency leader",
+	Run:   cmdGetHealth,
+}
+
+// memberInfo describes a member of the deployment.
+type memberInfo struct {
+	Group      string   `json:"group"`
+	ID         string   `json:"id"`
+	Phase      string   `json:"phase"`
+	Conditions []string `json:"conditions,omitempty"`
+	Pod        string   `json:"pod,omitempty"`
+	PVC        string   `json:"pvc,omitempty"`
+	Image      string   `json:"image,omitempty"`
+}
+
+// planActionInfo describes an action of the deployment plan.
+type planActionInfo struct {
+	Priority string `json:"priority"`
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Group    string `json:"group,omitempty"`
+	MemberID string `json:"memberID,omitempty"`
+	Started  bool   `json:"started"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// serverHealthInfo describes the health of a single server of the cluster.
+type serverHealthInfo struct {
+	ID            string `json:"id"`
+	ShortName     string `json:"shortName,omitempty"`
+	Role          string `json:"role"`
+	Status        string `json:"status"`
+	SyncStatus    string `json:"syncStatus,omitempty"`
+	Version       string `json:"version,omitempty"`
+	AgencyLeader  bool   `json:"agencyLeader,omitempty"`
+	LastHeartbeat string `json:"lastHeartbeat,omitempty"`
+}
+
+// clusterHealthInfo describes the health of the cluster.
+type clusterHealthInfo struct {
+	ClusterID    string             `json:"clusterID"`
+	AgencyLeader string             `json:"agencyLeader,omitempty"`
+	Servers      []serverHealthInfo `json:"servers"`
+}
+
+func cmdGetMembers(cmd *cobra.Command, _ []string) {
+	output := getOutputFormat(cmd)
+	d := getInspectedDeployment(cmd)
+
+	var members []memberInfo
+	d.Status.Members.ForeachServerGroup(func(group v12.ServerGroup, list v12.MemberStatusList) error {
+		for _, m := range list {
+			info := memberInfo{
+				Group: group.AsRole(),
+				ID:    m.ID,
+				Phase: string(m.Phase),
+				Pod:   m.PodName,
+				PVC:   m.PersistentVolumeClaimName,
+			}
+			for _, c := range m.Conditions {
+				if c.IsTrue() {
+					info.Conditions = append(info.Conditions, string(c.Type))
+				}
+			}
+			if m.Image != nil {
+				info.Image = m.Image.Image
+			}
+			members = append(members, info)
+		}
+		return nil
+	})
+
+	err := printOutput(output, members, []string{"GROUP", "ID", "PHASE", "CONDITIONS", "POD", "PVC", "IMAGE"},
+		func() [][]string {
+			rows := make([][]string, 0, len(members))
+			for _, m := range members {
+				rows = append(rows, []string{m.Group, m.ID, m.Phase, strings.Join(m.Conditions, ","), m.Pod, m.PVC, m.Image})
+			}
+			return rows
+		})
+	if err != nil {
+		cliLog.Fatal().Err(err).Msg("failed to print members")
+	}
+}
+
+func cmdGetPlan(cmd *cobra.Command, _ []string) {
+	output := getOutputFormat(cmd)
+	d := getInspectedDeployment(cmd)
+
+	actions := getPlanActions(d.Status.HighPriorityPlan, d.Status.Plan)
+
+	if err := printPlanActions(output, actions); err != nil {
+		cliLog.Fatal().Err(err).Msg("failed to print plan")
+	}
+}
+
+// getPlanActions returns the actions of the high priority plan and the plan in the order of execution.
+func getPlanActions(highPriorityPlan, plan v12.Plan) []planActionInfo {
+	actions := make([]planActionInfo, 0, len(highPriorityPlan)+len(plan))
+	for _, p := range []struct {
+		priority string
+		plan     v12.Plan
+	}{{"high", highPriorityPlan}, {"normal", plan}} {
+		for _, a := range p.plan {
+			actions = append(actions, planActionInfo{
+				Priority: p.priority,
+				ID:       a.ID,
+				Type:     string(a.Type),
+				Group:    a.Group.AsRole(),
+				MemberID: a.MemberID,
+				Started:  a.StartTime != nil,
+				Reason:   a.Reason,
+			})
+		}
+	}
+
+	return actions
+}
+
+// printPlanActions prints the plan actions in the given output format.
+func printPlanActions(output string, actions []planActionInfo) error {
+	return printOutput(output, actions, []string{"PRIORITY", "ID", "TYPE", "GROUP", "MEMBER", "STARTED", "REASON"},
+		func() [][]string {
+			rows := make([][]string, 0, len(actions))
+			for _, a := range actions {
+				rows = append(rows, []string{a.Priority, a.ID, a.Type, a.Group, a.MemberID, fmt.Sprintf("%t", a.Started), a.Reason})
+			}
+			return rows
+		})
+}
+
+func cmdGetShards(cmd *cobra.Command, _ []string) {
+	output := getOutputFormat(cmd)
+	deploymentName, _ := cmd.Flags().GetString(ArgDeploymentName)
+	ctx := getInterruptionContext()
+	d, certCA, auth, err := getDeploymentAndCredentials(ctx, deploymentName)
+	if err != nil {
+		cliLog.Fatal().Err(err).Msg("failed to create basic data for the connection")
+	}
+
+	if d.Spec.GetMode() != v12.DeploymentModeCluster {
+		cliLog.Fatal().Msgf("shards do not work for the \"%s\" deployment \"%s\"", d.Spec.GetMode(),
+			d.GetName())
+	}
+
+	conn, _, err := getAgencyLeaderConnection(ctx, d, certCA, auth, connection.ApplicationJSON)
+	if err != nil {
+		cliLog.Fatal().Err(err).Msg("failed to get leader ID")
+	}
+
+	state, err := getAgencyCollections(ctx, conn)
+	if err != nil {
+		cliLog.Fatal().Err(err).Msg("can not get collections from the agency")
+	}
+
+	shards := state.GetShardDistribution()
+	err = printOutput(output, shards, []string{"DBSERVER", "LEADERS", "FOLLOWERS", "OUT-OF-SYNC"},
+		func() [][]string {
+			rows := make([][]string, 0, len(shards))
+			for _, s := range shards {
+				rows = append(rows, []string{s.Server, fmt.Sprintf("%d", s.Leaders), fmt.Sprintf("%d", s.Followers),
+					fmt.Sprintf("%d", s.OutOfSync)})
+			}
+			return rows
+		})
+	if err != nil {
+		cliLog.Fatal().Err(err).Msg("failed to print shards")
+	}
+}
+
+func cmdGetHealth(cmd *cobra.Command, _ []string) {
+	output := getOutputFormat(cmd)
+	deploymentName, _ := cmd.Flags().GetString(ArgDeploymentName)
+	ctx := getInterruptionContext()
+	d, certCA, auth, err := getDeploymentAndCredentials(ctx, deploymentName)
+	if err != nil {
+		cliLog.Fatal().Err(err).Msg("failed to create basic data for the connection")
+	}
+
+	if d.Spec.GetMode() != v12.DeploymentModeCluster {
+		cliLog.Fatal().Msgf("cluster health does not work for the \"%s\" deployment \"%s\"", d.Spec.GetMode(),
+			d.GetName())
+	}
+
+	_, leaderID, err := getAgencyLeaderConnection(ctx, d, certCA, auth, connection.ApplicationJSON)
+	if err != nil {
+		cliLog.Fatal().Err(err).Msg("failed to get leader ID")
+	}
+
+	endpoint := getArangoEndpoint(d.Spec.IsSecure(), k8sutil.CreateDatabaseClientServiceDNSName(d.GetObjectMeta()))
+	conn := createClient([]string{endpoint}, certCA, auth, connection.ApplicationJSON)
+	health, err := getClusterHealth(ctx, conn)
+	if err != nil {
+		cliLog.Fatal().Err(err).Msg("can not get cluster health")
+	}
+
+	info := clusterHealthInfo{
+		ClusterID:    health.ID,
+		AgencyLeader: leaderID,
+		Servers:      make([]serverHealthInfo, 0, len(health.Health)),
+	}
+	for id, s := range health.Health {
+		server := serverHealthInfo{
+			ID:           string(id),
+			ShortName:    s.ShortName,
+			Role:         string(s.Role),
+			Status:       string(s.Status),
+			SyncStatus:   string(s.SyncStatus),
+			Version:      string(s.Version),
+			AgencyLeader: string(id) == leaderID,
+		}
+		if !s.LastHeartbeatAcked.IsZero() {
+			server.LastHeartbeat = s.LastHeartbeatAcked.String()
+		}
+		info.Servers = append(info.Servers, server)
+	}
+	sort.Slice(info.Servers, func(i, j int) bool {
+		if info.Servers[i].Role != info.Servers[j].Role {
+			return info.Servers[i].Role < info.Servers[j].Role
+		}
+		return info.Servers[i].ID < info.Servers[j].ID
+	})
+
+	err = printOutput(output, info, []string{"ID", "NAME", "ROLE", "STATUS", "SYNC", "VERSION", "LEADER"},
+		func() [][]string {
+			rows := make([][]string, 0, len(info.Servers))
+			for _, s := range info.Servers {
+				leader := ""
+				if s.AgencyLeader {
+					leader = "*"
+				}
+				rows = append(rows, []string{s.ID, s.ShortName, s.Role, s.Status, s.SyncStatus, s.Version, leader})
+			}
+			return rows
+		})
+	if err != nil {
+		cliLog.Fatal().Err(err).Msg("failed to print cluster health")
+	}
+}
+
+// getOutputFormat returns the requested output format or exits when it is not supported.
+func getOutputFormat(cmd *cobra.Command) string {
+	output, _ := cmd.Flags().GetString(ArgOutput)
+	switch output {
+	case outputTable, outputJSON:
+		return output
+	default:
+		cliLog.Fatal().Msgf("unsupported output format \"%s\"", output)
+		return ""
+	}
+}
+
+// getInspectedDeployment returns the deployment selected with the deployment name flag.
+func getInspectedDeployment(cmd *cobra.Command) v12.ArangoDeployment {
+	deploymentName, _ := cmd.Flags().GetString(ArgDeploymentName)
+	namespace, err := getNamespace()
+	if err != nil {
+		cliLog.Fatal().Err(err).Msg("failed to get namespace")
+	}
+
+	d, err := getDeployment(getInterruptionContext(), namespace, deploymentName)
+	if err != nil {
+		cliLog.Fatal().Err(err).Msg("failed to get deployment")
+	}
+
+	return d
+}
+
+// printOutput prints the data as JSON or as a table with the given header and rows.
+func printOutput(output string, data interface{}, header []string, rows func() [][]string) error {
+	if output == outputJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(data)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, row := range rows() {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+
+	return w.Flush()
+}
+
+// getAgencyCollections returns the Plan and Current collections from the agency.
+func getAgencyCollections(ctx context.Context, conn connection.Connection) (agency.State, error) {
+	url := connection.NewUrl("_api", "agency", "read")
+	request := agency.GetAgencyReadRequest(agency.GetAgencyReadKey(
+		agency.GetAgencyKey(agency.ArangoKey, agency.PlanKey, agency.PlanCollectionsKey),
+		agency.GetAgencyKey(agency.ArangoKey, agency.CurrentKey, agency.PlanCollectionsKey)))
+
+	var output agency.StateRoots
+	resp, err := connection.CallPost(ctx, conn, url, &output, request)
+	if err != nil {
+		return agency.State{}, err
+	}
+	if resp.Code() != http.StatusOK {
+		return agency.State{}, errors.New(fmt.Sprintf("unexpected HTTP status from \"%s\" endpoint", url))
+	}
+	if len(output) != 1 {
+		return agency.State{}, errors.New("unexpected agency read response")
+	}
+
+	return output[0].Arango, nil
+}
+
+// getClusterHealth returns the health of the cluster.
+func getClusterHealth(ctx context.Context, conn connection.Connection) (driver.ClusterHealth, error) {
+	url := connection.NewUrl("_admin", "cluster", "health")
+	var output driver.ClusterHealth
+	resp, err := connection.CallGet(ctx, conn, url, &output)
+	if err != nil {
+		return driver.ClusterHealth{}, err
+	}
+	if resp.Code() != http.StatusOK {
+		return driver.ClusterHealth{}, errors.New(fmt.Sprintf("unexpected HTTP status from \"%s\" endpoint", url))
+	}
+
+	return output, nil
+}
@@ -32,6 +32,7 @@ import (
 
 	"github.com/arangodb/kube-arangodb/pkg/exporter"
 	"github.com/arangodb/kube-arangodb/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
@@ -86,7 +87,7 @@ func onSigterm(f func()) {
 }
 
 func cmdExporterCheckE() error {
-	p, err := exporter.NewPassthru(exporterInput.endpoint, func() (string, error) {
+	auth := func() (string, error) {
 		if exporterInput.jwtFile == "" {
 			return "", nil
 		}
@@ -97,23 +98,19 @@ func cmdExporterCheckE() error {
 		}
 
 		return string(data), nil
-	}, false, 15*time.Second)
-	if err != nil {
-		return err
 	}
 
-	mon := exporter.NewMonitor(exporterInput.endpoint, func() (string, error) {
-		if exporterInput.jwtFile == "" {
-			return "", nil
-		}
+	mon := exporter.NewMonitor(exporterInput.endpoint, auth, false, 15*time.Second)
 
-		data, err := ioutil.ReadFile(exporterInput.jwtFile)
-		if err != nil {
-			return "", err
-		}
+	registry := prometheus.NewRegistry()
+	if err := registry.Register(mon); err != nil {
+		return err
+	}
 
-		return string(data), nil
-	}, false, 15*time.Second)
+	p, err := exporter.NewPassthru(exporterInput.endpoint, auth, false, 15*time.Second, registry)
+	if err != nil {
+		return err
+	}
 
 	go mon.UpdateMonitorStatus(util.CreateSignalContext(context.Background()))
 
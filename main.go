@@ -44,6 +44,7 @@ import (
 	"github.com/arangodb/kube-arangodb/pkg/operator/scope"
 
 	"github.com/arangodb/kube-arangodb/pkg/deployment/features"
+	"github.com/arangodb/kube-arangodb/pkg/deployment/queue"
 
 	"github.com/rs/zerolog/log"
 
@@ -108,6 +109,9 @@ var (
 		tlsSecretName   string
 		adminSecretName string // Name of basic authentication secret containing the admin username+password of the dashboard
 		allowAnonymous  bool   // If set, anonymous access to dashboard is allowed
+		scrapeTimeout   time.Duration
+		authMode        string
+		oidc            server.OIDCConfig
 	}
 	operatorOptions struct {
 		enableDeployment            bool // Run deployment operator
@@ -117,6 +121,7 @@ var (
 		versionOnly                 bool // Run only version endpoint, explicitly disabled with other
 
 		scalingIntegrationEnabled bool
+		deploymentWorkers         int
 
 		alpineImage, metricsExporterImage, arangoImage string
 
@@ -152,6 +157,17 @@ func init() {
 	f.StringVar(&serverOptions.tlsSecretName, "server.tls-secret-name", "", "Name of secret containing tls.crt & tls.key for HTTPS server (if empty, self-signed certificate is used)")
 	f.StringVar(&serverOptions.adminSecretName, "server.admin-secret-name", defaultAdminSecretName, "Name of secret containing username + password for login to the dashboard")
 	f.BoolVar(&serverOptions.allowAnonymous, "server.allow-anonymous-access", false, "Allow anonymous access to the dashboard")
+	f.StringVar(&serverOptions.authMode, "server.auth-mode", server.AuthModeBasic, "Authentication mode of the dashboard (basic|kubernetes|oidc)")
+	f.StringVar(&serverOptions.oidc.IssuerURL, "server.oidc.issuer-url", "", "URL of the OpenID Connect issuer used in oidc authentication mode")
+	f.StringVar(&serverOptions.oidc.ClientID, "server.oidc.client-id", "", "OpenID Connect client ID of the dashboard")
+	f.StringVar(&serverOptions.oidc.ClientSecretName, "server.oidc.client-secret-name", "", fmt.Sprintf("Name of secret containing the OpenID Connect client secret in the '%s' field", server.OIDCClientSecretKey))
+	f.StringVar(&serverOptions.oidc.SessionSecretName, "server.oidc.session-secret-name", server.DefaultOIDCSessionSecretName, fmt.Sprintf("Name of secret containing the key signing the dashboard sessions in the '%s' field (created if missing)", server.OIDCSessionKeyKey))
+	f.StringVar(&serverOptions.oidc.RedirectURL, "server.oidc.redirect-url", "", fmt.Sprintf("External URL of the dashboard OpenID Connect callback (https://<host>%s)", server.OIDCCallbackPath))
+	f.StringSliceVar(&serverOptions.oidc.Scopes, "server.oidc.scopes", []string{"profile", "email"}, "OpenID Connect scopes requested in addition to 'openid'")
+	f.StringVar(&serverOptions.oidc.GroupsClaim, "server.oidc.groups-claim", server.DefaultOIDCGroupsClaim, "ID token claim containing the groups of the user")
+	f.StringSliceVar(&serverOptions.oidc.AdminGroups, "server.oidc.admin-groups", nil, "Groups with read-write access to the dashboard")
+	f.StringSliceVar(&serverOptions.oidc.ReadOnlyGroups, "server.oidc.read-only-groups", nil, "Groups with read-only access to the dashboard")
+	f.DurationVar(&serverOptions.scrapeTimeout, "server.metrics-scrape-timeout", server.DefaultMetricsScrapeTimeout, "Timeout of a single member scrape of the aggregated deployment metrics endpoint")
 	f.StringArrayVar(&logLevels, "log.level", []string{defaultLogLevel}, fmt.Sprintf("Set log levels in format <level> or <logger>=<level>. Possible loggers: %s", strings.Join(logging.LoggerNames(), ", ")))
 	f.BoolVar(&operatorOptions.enableDeployment, "operator.deployment", false, "Enable to run the ArangoDeployment operator")
 	f.BoolVar(&operatorOptions.enableDeploymentReplication, "operator.deployment-replication", false, "Enable to run the ArangoDeploymentReplication operator")
@@ -169,6 +185,7 @@ func init() {
 	f.DurationVar(&operatorTimeouts.arangoD, "timeout.arangod", globals.DefaultArangoDTimeout, "The request timeout to the ArangoDB")
 	f.DurationVar(&operatorTimeouts.reconciliation, "timeout.reconciliation", globals.DefaultReconciliationTimeout, "The reconciliation timeout to the ArangoDB CR")
 	f.BoolVar(&operatorOptions.scalingIntegrationEnabled, "internal.scaling-integration", true, "Enable Scaling Integration")
+	f.IntVar(&operatorOptions.deploymentWorkers, "operator.deployment-workers", queue.DefaultWorkers, "Number of ArangoDeployments reconciled at once (0 reconciles all deployments in parallel)")
 	f.Int64Var(&operatorKubernetesOptions.maxBatchSize, "kubernetes.max-batch-size", globals.DefaultKubernetesRequestBatchSize, "Size of batch during objects read")
 	f.IntVar(&operatorBackup.concurrentUploads, "backup-concurrent-uploads", globals.DefaultBackupConcurrentUploads, "Number of concurrent uploads per deployment")
 	features.Init(&cmdMain)
@@ -279,8 +296,13 @@ func cmdMainRun(cmd *cobra.Command, args []string) {
 			PodIP:              ip,
 			AdminSecretName:    serverOptions.adminSecretName,
 			AllowAnonymous:     serverOptions.allowAnonymous,
+			AuthMode:           serverOptions.authMode,
+			OIDC:               serverOptions.oidc,
+
+			MetricsScrapeTimeout: serverOptions.scrapeTimeout,
 		}, server.Dependencies{
 			Log:           logService.MustGetLogger(logging.LoggerNameServer),
+			AuditLog:      logService.MustGetLogger(logging.LoggerNameAudit),
 			LivenessProbe: &livenessProbe,
 			Deployment: server.OperatorDependency{
 				Enabled: cfg.EnableDeployment,
@@ -300,7 +322,9 @@ func cmdMainRun(cmd *cobra.Command, args []string) {
 			},
 			Operators: o,
 
-			Secrets: secrets,
+			Secrets:              secrets,
+			TokenReviews:         kubecli.AuthenticationV1().TokenReviews(),
+			SubjectAccessReviews: kubecli.AuthorizationV1().SubjectAccessReviews(),
 		}); err != nil {
 			cliLog.Fatal().Err(err).Msg("Failed to create HTTP server")
 		} else {
@@ -386,6 +410,7 @@ func newOperatorConfigAndDeps(id, namespace, name string) (operator.Config, oper
 		EnableBackup:                operatorOptions.enableBackup,
 		AllowChaos:                  chaosOptions.allowed,
 		ScalingIntegrationEnabled:   operatorOptions.scalingIntegrationEnabled,
+		DeploymentWorkers:           operatorOptions.deploymentWorkers,
 		ArangoImage:                 operatorOptions.arangoImage,
 		SingleMode:                  operatorOptions.singleMode,
 		Scope:                       scope,
@@ -84,6 +84,12 @@ const (
 
 	// ConditionTypeTopologyAware indicates that the member is deployed with TopologyAwareness.
 	ConditionTypeTopologyAware ConditionType = "TopologyAware"
+
+	// ConditionTypeNodeDrain indicates that the member is scheduled on a cordoned or tainted node and has to be relocated.
+	ConditionTypeNodeDrain ConditionType = "NodeDrain"
+
+	// ConditionTypeSuspended indicates that the member is shut down because the deployment is suspended.
+	ConditionTypeSuspended ConditionType = "Suspended"
 )
 
 // Condition represents one current condition of a deployment or deployment member.
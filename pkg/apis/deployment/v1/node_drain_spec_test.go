@@ -0,0 +1,58 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package v1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	core "k8s.io/api/core/v1"
+
+	"github.com/arangodb/kube-arangodb/pkg/util"
+)
+
+func TestDeploymentNodeDrainSpec_IsNodeDrained(t *testing.T) {
+	var spec *DeploymentNodeDrainSpec
+	require.False(t, spec.IsEnabled())
+	require.False(t, (&DeploymentNodeDrainSpec{}).IsEnabled())
+	require.True(t, (&DeploymentNodeDrainSpec{Enabled: util.NewBool(true)}).IsEnabled())
+
+	drained, _ := spec.IsNodeDrained(&core.Node{})
+	require.False(t, drained)
+
+	drained, _ = spec.IsNodeDrained(&core.Node{Spec: core.NodeSpec{Unschedulable: true}})
+	require.True(t, drained)
+
+	tainted := &core.Node{Spec: core.NodeSpec{Taints: []core.Taint{{Key: "upgrade", Effect: core.TaintEffectNoSchedule}}}}
+	drained, _ = spec.IsNodeDrained(tainted)
+	require.False(t, drained)
+
+	spec = &DeploymentNodeDrainSpec{Taints: []string{"upgrade"}}
+	drained, reason := spec.IsNodeDrained(tainted)
+	require.True(t, drained)
+	require.Equal(t, "Node has maintenance taint upgrade", reason)
+
+	drained, _ = spec.IsNodeDrained(&core.Node{Spec: core.NodeSpec{Taints: []core.Taint{{Key: "upgrade", Effect: core.TaintEffectPreferNoSchedule}}}})
+	require.False(t, drained)
+
+	require.NoError(t, spec.Validate())
+	require.Error(t, (&DeploymentNodeDrainSpec{Parallel: util.NewInt(0)}).Validate())
+}
@@ -534,6 +534,37 @@ func (in *DeploymentFeatures) DeepCopy() *DeploymentFeatures {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentNodeDrainSpec) DeepCopyInto(out *DeploymentNodeDrainSpec) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Parallel != nil {
+		in, out := &in.Parallel, &out.Parallel
+		*out = new(int)
+		**out = **in
+	}
+	if in.Taints != nil {
+		in, out := &in.Taints, &out.Taints
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentNodeDrainSpec.
+func (in *DeploymentNodeDrainSpec) DeepCopy() *DeploymentNodeDrainSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentNodeDrainSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DeploymentRestoreResult) DeepCopyInto(out *DeploymentRestoreResult) {
 	*out = *in
@@ -723,6 +754,16 @@ func (in *DeploymentSpec) DeepCopyInto(out *DeploymentSpec) {
 		*out = new(ArangoDeploymentRebalancerSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.NodeDrain != nil {
+		in, out := &in.NodeDrain, &out.NodeDrain
+		*out = new(DeploymentNodeDrainSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Suspended != nil {
+		in, out := &in.Suspended, &out.Suspended
+		*out = new(bool)
+		**out = **in
+	}
 	return
 }
 
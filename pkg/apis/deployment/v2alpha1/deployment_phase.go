@@ -34,9 +34,17 @@ const (
 	// DeploymentPhaseFailed indicates that a deployment is in a failed state
 	// from which automatic recovery is impossible. Inspect `Reason` for more info.
 	DeploymentPhaseFailed DeploymentPhase = "Failed"
+	// DeploymentPhaseSuspended indicates that all members of the deployment are shut down
+	// because the deployment is suspended.
+	DeploymentPhaseSuspended DeploymentPhase = "Suspended"
 )
 
 // IsFailed returns true if given state is DeploymentStateFailed
 func (cs DeploymentPhase) IsFailed() bool {
 	return cs == DeploymentPhaseFailed
 }
+
+// IsSuspended returns true if given state is DeploymentPhaseSuspended
+func (cs DeploymentPhase) IsSuspended() bool {
+	return cs == DeploymentPhaseSuspended
+}
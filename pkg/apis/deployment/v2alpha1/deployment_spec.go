@@ -169,6 +169,21 @@ type DeploymentSpec struct {
 
 	// Rebalancer define the rebalancer specification
 	Rebalancer *ArangoDeploymentRebalancerSpec `json:"rebalancer,omitempty"`
+
+	// NodeDrain define relocation of members scheduled on cordoned or tainted nodes
+	NodeDrain *DeploymentNodeDrainSpec `json:"nodeDrain,omitempty"`
+
+	// Suspended shuts down all members of the deployment while keeping its data
+	Suspended *bool `json:"suspended,omitempty"`
+}
+
+// IsSuspended returns true when all members of the deployment should be shut down
+func (s DeploymentSpec) IsSuspended() bool {
+	if s.Suspended == nil {
+		return false
+	}
+
+	return *s.Suspended
 }
 
 // GetAllowMemberRecreation returns member recreation policy based on group and settings
@@ -462,6 +477,9 @@ func (s *DeploymentSpec) Validate() error {
 	if err := s.Bootstrap.Validate(); err != nil {
 		return errors.WithStack(err)
 	}
+	if err := s.NodeDrain.Validate(); err != nil {
+		return errors.WithStack(errors.Wrap(err, "spec.nodeDrain"))
+	}
 	return nil
 }
 
@@ -0,0 +1,101 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package v2alpha1
+
+import (
+	"github.com/arangodb/kube-arangodb/pkg/util/errors"
+	core "k8s.io/api/core/v1"
+)
+
+// DeploymentNodeDrainSpec defines how members scheduled on drained nodes are relocated
+type DeploymentNodeDrainSpec struct {
+	// Enabled enables relocation of members from cordoned nodes and nodes with maintenance taints (default false)
+	Enabled *bool `json:"enabled,omitempty"`
+	// Parallel defines how many members can be relocated at the same time
+	Parallel *int `json:"parallel,omitempty"`
+	// Taints defines keys of NoSchedule and NoExecute taints which mark a node as drained
+	Taints []string `json:"taints,omitempty"`
+}
+
+// IsEnabled returns true when relocation of members from drained nodes is enabled
+func (n *DeploymentNodeDrainSpec) IsEnabled() bool {
+	if n == nil || n.Enabled == nil {
+		return false
+	}
+
+	return *n.Enabled
+}
+
+// GetParallel returns the number of members which can be relocated at the same time
+func (n *DeploymentNodeDrainSpec) GetParallel() int {
+	if n == nil || n.Parallel == nil {
+		return 1
+	}
+
+	return *n.Parallel
+}
+
+// GetTaints returns keys of taints which mark a node as drained
+func (n *DeploymentNodeDrainSpec) GetTaints() []string {
+	if n == nil || n.Taints == nil {
+		return []string{core.TaintNodeUnschedulable}
+	}
+
+	return n.Taints
+}
+
+// IsNodeDrained returns true when the node is cordoned or has one of the maintenance taints
+func (n *DeploymentNodeDrainSpec) IsNodeDrained(node *core.Node) (bool, string) {
+	if node == nil {
+		return false, ""
+	}
+
+	if node.Spec.Unschedulable {
+		return true, "Node is cordoned"
+	}
+
+	for _, key := range n.GetTaints() {
+		for _, taint := range node.Spec.Taints {
+			if taint.Key != key {
+				continue
+			}
+
+			if taint.Effect == core.TaintEffectNoSchedule || taint.Effect == core.TaintEffectNoExecute {
+				return true, "Node has maintenance taint " + key
+			}
+		}
+	}
+
+	return false, ""
+}
+
+// Validate the given spec
+func (n *DeploymentNodeDrainSpec) Validate() error {
+	if n == nil {
+		return nil
+	}
+
+	if n.Parallel != nil && *n.Parallel < 1 {
+		return errors.WithStack(errors.Wrapf(ValidationError, "parallel must be greater than 0"))
+	}
+
+	return nil
+}
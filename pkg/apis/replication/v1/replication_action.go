@@ -0,0 +1,71 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+//
+
+package v1
+
+import (
+	"github.com/arangodb/kube-arangodb/pkg/util/errors"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DeploymentReplicationAction is a strongly typed action which can be requested on a deployment replication
+type DeploymentReplicationAction string
+
+const (
+	// DeploymentReplicationActionNone indicates that no action is requested
+	DeploymentReplicationActionNone DeploymentReplicationAction = ""
+	// DeploymentReplicationActionSwitchover swaps source and destination after the destination is completely in sync.
+	// Writes on the source are stopped while the last changes are synchronized.
+	DeploymentReplicationActionSwitchover DeploymentReplicationAction = "Switchover"
+	// DeploymentReplicationActionFailover swaps source and destination without waiting for the source,
+	// e.g. when the source datacenter is unreachable. Changes not yet synchronized are lost.
+	DeploymentReplicationActionFailover DeploymentReplicationAction = "Failover"
+)
+
+// New returns a pointer to a copy of the given action.
+func (a DeploymentReplicationAction) New() *DeploymentReplicationAction {
+	return &a
+}
+
+// Validate the action, returning an error on validation problems or nil if all ok.
+func (a DeploymentReplicationAction) Validate() error {
+	switch a {
+	case DeploymentReplicationActionNone, DeploymentReplicationActionSwitchover, DeploymentReplicationActionFailover:
+		return nil
+	default:
+		return errors.WithStack(errors.Wrapf(ValidationError, "Unknown action '%s'", string(a)))
+	}
+}
+
+// IsForced returns true when the action does not wait for the source.
+func (a DeploymentReplicationAction) IsForced() bool {
+	return a == DeploymentReplicationActionFailover
+}
+
+// DeploymentReplicationActionStatus holds the progress of the action requested in `spec.action`.
+// The current step is reflected in the phase of the deployment replication.
+type DeploymentReplicationActionStatus struct {
+	// Type of the running action
+	Type DeploymentReplicationAction `json:"type"`
+	// StartTime holds the time the action has been started
+	StartTime metav1.Time `json:"startTime"`
+}
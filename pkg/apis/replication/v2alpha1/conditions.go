@@ -33,6 +33,8 @@ type ConditionType string
 const (
 	// ConditionTypeConfigured indicates that the replication has been configured.
 	ConditionTypeConfigured ConditionType = "Configured"
+	// ConditionTypeActionInProgress indicates that the action requested in `spec.action` is running.
+	ConditionTypeActionInProgress ConditionType = "ActionInProgress"
 )
 
 // Condition represents one current condition of a deployment or deployment member.
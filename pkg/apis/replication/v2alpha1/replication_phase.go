@@ -31,6 +31,15 @@ const (
 	// DeploymentReplicationPhaseFailed indicates that a deployment replication is in a failed state
 	// from which automatic recovery is impossible. Inspect `Reason` for more info.
 	DeploymentReplicationPhaseFailed DeploymentReplicationPhase = "Failed"
+	// DeploymentReplicationPhaseWaitingForSync indicates that a switchover waits until all shards
+	// of the destination are in sync.
+	DeploymentReplicationPhaseWaitingForSync DeploymentReplicationPhase = "WaitingForSync"
+	// DeploymentReplicationPhaseStoppingSync indicates that a switchover or failover is canceling the synchronization.
+	DeploymentReplicationPhaseStoppingSync DeploymentReplicationPhase = "StoppingSync"
+	// DeploymentReplicationPhaseReversing indicates that a switchover or failover is swapping source and destination.
+	DeploymentReplicationPhaseReversing DeploymentReplicationPhase = "Reversing"
+	// DeploymentReplicationPhasePaused indicates that synchronization is suspended because `spec.paused` is set.
+	DeploymentReplicationPhasePaused DeploymentReplicationPhase = "Paused"
 )
 
 // IsFailed returns true if given state is DeploymentStateFailed
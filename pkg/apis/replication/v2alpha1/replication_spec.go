@@ -22,13 +22,44 @@
 
 package v2alpha1
 
-import "github.com/arangodb/kube-arangodb/pkg/util/errors"
+import (
+	"github.com/arangodb/kube-arangodb/pkg/util"
+	"github.com/arangodb/kube-arangodb/pkg/util/errors"
+)
 
 // DeploymentReplicationSpec contains the specification part of
 // an ArangoDeploymentReplication.
 type DeploymentReplicationSpec struct {
 	Source      EndpointSpec `json:"source"`
 	Destination EndpointSpec `json:"destination"`
+	// Action requests a switchover or failover of the replication direction.
+	// It is removed by the operator once the action is finished.
+	Action *DeploymentReplicationAction `json:"action,omitempty"`
+	// Paused suspends the synchronization while keeping the data in the destination.
+	// When cleared, synchronization is resumed incrementally.
+	Paused *bool `json:"paused,omitempty"`
+}
+
+// IsPaused returns the value of paused.
+func (s DeploymentReplicationSpec) IsPaused() bool {
+	return util.BoolOrDefault(s.Paused)
+}
+
+// GetAction returns the value of action.
+func (s DeploymentReplicationSpec) GetAction() DeploymentReplicationAction {
+	if s.Action == nil {
+		return DeploymentReplicationActionNone
+	}
+	return *s.Action
+}
+
+// Reversed returns a copy of the spec with source and destination swapped
+// and without a requested action.
+func (s DeploymentReplicationSpec) Reversed() DeploymentReplicationSpec {
+	result := *s.DeepCopy()
+	result.Source, result.Destination = result.Destination, result.Source
+	result.Action = nil
+	return result
 }
 
 // Validate the given spec, returning an error on validation
@@ -40,6 +71,9 @@ func (s DeploymentReplicationSpec) Validate() error {
 	if err := s.Destination.Validate(false); err != nil {
 		return errors.WithStack(err)
 	}
+	if err := s.GetAction().Validate(); err != nil {
+		return errors.WithStack(err)
+	}
 	return nil
 }
 
@@ -0,0 +1,75 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+//
+
+package v2alpha1
+
+import (
+	"testing"
+
+	"github.com/arangodb/kube-arangodb/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeploymentReplicationSpecReversed(t *testing.T) {
+	spec := DeploymentReplicationSpec{
+		Source: EndpointSpec{
+			DeploymentName: util.NewString("dc1"),
+			Authentication: EndpointAuthenticationSpec{KeyfileSecretName: util.NewString("dc1-keyfile")},
+		},
+		Destination: EndpointSpec{
+			DeploymentName: util.NewString("dc2"),
+		},
+		Action: DeploymentReplicationActionSwitchover.New(),
+	}
+	require.NoError(t, spec.Validate())
+
+	reversed := spec.Reversed()
+	assert.Equal(t, "dc2", reversed.Source.GetDeploymentName())
+	assert.Equal(t, "dc1", reversed.Destination.GetDeploymentName())
+	assert.Equal(t, DeploymentReplicationActionNone, reversed.GetAction())
+	assert.Equal(t, DeploymentReplicationActionSwitchover, spec.GetAction())
+
+	// dc2 has no keyfile to authenticate as source
+	assert.True(t, IsValidation(reversed.Validate()))
+
+	spec.Destination.Authentication.KeyfileSecretName = util.NewString("dc2-keyfile")
+	assert.NoError(t, spec.Reversed().Validate())
+}
+
+func TestDeploymentReplicationSpecAction(t *testing.T) {
+	spec := DeploymentReplicationSpec{
+		Source: EndpointSpec{
+			DeploymentName: util.NewString("dc1"),
+			Authentication: EndpointAuthenticationSpec{KeyfileSecretName: util.NewString("dc1-keyfile")},
+		},
+		Destination: EndpointSpec{
+			DeploymentName: util.NewString("dc2"),
+		},
+	}
+	for _, a := range []DeploymentReplicationAction{DeploymentReplicationActionNone, DeploymentReplicationActionSwitchover, DeploymentReplicationActionFailover} {
+		spec.Action = a.New()
+		assert.NoError(t, spec.Validate(), string(a))
+	}
+
+	spec.Action = DeploymentReplicationAction("Unknown").New()
+	assert.True(t, IsValidation(spec.Validate()))
+}
@@ -22,6 +22,10 @@
 
 package v2alpha1
 
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
 // DeploymentReplicationStatus contains the status part of
 // an ArangoDeploymentReplication.
 type DeploymentReplicationStatus struct {
@@ -41,4 +45,10 @@ type DeploymentReplicationStatus struct {
 	// CancelFailures records the number of times that the configuration was canceled
 	// which resulted in an error.
 	CancelFailures int `json:"cancel-failures,omitempty"`
+
+	// Action holds the progress of the action requested in `spec.action` (if any)
+	Action *DeploymentReplicationActionStatus `json:"action,omitempty"`
+
+	// PausedTime holds the time synchronization has been paused (if paused)
+	PausedTime *metav1.Time `json:"pausedTime,omitempty"`
 }
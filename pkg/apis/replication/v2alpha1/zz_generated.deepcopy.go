@@ -173,11 +173,38 @@ func (in *DatabaseStatus) DeepCopy() *DatabaseStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentReplicationActionStatus) DeepCopyInto(out *DeploymentReplicationActionStatus) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentReplicationActionStatus.
+func (in *DeploymentReplicationActionStatus) DeepCopy() *DeploymentReplicationActionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentReplicationActionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DeploymentReplicationSpec) DeepCopyInto(out *DeploymentReplicationSpec) {
 	*out = *in
 	in.Source.DeepCopyInto(&out.Source)
 	in.Destination.DeepCopyInto(&out.Destination)
+	if in.Action != nil {
+		in, out := &in.Action, &out.Action
+		*out = new(DeploymentReplicationAction)
+		**out = **in
+	}
+	if in.Paused != nil {
+		in, out := &in.Paused, &out.Paused
+		*out = new(bool)
+		**out = **in
+	}
 	return
 }
 
@@ -203,6 +230,15 @@ func (in *DeploymentReplicationStatus) DeepCopyInto(out *DeploymentReplicationSt
 	}
 	in.Source.DeepCopyInto(&out.Source)
 	in.Destination.DeepCopyInto(&out.Destination)
+	if in.Action != nil {
+		in, out := &in.Action, &out.Action
+		*out = new(DeploymentReplicationActionStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PausedTime != nil {
+		in, out := &in.PausedTime, &out.PausedTime
+		*out = (*in).DeepCopy()
+	}
 	return
 }
 
@@ -35,6 +35,9 @@ type LocalStorageSpec struct {
 	LocalPath    []string          `json:"localPath,omitempty"`
 	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
 	Privileged   *bool             `json:"privileged,omitempty"`
+	// Quota limits the size of each volume to its requested capacity using
+	// filesystem project quotas (XFS/ext4). Requires privileged mode.
+	Quota *bool `json:"quota,omitempty"`
 }
 
 // Validate the given spec, returning an error on validation
@@ -51,6 +54,9 @@ func (s LocalStorageSpec) Validate() error {
 			return errors.WithStack(errors.Wrapf(ValidationError, "localPath cannot contain empty strings"))
 		}
 	}
+	if s.GetQuota() && !s.GetPrivileged() {
+		return errors.WithStack(errors.Wrapf(ValidationError, "quota requires privileged mode"))
+	}
 	return nil
 }
 
@@ -82,3 +88,11 @@ func (s LocalStorageSpec) GetPrivileged() bool {
 
 	return *s.Privileged
 }
+
+func (s LocalStorageSpec) GetQuota() bool {
+	if s.Quota == nil {
+		return false
+	}
+
+	return *s.Quota
+}
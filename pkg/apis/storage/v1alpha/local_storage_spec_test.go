@@ -25,6 +25,7 @@ package v1alpha
 import (
 	"testing"
 
+	"github.com/arangodb/kube-arangodb/pkg/util"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -42,6 +43,12 @@ func TestLocalStorageSpecCreation(t *testing.T) {
 	class = StorageClassSpec{"spec-name", true}
 	local = LocalStorageSpec{StorageClass: class, LocalPath: []string{}}
 	assert.True(t, IsValidation(local.Validate()))
+
+	local = LocalStorageSpec{StorageClass: class, LocalPath: []string{"/a/path"}, Quota: util.NewBool(true)}
+	assert.True(t, IsValidation(local.Validate()), "should fail as quota requires privileged mode")
+
+	local.Privileged = util.NewBool(true)
+	assert.NoError(t, local.Validate())
 }
 
 // Test reset of local storage spec
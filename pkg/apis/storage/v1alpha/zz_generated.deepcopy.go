@@ -110,6 +110,11 @@ func (in *LocalStorageSpec) DeepCopyInto(out *LocalStorageSpec) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.Quota != nil {
+		in, out := &in.Quota, &out.Quota
+		*out = new(bool)
+		**out = **in
+	}
 	return
 }
 
@@ -26,4 +26,5 @@ var (
 	inspectDeploymentAgencyIndex   = metrics.MustRegisterGaugeVec(metricsComponent, "inspect_deployment_agency_index", "Index of the agency cache", metrics.DeploymentName)
 	inspectDeploymentAgencyFetches = metrics.MustRegisterCounterVec(metricsComponent, "inspect_deployment_agency_fetches", "Number of agency fetches", metrics.DeploymentName)
 	inspectDeploymentAgencyErrors  = metrics.MustRegisterCounterVec(metricsComponent, "inspect_deployment_agency_errors", "Number of agency errors", metrics.DeploymentName)
+	inspectDeploymentAgencyLag     = metrics.MustRegisterGaugeVec(metricsComponent, "inspect_deployment_agency_lag", "Number of agency log entries not applied on the agency cache", metrics.DeploymentName)
 )
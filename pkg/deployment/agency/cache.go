@@ -24,8 +24,10 @@ import (
 	"context"
 	"sync"
 
+	"github.com/arangodb/go-driver"
 	"github.com/arangodb/go-driver/agency"
 	api "github.com/arangodb/kube-arangodb/pkg/apis/deployment/v1"
+	"github.com/arangodb/kube-arangodb/pkg/util/errors"
 )
 
 type Cache interface {
@@ -50,6 +52,27 @@ func NewSingleCache() Cache {
 	return &cacheSingle{}
 }
 
+// NewStaticCache returns the cache which always serves the given state and never connects to the agency.
+func NewStaticCache(state State) Cache {
+	return &cacheStatic{data: state}
+}
+
+type cacheStatic struct {
+	data State
+}
+
+func (c cacheStatic) CommitIndex() uint64 {
+	return 0
+}
+
+func (c cacheStatic) Reload(ctx context.Context, client agency.Agency) (uint64, error) {
+	return 0, nil
+}
+
+func (c cacheStatic) Data() (State, bool) {
+	return c.data, true
+}
+
 type cacheSingle struct {
 }
 
@@ -73,9 +96,22 @@ type cache struct {
 	commitIndex uint64
 
 	data State
+
+	// raw is the tree of the state keys, updated with the agency log entries.
+	raw map[string]interface{}
+
+	// replayedIndex is the highest index which may be already included in the raw tree.
+	// Log entries up to this index are applied again after a full load.
+	replayedIndex uint64
+
+	// pollDisabled is set when the agency does not support the poll API.
+	pollDisabled bool
 }
 
 func (c *cache) CommitIndex() uint64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
 	return c.commitIndex
 }
 
@@ -86,6 +122,9 @@ func (c *cache) Data() (State, bool) {
 	return c.data, c.valid
 }
 
+// Reload updates the cache to the agency commit index. Log entries since the cached index
+// are applied incrementally, when the agency supports the poll API. The state keys are loaded
+// again when the agency log does not contain all entries since the cached index.
 func (c *cache) Reload(ctx context.Context, client agency.Agency) (uint64, error) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
@@ -101,13 +140,88 @@ func (c *cache) Reload(ctx context.Context, client agency.Agency) (uint64, error
 		return cfg.CommitIndex, err
 	}
 
-	if data, err := loadState(ctx, client); err != nil {
+	if c.valid && !c.pollDisabled && cfg.CommitIndex > c.commitIndex {
+		if err := c.poll(ctx, client, cfg.CommitIndex); err == nil {
+			return cfg.CommitIndex, nil
+		} else if driver.IsNotFound(err) {
+			c.pollDisabled = true
+		}
+	}
+
+	return cfg.CommitIndex, c.load(ctx, client, cfg.CommitIndex)
+}
+
+// load reads the state keys from the agency.
+func (c *cache) load(ctx context.Context, client agency.Agency, commitIndex uint64) error {
+	raw, err := loadRawState(ctx, client)
+	if err != nil {
 		c.valid = false
-		return cfg.CommitIndex, err
-	} else {
+		return err
+	}
+
+	data, err := stateFromRaw(raw)
+	if err != nil {
+		c.valid = false
+		return err
+	}
+
+	c.raw = raw
+	c.data = data
+	c.valid = true
+	c.commitIndex = commitIndex
+	c.replayedIndex = commitIndex
+
+	// The state was read after the commit index was fetched, so it may already include newer log entries.
+	if cfg, err := getAgencyConfig(ctx, client); err == nil && cfg.CommitIndex > commitIndex {
+		c.replayedIndex = cfg.CommitIndex
+	}
+
+	return nil
+}
+
+// poll applies the agency log entries up to the given index on the cached state.
+func (c *cache) poll(ctx context.Context, client agency.Agency, commitIndex uint64) error {
+	changed := false
+	index := c.commitIndex
+
+	for requests := 0; index < commitIndex; requests++ {
+		if requests >= maxPollRequests {
+			return errors.Newf("Agency log is not applied after %d requests", requests)
+		}
+
+		result, err := pollAgencyLog(ctx, client, index+1)
+		if err != nil {
+			return err
+		}
+
+		if result.ReadDB != nil || len(result.Log) == 0 || result.Log[0].Index != index+1 {
+			return errPollGap
+		}
+
+		for _, entry := range result.Log {
+			if entry.Index != index+1 {
+				return errPollGap
+			}
+
+			entryChanged, err := applyAgencyLogEntry(c.raw, entry, entry.Index <= c.replayedIndex)
+			if err != nil {
+				return err
+			}
+
+			changed = changed || entryChanged
+			index = entry.Index
+		}
+	}
+
+	if changed {
+		data, err := stateFromRaw(c.raw)
+		if err != nil {
+			return err
+		}
 		c.data = data
-		c.valid = true
-		c.commitIndex = cfg.CommitIndex
-		return cfg.CommitIndex, nil
 	}
+
+	c.commitIndex = index
+
+	return nil
 }
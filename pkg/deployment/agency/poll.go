@@ -0,0 +1,355 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package agency
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/arangodb/go-driver"
+	"github.com/arangodb/go-driver/agency"
+	"github.com/arangodb/kube-arangodb/pkg/util/errors"
+)
+
+const (
+	// pollTimeout is the long-poll timeout (in seconds) of the agency poll request.
+	// Poll is only sent when the agency has new log entries, so it returns immediately.
+	pollTimeout = "1"
+
+	// maxPollRequests limits the number of poll requests in a single reload.
+	maxPollRequests = 16
+)
+
+// errPollGap is returned when the agency log does not contain all entries after the cached index.
+var errPollGap = errors.Newf("Agency log does not contain all entries since the cached index")
+
+type pollResponse struct {
+	Result pollResult `json:"result"`
+}
+
+type pollResult struct {
+	CommitIndex uint64 `json:"commitIndex"`
+	FirstIndex  uint64 `json:"firstIndex"`
+
+	Log []pollLogEntry `json:"log,omitempty"`
+
+	// ReadDB is returned instead of the log when the requested index was already compacted.
+	ReadDB json.RawMessage `json:"readDB,omitempty"`
+}
+
+type pollLogEntry struct {
+	Index uint64                     `json:"index"`
+	Query map[string]json.RawMessage `json:"query"`
+}
+
+// pollAgencyLog returns the agency log entries starting at the given index.
+func pollAgencyLog(ctx context.Context, client agency.Agency, index uint64) (*pollResult, error) {
+	conn := client.Connection()
+
+	req, err := conn.NewRequest(http.MethodGet, "/_api/agency/poll")
+	if err != nil {
+		return nil, err
+	}
+
+	req.SetQuery("index", strconv.FormatUint(index, 10))
+	req.SetQuery("timeout", pollTimeout)
+
+	var data []byte
+
+	resp, err := conn.Do(driver.WithRawResponse(ctx, &data), req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := resp.CheckStatus(http.StatusOK); err != nil {
+		return nil, err
+	}
+
+	var r pollResponse
+
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, err
+	}
+
+	return &r.Result, nil
+}
+
+// agencyOperation is a single write operation on an agency key.
+type agencyOperation struct {
+	Op   string      `json:"op"`
+	New  interface{} `json:"new,omitempty"`
+	Val  interface{} `json:"val,omitempty"`
+	Pos  *int        `json:"pos,omitempty"`
+	Step *float64    `json:"step,omitempty"`
+}
+
+// parseAgencyOperation parses the value of a key in the log query.
+// A value without an operation is a plain set.
+func parseAgencyOperation(data json.RawMessage) (agencyOperation, error) {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return agencyOperation{}, err
+	}
+
+	if m, ok := value.(map[string]interface{}); ok {
+		if _, ok := m["op"]; ok {
+			var op agencyOperation
+			if err := json.Unmarshal(data, &op); err != nil {
+				return agencyOperation{}, err
+			}
+			return op, nil
+		}
+	}
+
+	return agencyOperation{Op: "set", New: value}, nil
+}
+
+// isIdempotent returns true when applying the operation twice gives the same value.
+func (o agencyOperation) isIdempotent() bool {
+	switch o.Op {
+	case "set", "delete", "observe", "unobserve", "delete-ttl":
+		return true
+	default:
+		return false
+	}
+}
+
+// splitAgencyKey splits the key into its parts, ignoring duplicated slashes.
+func splitAgencyKey(key string) []string {
+	var parts []string
+	for _, part := range strings.Split(key, "/") {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+func hasPrefix(path, prefix []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+
+	for id := range prefix {
+		if path[id] != prefix[id] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isStateKey returns true when the key is a state key, an ancestor or a descendant of one.
+func isStateKey(path []string) bool {
+	for _, key := range stateKeys {
+		if hasPrefix(path, key) || hasPrefix(key, path) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isStateKeyAncestor returns true when the key is an ancestor of any state key.
+func isStateKeyAncestor(path []string) bool {
+	for _, key := range stateKeys {
+		if len(path) < len(key) && hasPrefix(key, path) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// pruneRawState removes all keys which are not state keys from the raw tree.
+func pruneRawState(raw map[string]interface{}) map[string]interface{} {
+	pruned := map[string]interface{}{}
+
+	for _, key := range stateKeys {
+		if value, ok := getRawValue(raw, key); ok {
+			setRawValue(pruned, key, value)
+		}
+	}
+
+	return pruned
+}
+
+func getRawValue(raw map[string]interface{}, path []string) (interface{}, bool) {
+	var current interface{} = raw
+	for _, part := range path {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+
+		if current, ok = m[part]; !ok {
+			return nil, false
+		}
+	}
+
+	return current, true
+}
+
+// setRawValue sets the value and creates missing parents. Parents which are not objects are replaced.
+func setRawValue(raw map[string]interface{}, path []string, value interface{}) {
+	current := raw
+	for _, part := range path[:len(path)-1] {
+		next, ok := current[part].(map[string]interface{})
+		if !ok {
+			next = map[string]interface{}{}
+			current[part] = next
+		}
+		current = next
+	}
+
+	current[path[len(path)-1]] = value
+}
+
+func deleteRawValue(raw map[string]interface{}, path []string) {
+	current := raw
+	for _, part := range path[:len(path)-1] {
+		next, ok := current[part].(map[string]interface{})
+		if !ok {
+			return
+		}
+		current = next
+	}
+
+	delete(current, path[len(path)-1])
+}
+
+// applyAgencyOperation applies the operation on the key of the raw tree.
+func applyAgencyOperation(raw map[string]interface{}, path []string, op agencyOperation) error {
+	if len(path) == 0 {
+		return errors.Newf("Operation %s on the agency root is not supported", op.Op)
+	}
+
+	value, exists := getRawValue(raw, path)
+	array, _ := value.([]interface{})
+
+	switch op.Op {
+	case "set":
+		setRawValue(raw, path, op.New)
+	case "delete":
+		deleteRawValue(raw, path)
+	case "increment", "decrement":
+		step := 1.0
+		if op.Step != nil {
+			step = *op.Step
+		}
+		if op.Op == "decrement" {
+			step = -step
+		}
+
+		number, _ := value.(float64)
+		if !exists {
+			number = 0
+		}
+		setRawValue(raw, path, number+step)
+	case "push":
+		setRawValue(raw, path, append(append([]interface{}{}, array...), op.New))
+	case "prepend":
+		setRawValue(raw, path, append([]interface{}{op.New}, array...))
+	case "pop":
+		if len(array) > 0 {
+			setRawValue(raw, path, append([]interface{}{}, array[:len(array)-1]...))
+		}
+	case "shift":
+		if len(array) > 0 {
+			setRawValue(raw, path, append([]interface{}{}, array[1:]...))
+		}
+	case "erase":
+		result := make([]interface{}, 0, len(array))
+		for id, element := range array {
+			if op.Pos != nil {
+				if id == *op.Pos {
+					continue
+				}
+			} else if reflect.DeepEqual(element, op.Val) {
+				continue
+			}
+			result = append(result, element)
+		}
+		setRawValue(raw, path, result)
+	case "replace":
+		result := make([]interface{}, len(array))
+		for id, element := range array {
+			if reflect.DeepEqual(element, op.Val) {
+				element = op.New
+			}
+			result[id] = element
+		}
+		setRawValue(raw, path, result)
+	case "observe", "unobserve", "delete-ttl":
+		// Callbacks and TTLs do not change the value
+	default:
+		return errors.Newf("Unsupported agency operation %s", op.Op)
+	}
+
+	return nil
+}
+
+// applyAgencyLogEntry applies the state key operations of the log entry on the raw tree.
+// Non-idempotent operations are rejected when the entry may be already included in the tree.
+// Returns true when the tree was changed.
+func applyAgencyLogEntry(raw map[string]interface{}, entry pollLogEntry, replayed bool) (bool, error) {
+	changed := false
+	prune := false
+
+	for key, data := range entry.Query {
+		path := splitAgencyKey(key)
+		if !isStateKey(path) {
+			continue
+		}
+
+		op, err := parseAgencyOperation(data)
+		if err != nil {
+			return false, err
+		}
+
+		if replayed && !op.isIdempotent() {
+			return false, errPollGap
+		}
+
+		if err := applyAgencyOperation(raw, path, op); err != nil {
+			return false, err
+		}
+
+		changed = true
+		prune = prune || isStateKeyAncestor(path)
+	}
+
+	if prune {
+		pruned := pruneRawState(raw)
+		for key := range raw {
+			delete(raw, key)
+		}
+		for key, value := range pruned {
+			raw[key] = value
+		}
+	}
+
+	return changed, nil
+}
@@ -0,0 +1,284 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package agency
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/arangodb/go-driver/agency"
+	driverHTTP "github.com/arangodb/go-driver/http"
+	"github.com/stretchr/testify/require"
+)
+
+// testAgency is an agency serving the config, read and poll API from the log.
+type testAgency struct {
+	lock sync.Mutex
+
+	state map[string]interface{}
+	log   []pollLogEntry
+
+	// firstIndex is the first index kept in the log
+	firstIndex uint64
+
+	pollDisabled bool
+
+	reads, polls int
+}
+
+func newTestAgency() *testAgency {
+	return &testAgency{
+		state: map[string]interface{}{
+			"arango": map[string]interface{}{
+				"Plan": map[string]interface{}{
+					"Collections": map[string]interface{}{
+						"_system": map[string]interface{}{},
+					},
+				},
+				"Current": map[string]interface{}{
+					"Collections": map[string]interface{}{
+						"_system": map[string]interface{}{},
+					},
+				},
+			},
+		},
+		firstIndex: 1,
+	}
+}
+
+func (a *testAgency) write(key string, op string) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	entry := pollLogEntry{
+		Index: a.commitIndex() + 1,
+		Query: map[string]json.RawMessage{key: json.RawMessage(op)},
+	}
+	if _, err := applyAgencyLogEntry(a.state, entry, false); err != nil {
+		panic(err)
+	}
+	a.log = append(a.log, entry)
+}
+
+func (a *testAgency) commitIndex() uint64 {
+	return uint64(len(a.log))
+}
+
+func (a *testAgency) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	var response interface{}
+
+	switch r.URL.Path {
+	case "/_api/agency/config":
+		response = map[string]interface{}{"commitIndex": a.commitIndex()}
+	case "/_api/agency/read":
+		a.reads++
+		response = []interface{}{a.state}
+	case "/_api/agency/poll":
+		a.polls++
+		if a.pollDisabled {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":true,"code":404,"errorNum":404,"errorMessage":"not found"}`))
+			return
+		}
+
+		index, _ := strconv.ParseUint(r.URL.Query().Get("index"), 10, 64)
+		result := pollResult{CommitIndex: a.commitIndex()}
+		if index < a.firstIndex {
+			result.ReadDB = json.RawMessage(`[{}]`)
+		} else {
+			result.FirstIndex = index
+			result.Log = a.log[index-1:]
+		}
+		response = map[string]interface{}{"error": false, "code": 200, "result": result}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (a *testAgency) client(t *testing.T) agency.Agency {
+	server := httptest.NewServer(a)
+	t.Cleanup(server.Close)
+
+	conn, err := driverHTTP.NewConnection(driverHTTP.ConnectionConfig{
+		Endpoints: []string{server.URL},
+	})
+	require.NoError(t, err)
+
+	client, err := agency.NewAgency(conn)
+	require.NoError(t, err)
+
+	return client
+}
+
+func Test_Cache_Reload_Incremental(t *testing.T) {
+	a := newTestAgency()
+	client := a.client(t)
+	c := NewAgencyCache()
+
+	a.write("/arango/Target/Version", `{"op":"increment"}`)
+
+	index, err := c.Reload(context.Background(), client)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, index)
+	require.Equal(t, 1, a.reads)
+
+	t.Run("Apply log entries", func(t *testing.T) {
+		a.write("/arango/Plan/Collections/_system/1", `{"name":"test","shards":{"s1":["PRMR-1","PRMR-2"]}}`)
+		a.write("/arango/Current/Collections/_system/1/s1/servers", `{"op":"set","new":["PRMR-1"]}`)
+		a.write("/arango/Current/Collections/_system/1/s1/servers", `{"op":"push","new":"PRMR-2"}`)
+		a.write("/arango/Target/Version", `{"op":"increment"}`)
+
+		index, err := c.Reload(context.Background(), client)
+		require.NoError(t, err)
+		require.EqualValues(t, 5, index)
+		require.EqualValues(t, 5, c.CommitIndex())
+		require.Equal(t, 1, a.reads)
+
+		data, valid := c.Data()
+		require.True(t, valid)
+		require.Equal(t, "test", data.Plan.Collections["_system"]["1"].GetName(""))
+		require.Equal(t, []string{"PRMR-1", "PRMR-2"}, data.Current.Collections["_system"]["1"]["s1"].Servers)
+	})
+
+	t.Run("Delete and maintenance", func(t *testing.T) {
+		a.write("/arango/Plan/Collections/_system/1", `{"op":"delete"}`)
+		a.write("/arango/Supervision/Maintenance", `"2021-01-01T00:00:00Z"`)
+
+		_, err := c.Reload(context.Background(), client)
+		require.NoError(t, err)
+		require.Equal(t, 1, a.reads)
+
+		data, valid := c.Data()
+		require.True(t, valid)
+		require.NotContains(t, data.Plan.Collections["_system"], "1")
+		require.True(t, data.Supervision.Maintenance.Exists())
+	})
+
+	t.Run("Full load on gap", func(t *testing.T) {
+		a.write("/arango/Plan/Collections/_system/2", `{"name":"gap"}`)
+		a.lock.Lock()
+		a.firstIndex = a.commitIndex() + 1
+		a.lock.Unlock()
+
+		_, err := c.Reload(context.Background(), client)
+		require.NoError(t, err)
+		require.Equal(t, 2, a.reads)
+
+		data, valid := c.Data()
+		require.True(t, valid)
+		require.Equal(t, "gap", data.Plan.Collections["_system"]["2"].GetName(""))
+	})
+
+	t.Run("Full load when poll is not supported", func(t *testing.T) {
+		a.lock.Lock()
+		a.pollDisabled = true
+		a.lock.Unlock()
+
+		a.write("/arango/Plan/Collections/_system/3", `{"name":"nopoll"}`)
+		_, err := c.Reload(context.Background(), client)
+		require.NoError(t, err)
+		require.Equal(t, 3, a.reads)
+
+		polls := a.polls
+		a.write("/arango/Plan/Collections/_system/4", `{"name":"nopoll"}`)
+		_, err = c.Reload(context.Background(), client)
+		require.NoError(t, err)
+		require.Equal(t, 4, a.reads)
+		require.Equal(t, polls, a.polls)
+	})
+}
+
+func Test_ApplyAgencyLogEntry(t *testing.T) {
+	apply := func(raw map[string]interface{}, key, op string) bool {
+		changed, err := applyAgencyLogEntry(raw, pollLogEntry{
+			Query: map[string]json.RawMessage{key: json.RawMessage(op)},
+		}, false)
+		require.NoError(t, err)
+		return changed
+	}
+
+	t.Run("Keys outside of the state are ignored", func(t *testing.T) {
+		raw := map[string]interface{}{}
+		require.False(t, apply(raw, "/arango/Target/ToDo/1", `{"type":"test"}`))
+		require.Empty(t, raw)
+	})
+
+	t.Run("Set of a parent is pruned", func(t *testing.T) {
+		raw := map[string]interface{}{}
+		require.True(t, apply(raw, "/arango/Plan", `{"Collections":{"_system":{}},"Databases":{"_system":{}}}`))
+
+		_, ok := getRawValue(raw, []string{"arango", "Plan", "Collections", "_system"})
+		require.True(t, ok)
+		_, ok = getRawValue(raw, []string{"arango", "Plan", "Databases"})
+		require.False(t, ok)
+	})
+
+	t.Run("Array operations", func(t *testing.T) {
+		raw := map[string]interface{}{}
+		key := "/arango/Current/Collections/db/1/s1/servers"
+		path := splitAgencyKey(key)
+
+		apply(raw, key, `{"op":"push","new":"B"}`)
+		apply(raw, key, `{"op":"prepend","new":"A"}`)
+		apply(raw, key, `{"op":"push","new":"C"}`)
+		v, _ := getRawValue(raw, path)
+		require.Equal(t, []interface{}{"A", "B", "C"}, v)
+
+		apply(raw, key, `{"op":"replace","val":"B","new":"D"}`)
+		apply(raw, key, `{"op":"erase","val":"A"}`)
+		v, _ = getRawValue(raw, path)
+		require.Equal(t, []interface{}{"D", "C"}, v)
+
+		apply(raw, key, `{"op":"pop"}`)
+		apply(raw, key, `{"op":"shift"}`)
+		v, _ = getRawValue(raw, path)
+		require.Equal(t, []interface{}{}, v)
+	})
+
+	t.Run("Non-idempotent operations are not replayed", func(t *testing.T) {
+		raw := map[string]interface{}{}
+		_, err := applyAgencyLogEntry(raw, pollLogEntry{
+			Query: map[string]json.RawMessage{"/arango/Current/Collections/db/1/s1/servers": json.RawMessage(`{"op":"push","new":"A"}`)},
+		}, true)
+		require.Equal(t, errPollGap, err)
+	})
+
+	t.Run("Unsupported operation", func(t *testing.T) {
+		raw := map[string]interface{}{}
+		_, err := applyAgencyLogEntry(raw, pollLogEntry{
+			Query: map[string]json.RawMessage{"/arango/Plan/Collections/db": json.RawMessage(`{"op":"unknown"}`)},
+		}, false)
+		require.Error(t, err)
+	})
+}
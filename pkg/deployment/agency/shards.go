@@ -0,0 +1,161 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package agency
+
+import "sort"
+
+// DBServerShards holds the number of shards planned on a single DBServer.
+type DBServerShards struct {
+	// Server is the ID of the DBServer.
+	Server string `json:"server"`
+	// Leaders is the number of shards for which the DBServer is the planned leader.
+	Leaders int `json:"leaders"`
+	// Followers is the number of shards for which the DBServer is a planned follower.
+	Followers int `json:"followers"`
+	// OutOfSync is the number of planned shards which are not (yet) reported in Current for the DBServer.
+	OutOfSync int `json:"outOfSync"`
+}
+
+// GetShardDistribution returns the distribution of planned shards per DBServer, sorted by the server ID.
+// A shard is counted as out of sync when the DBServer is not listed in the Current servers of the shard.
+func (s State) GetShardDistribution() []DBServerShards {
+	servers := map[string]*DBServerShards{}
+
+	for db, collections := range s.Plan.Collections {
+		for collection, plan := range collections {
+			for shard, planServers := range plan.Shards {
+				current := s.Current.Collections[db][collection][shard].Servers
+
+				for i, server := range planServers {
+					d, ok := servers[server]
+					if !ok {
+						d = &DBServerShards{Server: server}
+						servers[server] = d
+					}
+
+					if i == 0 {
+						d.Leaders++
+					} else {
+						d.Followers++
+					}
+
+					if !containsServer(current, server) {
+						d.OutOfSync++
+					}
+				}
+			}
+		}
+	}
+
+	result := make([]DBServerShards, 0, len(servers))
+	for _, d := range servers {
+		result = append(result, *d)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Server < result[j].Server
+	})
+
+	return result
+}
+
+// CollectionShard holds the planned and current servers of a single shard.
+type CollectionShard struct {
+	// Database is the name of the database.
+	Database string `json:"database"`
+	// Collection is the name of the collection.
+	Collection string `json:"collection"`
+	// Shard is the name of the shard.
+	Shard string `json:"shard"`
+	// Leader is the ID of the planned leader.
+	Leader string `json:"leader"`
+	// LeaderInSync is true when the leader is reported in Current.
+	LeaderInSync bool `json:"leaderInSync"`
+	// InSyncFollowers are the planned followers reported in Current.
+	InSyncFollowers []string `json:"inSyncFollowers,omitempty"`
+	// OutOfSyncFollowers are the planned followers not (yet) reported in Current.
+	OutOfSyncFollowers []string `json:"outOfSyncFollowers,omitempty"`
+}
+
+// IsInSync returns true when all planned servers of the shard are reported in Current.
+func (c CollectionShard) IsInSync() bool {
+	return c.LeaderInSync && len(c.OutOfSyncFollowers) == 0
+}
+
+// GetShards returns all planned shards, sorted by the database, collection and shard name.
+// When the database is not empty, only the shards of this database are returned.
+func (s State) GetShards(database string) []CollectionShard {
+	var result []CollectionShard
+
+	for db, collections := range s.Plan.Collections {
+		if database != "" && db != database {
+			continue
+		}
+
+		for collection, plan := range collections {
+			for shard, planServers := range plan.Shards {
+				current := s.Current.Collections[db][collection][shard].Servers
+
+				c := CollectionShard{
+					Database:   db,
+					Collection: plan.GetName(collection),
+					Shard:      shard,
+				}
+
+				for i, server := range planServers {
+					inSync := containsServer(current, server)
+
+					if i == 0 {
+						c.Leader = server
+						c.LeaderInSync = inSync
+					} else if inSync {
+						c.InSyncFollowers = append(c.InSyncFollowers, server)
+					} else {
+						c.OutOfSyncFollowers = append(c.OutOfSyncFollowers, server)
+					}
+				}
+
+				result = append(result, c)
+			}
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Database != result[j].Database {
+			return result[i].Database < result[j].Database
+		}
+		if result[i].Collection != result[j].Collection {
+			return result[i].Collection < result[j].Collection
+		}
+		return result[i].Shard < result[j].Shard
+	})
+
+	return result
+}
+
+func containsServer(servers []string, server string) bool {
+	for _, s := range servers {
+		if s == server {
+			return true
+		}
+	}
+	return false
+}
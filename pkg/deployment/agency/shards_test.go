@@ -0,0 +1,80 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package agency
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_GetShardDistribution(t *testing.T) {
+	data := `[{"arango":{
+"Plan":{"Collections":{"_system":{
+	"1":{"shards":{"s1":["A","B"],"s2":["B","C"]}},
+	"2":{"shards":{"s3":["C","A"]}}
+}}},
+"Current":{"Collections":{"_system":{
+	"1":{"s1":{"servers":["A","B"]},"s2":{"servers":["B"]}}
+}}}
+}}]`
+	var s StateRoots
+
+	require.NoError(t, json.Unmarshal([]byte(data), &s))
+	require.Len(t, s, 1)
+
+	require.Equal(t, []DBServerShards{
+		{Server: "A", Leaders: 1, Followers: 1, OutOfSync: 1},
+		{Server: "B", Leaders: 1, Followers: 1},
+		{Server: "C", Leaders: 1, Followers: 1, OutOfSync: 2},
+	}, s[0].Arango.GetShardDistribution())
+}
+
+func Test_GetShards(t *testing.T) {
+	data := `[{"arango":{
+"Plan":{"Collections":{
+	"_system":{"1":{"name":"users","shards":{"s2":["B","C","A"],"s1":["A","B"]}}},
+	"db":{"2":{"shards":{"s3":["C"]}}}
+}},
+"Current":{"Collections":{
+	"_system":{"1":{"s1":{"servers":["A","B"]},"s2":{"servers":["B","A"]}}}
+}}
+}}]`
+	var s StateRoots
+
+	require.NoError(t, json.Unmarshal([]byte(data), &s))
+	require.Len(t, s, 1)
+
+	shards := s[0].Arango.GetShards("")
+	require.Equal(t, []CollectionShard{
+		{Database: "_system", Collection: "users", Shard: "s1", Leader: "A", LeaderInSync: true, InSyncFollowers: []string{"B"}},
+		{Database: "_system", Collection: "users", Shard: "s2", Leader: "B", LeaderInSync: true, InSyncFollowers: []string{"A"}, OutOfSyncFollowers: []string{"C"}},
+		{Database: "db", Collection: "2", Shard: "s3", Leader: "C"},
+	}, shards)
+
+	require.True(t, shards[0].IsInSync())
+	require.False(t, shards[1].IsInSync())
+	require.False(t, shards[2].IsInSync())
+
+	require.Len(t, s[0].Arango.GetShards("db"), 1)
+	require.Len(t, s[0].Arango.GetShards("missing"), 0)
+}
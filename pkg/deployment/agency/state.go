@@ -30,41 +30,79 @@ import (
 	"github.com/arangodb/kube-arangodb/pkg/util/errors"
 )
 
+// stateKeys are the agency keys loaded into the State.
+var stateKeys = [][]string{
+	{ArangoKey, SupervisionKey, SupervisionMaintenanceKey},
+	{ArangoKey, PlanKey, PlanCollectionsKey},
+	{ArangoKey, CurrentKey, PlanCollectionsKey},
+}
+
 func loadState(ctx context.Context, client agency.Agency) (State, error) {
+	raw, err := loadRawState(ctx, client)
+	if err != nil {
+		return State{}, err
+	}
+
+	return stateFromRaw(raw)
+}
+
+// loadRawState reads the state keys from the agency and returns them as a raw tree.
+func loadRawState(ctx context.Context, client agency.Agency) (map[string]interface{}, error) {
 	conn := client.Connection()
 
 	req, err := client.Connection().NewRequest(http.MethodPost, "/_api/agency/read")
 	if err != nil {
-		return State{}, err
+		return nil, err
 	}
 
 	var data []byte
 
-	req, err = req.SetBody(GetAgencyReadRequest(GetAgencyReadKey(GetAgencyKey(ArangoKey, SupervisionKey, SupervisionMaintenanceKey), GetAgencyKey(ArangoKey, PlanKey, PlanCollectionsKey), GetAgencyKey(ArangoKey, CurrentKey, PlanCollectionsKey))))
+	keys := make([]string, len(stateKeys))
+	for id, key := range stateKeys {
+		keys[id] = GetAgencyKey(key...)
+	}
+
+	req, err = req.SetBody(GetAgencyReadRequest(GetAgencyReadKey(keys...)))
 	if err != nil {
-		return State{}, err
+		return nil, err
 	}
 
 	resp, err := conn.Do(driver.WithRawResponse(ctx, &data), req)
 	if err != nil {
-		return State{}, err
+		return nil, err
 	}
 
 	if err := resp.CheckStatus(http.StatusOK); err != nil {
-		return State{}, err
+		return nil, err
 	}
 
-	var c StateRoots
+	var c []map[string]interface{}
 
 	if err := json.Unmarshal(data, &c); err != nil {
-		return State{}, err
+		return nil, err
 	}
 
 	if len(c) != 1 {
-		return State{}, errors.Newf("Invalid response size")
+		return nil, errors.Newf("Invalid response size")
+	}
+
+	return c[0], nil
+}
+
+// stateFromRaw converts the raw tree of the state keys into the State.
+func stateFromRaw(raw map[string]interface{}) (State, error) {
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return State{}, err
+	}
+
+	var c StateRoot
+
+	if err := json.Unmarshal(data, &c); err != nil {
+		return State{}, err
 	}
 
-	state := c[0].Arango
+	state := c.Arango
 
 	if _, ok := state.Current.Collections["_system"]; !ok {
 		return State{}, errors.Newf("Unable to find system database (invalid data)")
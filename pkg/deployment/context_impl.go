@@ -184,6 +184,9 @@ func (d *Deployment) updateStatus(ctx context.Context, status api.DeploymentStat
 	if err := d.updateCRStatus(ctx, force...); err != nil {
 		return errors.WithStack(err)
 	}
+	// Publish only transitions which have been stored
+	d.publishStatusEvents(d.status.published, d.status.last)
+	d.status.published = *d.status.last.DeepCopy()
 	return nil
 }
 
@@ -501,6 +504,19 @@ func (d *Deployment) GetPvc(ctx context.Context, pvcName string) (*core.Persiste
 	return pvc, nil
 }
 
+// GetPv gets a PV by the given name.
+func (d *Deployment) GetPv(ctx context.Context, pvName string) (*core.PersistentVolume, error) {
+	ctxChild, cancel := globals.GetGlobalTimeouts().Kubernetes().WithTimeout(ctx)
+	defer cancel()
+
+	pv, err := d.getKubeCli().CoreV1().PersistentVolumes().Get(ctxChild, pvName, meta.GetOptions{})
+	if err != nil {
+		log.Debug().Err(err).Str("pv-name", pvName).Msg("Failed to get PV")
+		return nil, errors.WithStack(err)
+	}
+	return pv, nil
+}
+
 // GetTLSKeyfile returns the keyfile encoded TLS certificate+key for
 // the given member.
 func (d *Deployment) GetTLSKeyfile(group api.ServerGroup, member api.MemberStatus) (string, error) {
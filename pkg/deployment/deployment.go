@@ -48,6 +48,7 @@ import (
 
 	"github.com/arangodb/kube-arangodb/pkg/util/arangod/conn"
 
+	"github.com/arangodb/kube-arangodb/pkg/deployment/queue"
 	"github.com/arangodb/kube-arangodb/pkg/deployment/resources/inspector"
 
 	"github.com/arangodb/kube-arangodb/pkg/util/arangod"
@@ -65,6 +66,8 @@ import (
 	"github.com/arangodb/kube-arangodb/pkg/deployment/resilience"
 	"github.com/arangodb/kube-arangodb/pkg/deployment/resources"
 	"github.com/arangodb/kube-arangodb/pkg/generated/clientset/versioned"
+	backupLister "github.com/arangodb/kube-arangodb/pkg/generated/listers/backup/v1"
+	"github.com/arangodb/kube-arangodb/pkg/server"
 	"github.com/arangodb/kube-arangodb/pkg/util"
 	"github.com/arangodb/kube-arangodb/pkg/util/k8sutil"
 	"github.com/arangodb/kube-arangodb/pkg/util/trigger"
@@ -88,6 +91,10 @@ type Dependencies struct {
 	KubeMonitoringCli monitoringClient.MonitoringV1Interface
 	DatabaseCRCli     versioned.Interface
 	EventRecorder     record.EventRecorder
+	Informers         *inspector.Informers
+	Queue             queue.Queue
+	// BackupLister returns the lister of the ArangoBackup informer, or nil if the backup operator is not running.
+	BackupLister func() backupLister.ArangoBackupLister
 }
 
 // deploymentEventType strongly typed type of event
@@ -116,9 +123,10 @@ type Deployment struct {
 
 	apiObject *api.ArangoDeployment // API object
 	status    struct {
-		mutex   sync.Mutex
-		version int32
-		last    api.DeploymentStatus // Internal status copy of the CR
+		mutex     sync.Mutex
+		version   int32
+		last      api.DeploymentStatus // Internal status copy of the CR
+		published api.DeploymentStatus // Last status stored in the CR and published as deployment events
 	}
 	config Config
 	deps   Dependencies
@@ -141,6 +149,12 @@ type Deployment struct {
 	chaosMonkey               *chaos.Monkey
 	syncClientCache           client.ClientCache
 	haveServiceMonitorCRD     bool
+	events                    *server.EventStream
+	metricsTransport          struct {
+		mutex     sync.Mutex
+		secure    bool
+		transport http.RoundTripper
+	}
 }
 
 func (d *Deployment) GetAgencyCache() (agency.State, bool) {
@@ -216,11 +230,13 @@ func New(config Config, deps Dependencies, apiObject *api.ArangoDeployment) (*De
 		eventCh:     make(chan *deploymentEvent, deploymentEventQueueSize),
 		stopCh:      make(chan struct{}),
 		agencyCache: agency.NewCache(apiObject.Spec.Mode),
+		events:      server.NewEventStream(server.DefaultEventStreamHistory),
 	}
 
 	d.clientCache = deploymentClient.NewClientCache(d.getArangoDeployment, conn.NewFactory(d.getAuth, d.getConnConfig))
 
 	d.status.last = *(apiObject.Status.DeepCopy())
+	d.status.published = *(apiObject.Status.DeepCopy())
 	d.reconciler = reconcile.NewReconciler(deps.Log, d)
 	d.resilience = resilience.NewResilience(deps.Log, d)
 	d.resources = resources.NewResources(deps.Log, d)
@@ -321,13 +337,18 @@ func (d *Deployment) run() {
 
 	// Execute inspection for first time without delay of 10s
 	log.Debug().Msg("Initially inspect deployment...")
-	inspectionInterval := d.inspectDeployment(minInspectionInterval)
+	inspectionInterval := d.runInspection(minInspectionInterval)
 	log.Debug().Str("interval", inspectionInterval.String()).Msg("...deployment inspect started")
 
 	for {
 		select {
 		case <-d.stopCh:
-			cachedStatus, err := inspector.NewInspector(context.Background(), d.getKubeCli(), d.getMonitoringV1Cli(), d.getArangoCli(), d.GetNamespace())
+			if d.deps.Queue != nil {
+				d.deps.Queue.Forget(d.queueKey())
+			}
+			reconcile.DeleteMetrics(d.GetName())
+
+			cachedStatus, err := d.newInspector(context.Background())
 			if err != nil {
 				log.Error().Err(err).Msg("Unable to get resources")
 			}
@@ -353,7 +374,7 @@ func (d *Deployment) run() {
 
 		case <-d.inspectTrigger.Done():
 			log.Debug().Msg("Inspect deployment...")
-			inspectionInterval = d.inspectDeployment(inspectionInterval)
+			inspectionInterval = d.runInspection(inspectionInterval)
 			log.Debug().Str("interval", inspectionInterval.String()).Msg("...inspected deployment")
 
 		case <-d.inspectCRDTrigger.Done():
@@ -452,6 +473,7 @@ func (d *Deployment) handleArangoDeploymentUpdatedEvent(ctx context.Context) err
 // On error, the error is logged.
 func (d *Deployment) CreateEvent(evt *k8sutil.Event) {
 	d.deps.EventRecorder.Event(evt.InvolvedObject, evt.Type, evt.Reason, evt.Message)
+	d.publishKubernetesEvent(evt)
 }
 
 // Update the status of the API object from the internal status
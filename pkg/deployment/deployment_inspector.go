@@ -39,6 +39,7 @@ import (
 
 	operatorErrors "github.com/arangodb/kube-arangodb/pkg/util/errors"
 
+	"github.com/arangodb/kube-arangodb/pkg/deployment/queue"
 	"github.com/arangodb/kube-arangodb/pkg/deployment/resources/inspector"
 
 	"github.com/arangodb/kube-arangodb/pkg/apis/deployment"
@@ -52,8 +53,50 @@ import (
 
 var (
 	inspectDeploymentDurationGauges = metrics.MustRegisterGaugeVec(metricsComponent, "inspect_deployment_duration", "Amount of time taken by a single inspection of a deployment (in sec)", metrics.DeploymentName)
+	reconcileLoopDurationHistogram  = metrics.MustRegisterHistogramVec(metricsComponent, "reconciliation_loop_duration_seconds", "Duration of a single reconciliation loop of a deployment (in sec)", nil, metrics.DeploymentName)
 )
 
+// newInspector returns the inspector of the deployment namespace.
+// It is backed by the shared informers, when they are provided by the operator.
+func (d *Deployment) newInspector(ctx context.Context) (inspectorInterface.Inspector, error) {
+	if d.deps.Informers != nil {
+		return d.deps.Informers.NewInspector(ctx, d.GetNamespace())
+	}
+
+	return inspector.NewInspector(ctx, d.getKubeCli(), d.getMonitoringV1Cli(), d.getArangoCli(), d.GetNamespace())
+}
+
+// queueKey returns the key of the deployment in the reconciliation queue.
+// Deployments with the same name can exist in different namespaces.
+func (d *Deployment) queueKey() string {
+	return d.GetNamespace() + "/" + d.GetName()
+}
+
+// runInspection inspects the deployment within the reconciliation queue shared by all deployments,
+// when it is provided by the operator. Deployments with a high priority plan are inspected first
+// and a deployment with failing inspections is delayed, so it does not starve the others.
+func (d *Deployment) runInspection(lastInterval util.Interval) util.Interval {
+	if d.deps.Queue == nil {
+		return d.inspectDeployment(lastInterval)
+	}
+
+	priority := queue.PriorityNormal
+	if status, _ := d.GetStatus(); !status.HighPriorityPlan.IsEmpty() {
+		priority = queue.PriorityHigh
+	}
+
+	nextInterval := lastInterval
+	d.deps.Queue.Run(d.stopCh, d.queueKey(), priority, func() error {
+		nextInterval = d.inspectDeployment(lastInterval)
+		if d.recentInspectionErrors > 0 {
+			return errors.Newf("Inspection of deployment %s failed", d.GetName())
+		}
+		return nil
+	})
+
+	return nextInterval
+}
+
 // inspectDeployment inspects the entire deployment, creates
 // a plan to update if needed and inspects underlying resources.
 // This function should be called when:
@@ -77,7 +120,7 @@ func (d *Deployment) inspectDeployment(lastInterval util.Interval) util.Interval
 	deploymentName := d.GetName()
 	defer metrics.SetDuration(inspectDeploymentDurationGauges.WithLabelValues(deploymentName), start)
 
-	cachedStatus, err := inspector.NewInspector(context.Background(), d.getKubeCli(), d.getMonitoringV1Cli(), d.getArangoCli(), d.GetNamespace())
+	cachedStatus, err := d.newInspector(context.Background())
 	if err != nil {
 		log.Error().Err(err).Msg("Unable to get resources")
 		return minInspectionInterval // Retry ASAP
@@ -152,6 +195,7 @@ func (d *Deployment) inspectDeploymentWithError(ctx context.Context, lastInterva
 	defer func() {
 		d.deps.Log.Info().Msgf("Reconciliation loop took %s", time.Since(t))
 	}()
+	defer metrics.ObserveDuration(reconcileLoopDurationHistogram.WithLabelValues(d.GetName()), t)
 
 	// Ensure that spec and status checksum are same
 	spec := d.GetSpec()
@@ -233,6 +277,10 @@ func (d *Deployment) inspectDeploymentWithError(ctx context.Context, lastInterva
 		return minInspectionInterval, errors.Wrapf(err, "Member failure detection failed")
 	}
 
+	if err := d.resilience.CheckNodeDrain(ctx, cachedStatus); err != nil {
+		return minInspectionInterval, errors.Wrapf(err, "Node drain detection failed")
+	}
+
 	// Immediate actions
 	if err := d.reconciler.CheckDeployment(ctx); err != nil {
 		return minInspectionInterval, errors.Wrapf(err, "Reconciler immediate actions failed")
@@ -244,12 +292,23 @@ func (d *Deployment) inspectDeploymentWithError(ctx context.Context, lastInterva
 		nextInterval = interval
 	}
 
-	inspectDeploymentAgencyFetches.WithLabelValues(d.GetName()).Inc()
-	if offset, err := d.RefreshAgencyCache(ctx); err != nil {
-		inspectDeploymentAgencyErrors.WithLabelValues(d.GetName()).Inc()
-		d.deps.Log.Err(err).Msgf("Unable to refresh agency")
+	if spec.IsSuspended() && status.Phase.IsSuspended() {
+		// Agency is shut down
+		d.deps.Log.Debug().Msgf("Deployment is suspended, skipping agency refresh")
 	} else {
-		inspectDeploymentAgencyIndex.WithLabelValues(d.GetName()).Set(float64(offset))
+		inspectDeploymentAgencyFetches.WithLabelValues(d.GetName()).Inc()
+		offset, err := d.RefreshAgencyCache(ctx)
+		if err != nil {
+			inspectDeploymentAgencyErrors.WithLabelValues(d.GetName()).Inc()
+			d.deps.Log.Err(err).Msgf("Unable to refresh agency")
+		} else {
+			inspectDeploymentAgencyIndex.WithLabelValues(d.GetName()).Set(float64(offset))
+		}
+		if cached := d.agencyCache.CommitIndex(); offset > cached {
+			inspectDeploymentAgencyLag.WithLabelValues(d.GetName()).Set(float64(offset - cached))
+		} else {
+			inspectDeploymentAgencyLag.WithLabelValues(d.GetName()).Set(0)
+		}
 	}
 
 	// Refresh maintenance lock
@@ -311,6 +370,10 @@ func (d *Deployment) inspectDeploymentWithError(ctx context.Context, lastInterva
 		nextInterval = minInspectionInterval
 	}
 
+	if err := d.updateSuspendedPhase(ctx); err != nil {
+		return minInspectionInterval, errors.Wrapf(err, "Suspended phase update failed")
+	}
+
 	// Create access packages
 	if err := d.createAccessPackages(ctx); err != nil {
 		return minInspectionInterval, errors.Wrapf(err, "AccessPackage creation failed")
@@ -356,6 +419,11 @@ func (d *Deployment) refreshMaintenanceTTL(ctx context.Context) {
 		return
 	}
 
+	if d.status.last.Phase.IsSuspended() {
+		// Agency is not running, maintenance is enabled again when deployment is resumed
+		return
+	}
+
 	if !features.Maintenance().Enabled() {
 		// Maintenance feature is not enabled
 		return
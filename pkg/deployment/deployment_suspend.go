@@ -0,0 +1,117 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package deployment
+
+import (
+	"context"
+
+	driver "github.com/arangodb/go-driver"
+
+	api "github.com/arangodb/kube-arangodb/pkg/apis/deployment/v1"
+	"github.com/arangodb/kube-arangodb/pkg/util/errors"
+)
+
+// updateSuspendedPhase switches the phase of the deployment to Suspended when all members are shut down
+// and back to Running when all members are started again.
+func (d *Deployment) updateSuspendedPhase(ctx context.Context) error {
+	spec := d.GetSpec()
+	log := d.deps.Log
+
+	if err := d.WithStatusUpdate(ctx, func(s *api.DeploymentStatus) bool {
+		if !s.IsPlanEmpty() {
+			// Wait until members are shut down or started
+			return false
+		}
+
+		shutdown, running := 0, 0
+		s.Members.ForeachServerGroup(func(group api.ServerGroup, list api.MemberStatusList) error {
+			for _, m := range list {
+				if isMemberShutDown(m) {
+					shutdown++
+				} else {
+					running++
+				}
+			}
+			return nil
+		})
+
+		switch {
+		case spec.IsSuspended() && s.Phase == api.DeploymentPhaseRunning && running == 0:
+			log.Info().Int("members", shutdown).Msg("All members are shut down, deployment is suspended")
+			s.Phase = api.DeploymentPhaseSuspended
+			return true
+		case !spec.IsSuspended() && s.Phase.IsSuspended() && shutdown == 0:
+			if !d.isResumedDeploymentHealthy(spec, s) {
+				log.Debug().Msg("Waiting for resumed deployment to become healthy")
+				return false
+			}
+			log.Info().Int("members", running).Msg("All members are started, deployment is running")
+			s.Phase = api.DeploymentPhaseRunning
+			return true
+		}
+
+		return false
+	}); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+// isMemberShutDown returns true when the pod of the member is terminated because the deployment is suspended.
+// Members which were pending when the deployment was suspended have no pod at all.
+func isMemberShutDown(m api.MemberStatus) bool {
+	if m.Phase != api.MemberPhaseShuttingDown || !m.Conditions.IsTrue(api.ConditionTypeSuspended) {
+		return false
+	}
+
+	return m.PodName == "" || m.Conditions.IsTrue(api.ConditionTypeTerminated)
+}
+
+// isResumedDeploymentHealthy returns true when all members are ready and, in cluster mode,
+// the cluster health reports all of them as good.
+func (d *Deployment) isResumedDeploymentHealthy(spec api.DeploymentSpec, s *api.DeploymentStatus) bool {
+	if !s.Members.AllMembersReady(spec.GetMode(), spec.Sync.IsEnabled()) {
+		return false
+	}
+
+	if spec.GetMode() != api.DeploymentModeCluster {
+		return true
+	}
+
+	health, err := d.GetDeploymentHealth()
+	if err != nil {
+		d.deps.Log.Debug().Err(err).Msg("Cluster health is not available")
+		return false
+	}
+
+	healthy := true
+	s.Members.ForeachServerInGroups(func(group api.ServerGroup, list api.MemberStatusList) error {
+		for _, m := range list {
+			if h, ok := health.Health[driver.ServerID(m.ID)]; !ok || h.Status != driver.ServerStatusGood {
+				healthy = false
+			}
+		}
+		return nil
+	}, api.ServerGroupAgents, api.ServerGroupDBServers, api.ServerGroupCoordinators)
+
+	return healthy
+}
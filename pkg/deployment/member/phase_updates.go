@@ -41,13 +41,11 @@ func GetPhaseExecutor() PhaseExecutor {
 
 var phase = phaseMap{
 	api.MemberPhaseNone: {
-		api.MemberPhasePending: func(action api.Action, m *api.MemberStatus) {
-			// Change member RID
-			m.RID = uuid.NewUUID()
-
-			// Clean Pod details
-			m.PodUID = ""
-		},
+		api.MemberPhasePending: newMemberRIDMapFunc,
+	},
+	api.MemberPhaseShuttingDown: {
+		// Member is started again after the deployment was suspended
+		api.MemberPhasePending: newMemberRIDMapFunc,
 	},
 	api.MemberPhasePending: {
 		api.MemberPhaseCreated: func(action api.Action, m *api.MemberStatus) {
@@ -60,6 +58,14 @@ var phase = phaseMap{
 	},
 }
 
+func newMemberRIDMapFunc(action api.Action, m *api.MemberStatus) {
+	// Change member RID
+	m.RID = uuid.NewUUID()
+
+	// Clean Pod details
+	m.PodUID = ""
+}
+
 func removeMemberConditionsMapFunc(m *api.MemberStatus) {
 	// Clean conditions
 	m.Conditions.Remove(api.ConditionTypeReady)
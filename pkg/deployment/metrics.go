@@ -22,7 +22,9 @@ package deployment
 
 import (
 	"sync"
+	"time"
 
+	driver "github.com/arangodb/go-driver"
 	api "github.com/arangodb/kube-arangodb/pkg/apis/deployment/v1"
 	"github.com/arangodb/kube-arangodb/pkg/util/metrics"
 	"github.com/prometheus/client_golang/prometheus"
@@ -41,6 +43,15 @@ func init() {
 		deploymentAgencyStateMetric:    metrics.NewDescription("arango_operator_deployment_agency_state", "Reachability of agency", []string{"namespace", "deployment"}, nil),
 		deploymentShardLeadersMetric:   metrics.NewDescription("arango_operator_deployment_shard_leaders", "Deployment leader shards distribution", []string{"namespace", "deployment", "database", "collection", "shard", "server"}, nil),
 		deploymentShardsMetric:         metrics.NewDescription("arango_operator_deployment_shards", "Deployment shards distribution", []string{"namespace", "deployment", "database", "collection", "shard", "server"}, nil),
+
+		deploymentMemberPhaseMetric:     metrics.NewDescription("arango_operator_deployment_member_phase", "Phase of the member", []string{"namespace", "deployment", "role", "id", "phase"}, nil),
+		deploymentMemberReadyMetric:     metrics.NewDescription("arango_operator_deployment_member_ready", "Readiness of the member", []string{"namespace", "deployment", "role", "id"}, nil),
+		deploymentMemberConditionMetric: metrics.NewDescription("arango_operator_deployment_member_condition", "Conditions of the member", []string{"namespace", "deployment", "role", "id", "condition"}, nil),
+		deploymentMemberRestartMetric:   metrics.NewDescription("arango_operator_deployment_member_last_restart_seconds", "Seconds since the last restart of the member (or since its creation if it was never restarted)", []string{"namespace", "deployment", "role", "id"}, nil),
+		deploymentMemberRestartsMetric:  metrics.NewDescription("arango_operator_deployment_member_recent_terminations", "Number of recent terminations of the member", []string{"namespace", "deployment", "role", "id"}, nil),
+		deploymentMemberImageMetric:     metrics.NewDescription("arango_operator_deployment_member_image", "Image of the member (1 if the member runs the target image)", []string{"namespace", "deployment", "role", "id", "image", "target_image"}, nil),
+		deploymentMemberHealthMetric:    metrics.NewDescription("arango_operator_deployment_member_health", "Health status of the member reported by the cluster health", []string{"namespace", "deployment", "role", "id", "status"}, nil),
+		deploymentAgencyLeaderMetric:    metrics.NewDescription("arango_operator_deployment_agency_leader", "Leadership of the agent (1 if leading)", []string{"namespace", "deployment", "id"}, nil),
 	}
 
 	prometheus.MustRegister(&localInventory)
@@ -55,13 +66,16 @@ type inventory struct {
 	deployments map[string]map[string]*Deployment
 
 	deploymentsMetric, deploymentMetricsMembersMetric, deploymentAgencyStateMetric, deploymentShardsMetric, deploymentShardLeadersMetric metrics.Description
+
+	deploymentMemberPhaseMetric, deploymentMemberReadyMetric, deploymentMemberConditionMetric, deploymentMemberRestartMetric, deploymentMemberRestartsMetric, deploymentMemberImageMetric, deploymentMemberHealthMetric, deploymentAgencyLeaderMetric metrics.Description
 }
 
 func (i *inventory) Describe(descs chan<- *prometheus.Desc) {
 	i.lock.Lock()
 	defer i.lock.Unlock()
 
-	metrics.NewPushDescription(descs).Push(i.deploymentsMetric, i.deploymentMetricsMembersMetric, i.deploymentAgencyStateMetric, i.deploymentShardLeadersMetric, i.deploymentShardsMetric)
+	metrics.NewPushDescription(descs).Push(i.deploymentsMetric, i.deploymentMetricsMembersMetric, i.deploymentAgencyStateMetric, i.deploymentShardLeadersMetric, i.deploymentShardsMetric,
+		i.deploymentMemberPhaseMetric, i.deploymentMemberReadyMetric, i.deploymentMemberConditionMetric, i.deploymentMemberRestartMetric, i.deploymentMemberRestartsMetric, i.deploymentMemberImageMetric, i.deploymentMemberHealthMetric, i.deploymentAgencyLeaderMetric)
 }
 
 func (i *inventory) Collect(m chan<- prometheus.Metric) {
@@ -80,6 +94,8 @@ func (i *inventory) Collect(m chan<- prometheus.Metric) {
 				p.Push(i.deploymentMetricsMembersMetric.Gauge(1, deployment.GetNamespace(), deployment.GetName(), member.Group.AsRole(), member.Member.ID))
 			}
 
+			i.collectMembers(p, deployment, spec, status)
+
 			if spec.Mode.Get().HasAgents() {
 				agency, agencyOk := deployment.GetAgencyCache()
 				if !agencyOk {
@@ -124,6 +140,61 @@ func (i *inventory) Collect(m chan<- prometheus.Metric) {
 	}
 }
 
+// collectMembers pushes the state of all members of the deployment, as seen by the operator
+func (i *inventory) collectMembers(p metrics.PushMetric, deployment *Deployment, spec api.DeploymentSpec, status api.DeploymentStatus) {
+	now := time.Now()
+	targetImage := spec.GetImage()
+
+	health, healthErr := deployment.GetDeploymentHealth()
+
+	for _, member := range status.Members.AsList() {
+		m := member.Member
+		labels := []string{deployment.GetNamespace(), deployment.GetName(), member.Group.AsRole(), m.ID}
+
+		p.Push(i.deploymentMemberPhaseMetric.Gauge(1, append(labels, string(m.Phase))...))
+		p.Push(i.deploymentMemberReadyMetric.Gauge(boolToFloat(m.Conditions.IsTrue(api.ConditionTypeReady)), labels...))
+
+		for _, c := range m.Conditions {
+			p.Push(i.deploymentMemberConditionMetric.Gauge(boolToFloat(c.IsTrue()), append(labels, c.Type.String())...))
+		}
+
+		lastRestart := m.CreatedAt.Time
+		for _, t := range m.RecentTerminations {
+			if t.Time.After(lastRestart) {
+				lastRestart = t.Time
+			}
+		}
+		if !lastRestart.IsZero() {
+			p.Push(i.deploymentMemberRestartMetric.Gauge(now.Sub(lastRestart).Seconds(), labels...))
+		}
+		p.Push(i.deploymentMemberRestartsMetric.Gauge(float64(len(m.RecentTerminations)), labels...))
+
+		if m.Image != nil {
+			p.Push(i.deploymentMemberImageMetric.Gauge(boolToFloat(m.Image.Image == targetImage), append(labels, m.Image.Image, targetImage)...))
+		}
+
+		if healthErr != nil {
+			continue
+		}
+
+		if h, ok := health.Health[driver.ServerID(m.ID)]; ok {
+			if h.Status != "" {
+				p.Push(i.deploymentMemberHealthMetric.Gauge(1, append(labels, string(h.Status))...))
+			}
+			if member.Group == api.ServerGroupAgents && h.Leading != nil {
+				p.Push(i.deploymentAgencyLeaderMetric.Gauge(boolToFloat(*h.Leading), deployment.GetNamespace(), deployment.GetName(), m.ID))
+			}
+		}
+	}
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
 func (i *inventory) Add(d *Deployment) {
 	i.lock.Lock()
 	defer i.lock.Unlock()
@@ -0,0 +1,95 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package deployment
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/arangodb/kube-arangodb/pkg/apis/deployment/v1"
+	"github.com/arangodb/kube-arangodb/pkg/util"
+	"github.com/arangodb/kube-arangodb/pkg/util/metrics"
+)
+
+func TestInventory_CollectMembers(t *testing.T) {
+	d, _ := createTestDeployment(t, Config{}, &api.ArangoDeployment{
+		Spec: api.DeploymentSpec{
+			Image: util.NewString(testImage),
+		},
+	})
+
+	member := api.MemberStatus{
+		ID:        "PRMR-1",
+		Phase:     api.MemberPhaseCreated,
+		CreatedAt: metav1.NewTime(time.Now().Add(-time.Hour)),
+		RecentTerminations: []metav1.Time{
+			metav1.NewTime(time.Now().Add(-time.Minute)),
+		},
+		Image: &api.ImageInfo{
+			Image: "arangodb/arangodb:old",
+		},
+	}
+	member.Conditions.Update(api.ConditionTypeReady, true, "", "")
+	member.Conditions.Update(api.ConditionTypePendingRestart, true, "", "")
+
+	d.status.last = api.DeploymentStatus{
+		Members: api.DeploymentStatusMembers{
+			DBServers: api.MemberStatusList{member},
+		},
+	}
+
+	out := make(chan prometheus.Metric, 128)
+	localInventory.collectMembers(metrics.NewPushMetric(out), d, d.GetSpec(), d.status.last)
+	close(out)
+
+	values := map[string][]*dto.Metric{}
+	for m := range out {
+		var metric dto.Metric
+		require.NoError(t, m.Write(&metric))
+		values[m.Desc().String()] = append(values[m.Desc().String()], &metric)
+	}
+
+	get := func(d metrics.Description) []*dto.Metric {
+		return values[d.Desc().String()]
+	}
+
+	require.Len(t, get(localInventory.deploymentMemberPhaseMetric), 1)
+	require.Len(t, get(localInventory.deploymentMemberReadyMetric), 1)
+	assert.EqualValues(t, 1, get(localInventory.deploymentMemberReadyMetric)[0].GetGauge().GetValue())
+
+	require.Len(t, get(localInventory.deploymentMemberConditionMetric), 2)
+
+	require.Len(t, get(localInventory.deploymentMemberRestartMetric), 1)
+	restart := get(localInventory.deploymentMemberRestartMetric)[0].GetGauge().GetValue()
+	assert.True(t, restart >= 60 && restart < 3600)
+
+	require.Len(t, get(localInventory.deploymentMemberImageMetric), 1)
+	assert.EqualValues(t, 0, get(localInventory.deploymentMemberImageMetric)[0].GetGauge().GetValue())
+
+	// Health is not fetched yet
+	assert.Len(t, get(localInventory.deploymentMemberHealthMetric), 0)
+}
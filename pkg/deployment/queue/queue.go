@@ -0,0 +1,282 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package queue
+
+import (
+	"sync"
+	"time"
+
+	"github.com/arangodb/kube-arangodb/pkg/metrics"
+	"k8s.io/client-go/util/workqueue"
+)
+
+const (
+	// DefaultWorkers is the default number of deployments reconciled at once.
+	DefaultWorkers = 8
+
+	// DefaultBaseBackoff is the backoff of a deployment after its first failed reconciliation.
+	DefaultBaseBackoff = time.Second
+	// DefaultMaxBackoff is the maximum backoff of a deployment with failing reconciliations.
+	DefaultMaxBackoff = 2 * time.Minute
+
+	metricsComponent = "deployment_queue"
+)
+
+var (
+	queueDepthGauges     = metrics.MustRegisterGaugeVec(metricsComponent, "depth", "Number of deployments waiting for a reconciliation worker", "priority")
+	queueLatency         = metrics.MustRegisterHistogramVec(metricsComponent, "latency_seconds", "Time a deployment waits for a reconciliation worker (in sec)", nil, "priority")
+	queueWorkersBusy     = metrics.MustRegisterGauge(metricsComponent, "busy_workers", "Number of reconciliation workers currently reconciling a deployment")
+	queueBackoffCounters = metrics.MustRegisterCounterVec(metricsComponent, "backoffs", "Number of times a deployment (namespace/name) was delayed because of a failed reconciliation", metrics.DeploymentName)
+)
+
+// Priority of the deployment reconciliation.
+type Priority int
+
+const (
+	// PriorityNormal is used by deployments without pending high priority actions.
+	PriorityNormal Priority = iota
+	// PriorityHigh is used by deployments with a high priority plan.
+	PriorityHigh
+)
+
+var priorities = []Priority{PriorityHigh, PriorityNormal}
+
+// String returns the priority name used in metrics.
+func (p Priority) String() string {
+	if p == PriorityHigh {
+		return "high"
+	}
+	return "normal"
+}
+
+// Func is a single reconciliation of a deployment.
+// Returned error puts the deployment in backoff.
+type Func func() error
+
+// Queue limits the number of deployments reconciled at once.
+// Deployments are identified by keys in the namespace/name format.
+type Queue interface {
+	// Run waits until the backoff of the key elapsed and a worker is free, then executes the function.
+	// Functions with high priority are executed before the normal ones.
+	// Returns false when the stop channel was closed or the queue was stopped before the function was executed.
+	Run(stop <-chan struct{}, key string, priority Priority, f Func) bool
+	// Forget removes the backoff and the metrics of the key. It is called when the deployment is removed.
+	Forget(key string)
+}
+
+// NewQueue creates a queue with the given number of workers, which run until the stop channel is closed.
+func NewQueue(workers int, stop <-chan struct{}) Queue {
+	return newQueue(workers, workqueue.NewItemExponentialFailureRateLimiter(DefaultBaseBackoff, DefaultMaxBackoff), stop)
+}
+
+func newQueue(workers int, limiter workqueue.RateLimiter, stop <-chan struct{}) *queue {
+	q := &queue{
+		limiter: limiter,
+		pending: map[Priority][]*item{},
+		backoff: map[string]time.Time{},
+		stop:    stop,
+	}
+	q.cond = sync.NewCond(&q.lock)
+
+	for i := 0; i < workers; i++ {
+		go q.worker()
+	}
+
+	go func() {
+		<-stop
+
+		q.lock.Lock()
+		defer q.lock.Unlock()
+
+		q.stopped = true
+		q.cond.Broadcast()
+	}()
+
+	return q
+}
+
+type item struct {
+	key      string
+	priority Priority
+	f        Func
+	added    time.Time
+	done     chan struct{}
+}
+
+type queue struct {
+	lock sync.Mutex
+	cond *sync.Cond
+
+	limiter workqueue.RateLimiter
+
+	pending map[Priority][]*item
+	backoff map[string]time.Time
+	stop    <-chan struct{}
+	stopped bool
+}
+
+func (q *queue) Run(stop <-chan struct{}, key string, priority Priority, f Func) bool {
+	if delay := q.backoffOf(key); delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-stop:
+			return false
+		case <-q.stop:
+			return false
+		}
+	}
+
+	i := &item{
+		key:      key,
+		priority: priority,
+		f:        f,
+		added:    time.Now(),
+		done:     make(chan struct{}),
+	}
+
+	if !q.push(i) {
+		return false
+	}
+
+	select {
+	case <-i.done:
+		return true
+	case <-stop:
+	case <-q.stop:
+	}
+
+	if q.remove(i) {
+		return false
+	}
+	// Function is already running
+	<-i.done
+	return true
+}
+
+func (q *queue) Forget(key string) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	q.limiter.Forget(key)
+	delete(q.backoff, key)
+	queueBackoffCounters.DeleteLabelValues(key)
+}
+
+func (q *queue) backoffOf(key string) time.Duration {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if t, ok := q.backoff[key]; ok {
+		return time.Until(t)
+	}
+
+	return 0
+}
+
+// push adds the item to the queue. Returns false when the queue is stopped.
+func (q *queue) push(i *item) bool {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if q.stopped {
+		return false
+	}
+
+	q.pending[i.priority] = append(q.pending[i.priority], i)
+	queueDepthGauges.WithLabelValues(i.priority.String()).Inc()
+	q.cond.Signal()
+	return true
+}
+
+// remove removes the item from the queue. Returns false when it was already taken by a worker.
+func (q *queue) remove(i *item) bool {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	items := q.pending[i.priority]
+	for id, pending := range items {
+		if pending == i {
+			q.pending[i.priority] = append(items[:id:id], items[id+1:]...)
+			queueDepthGauges.WithLabelValues(i.priority.String()).Dec()
+			return true
+		}
+	}
+
+	return false
+}
+
+// pop waits for the next item with the highest priority. Returns nil when the queue is stopped.
+func (q *queue) pop() *item {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	for {
+		if q.stopped {
+			return nil
+		}
+
+		for _, p := range priorities {
+			if items := q.pending[p]; len(items) > 0 {
+				q.pending[p] = items[1:]
+				queueDepthGauges.WithLabelValues(p.String()).Dec()
+				return items[0]
+			}
+		}
+
+		q.cond.Wait()
+	}
+}
+
+func (q *queue) worker() {
+	for {
+		i := q.pop()
+		if i == nil {
+			return
+		}
+
+		q.process(i)
+	}
+}
+
+func (q *queue) process(i *item) {
+	defer close(i.done)
+
+	queueLatency.WithLabelValues(i.priority.String()).Observe(time.Since(i.added).Seconds())
+
+	queueWorkersBusy.Inc()
+	err := i.f()
+	queueWorkersBusy.Dec()
+
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if err != nil {
+		q.backoff[i.key] = time.Now().Add(q.limiter.When(i.key))
+		queueBackoffCounters.WithLabelValues(i.key).Inc()
+		return
+	}
+
+	q.limiter.Forget(i.key)
+	delete(q.backoff, i.key)
+}
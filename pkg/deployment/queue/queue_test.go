@@ -0,0 +1,228 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package queue
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/arangodb/kube-arangodb/pkg/util/errors"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/util/workqueue"
+)
+
+func newTestQueue(t *testing.T, workers int, backoff time.Duration) *queue {
+	stop := make(chan struct{})
+	t.Cleanup(func() {
+		close(stop)
+	})
+
+	return newQueue(workers, workqueue.NewItemExponentialFailureRateLimiter(backoff, backoff), stop)
+}
+
+// waitForPending waits until the given number of items is waiting for a worker.
+func waitForPending(t *testing.T, q *queue, count int) {
+	require.Eventually(t, func() bool {
+		q.lock.Lock()
+		defer q.lock.Unlock()
+
+		return len(q.pending[PriorityHigh])+len(q.pending[PriorityNormal]) == count
+	}, time.Second, time.Millisecond)
+}
+
+func Test_Queue_Workers(t *testing.T) {
+	q := newTestQueue(t, 2, time.Millisecond)
+
+	var running, max int32
+	var wg sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			require.True(t, q.Run(nil, "deployment", PriorityNormal, func() error {
+				current := atomic.AddInt32(&running, 1)
+				defer atomic.AddInt32(&running, -1)
+
+				for {
+					m := atomic.LoadInt32(&max)
+					if current <= m || atomic.CompareAndSwapInt32(&max, m, current) {
+						break
+					}
+				}
+
+				time.Sleep(5 * time.Millisecond)
+				return nil
+			}))
+		}()
+	}
+
+	wg.Wait()
+	require.EqualValues(t, 2, max)
+}
+
+func Test_Queue_Priority(t *testing.T) {
+	q := newTestQueue(t, 1, time.Millisecond)
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	go q.Run(nil, "blocking", PriorityNormal, func() error {
+		close(started)
+		<-release
+		return nil
+	})
+	<-started
+
+	var lock sync.Mutex
+	var order []string
+	var wg sync.WaitGroup
+
+	run := func(key string, priority Priority) {
+		defer wg.Done()
+		q.Run(nil, key, priority, func() error {
+			lock.Lock()
+			defer lock.Unlock()
+
+			order = append(order, key)
+			return nil
+		})
+	}
+
+	wg.Add(3)
+	go run("normal-1", PriorityNormal)
+	waitForPending(t, q, 1)
+	go run("normal-2", PriorityNormal)
+	waitForPending(t, q, 2)
+	go run("high", PriorityHigh)
+	waitForPending(t, q, 3)
+
+	close(release)
+	wg.Wait()
+
+	require.Equal(t, []string{"high", "normal-1", "normal-2"}, order)
+}
+
+func Test_Queue_Backoff(t *testing.T) {
+	q := newTestQueue(t, 1, 50*time.Millisecond)
+
+	require.True(t, q.Run(nil, "test/failing", PriorityNormal, func() error {
+		return errors.Newf("failed")
+	}))
+
+	t.Run("Same name in other namespace is not delayed", func(t *testing.T) {
+		start := time.Now()
+		require.True(t, q.Run(nil, "other/failing", PriorityNormal, func() error {
+			return nil
+		}))
+		require.True(t, time.Since(start) < 50*time.Millisecond)
+	})
+
+	t.Run("Failed key is delayed", func(t *testing.T) {
+		start := time.Now()
+		require.True(t, q.Run(nil, "test/failing", PriorityNormal, func() error {
+			return nil
+		}))
+		require.True(t, time.Since(start) >= 40*time.Millisecond)
+	})
+
+	t.Run("Backoff is removed after success", func(t *testing.T) {
+		require.Zero(t, q.backoffOf("test/failing"))
+	})
+
+	t.Run("Stop during backoff", func(t *testing.T) {
+		require.True(t, q.Run(nil, "test/failing", PriorityNormal, func() error {
+			return errors.Newf("failed")
+		}))
+
+		stop := make(chan struct{})
+		close(stop)
+		require.False(t, q.Run(stop, "test/failing", PriorityNormal, func() error {
+			require.Fail(t, "function should not be executed")
+			return nil
+		}))
+
+		q.Forget("test/failing")
+		require.Zero(t, q.backoffOf("test/failing"))
+	})
+}
+
+func Test_Queue_Stop(t *testing.T) {
+	q := newTestQueue(t, 1, time.Millisecond)
+
+	release := make(chan struct{})
+	defer close(release)
+	started := make(chan struct{})
+	go q.Run(nil, "blocking", PriorityNormal, func() error {
+		close(started)
+		<-release
+		return nil
+	})
+	<-started
+
+	stop := make(chan struct{})
+	result := make(chan bool)
+	go func() {
+		result <- q.Run(stop, "pending", PriorityNormal, func() error {
+			return nil
+		})
+	}()
+	waitForPending(t, q, 1)
+
+	close(stop)
+	require.False(t, <-result)
+	waitForPending(t, q, 0)
+}
+
+func Test_Queue_Shutdown(t *testing.T) {
+	stop := make(chan struct{})
+	q := newQueue(1, workqueue.NewItemExponentialFailureRateLimiter(time.Millisecond, time.Millisecond), stop)
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	go q.Run(nil, "blocking", PriorityNormal, func() error {
+		close(started)
+		<-release
+		return nil
+	})
+	<-started
+
+	result := make(chan bool)
+	go func() {
+		result <- q.Run(nil, "pending", PriorityNormal, func() error {
+			return nil
+		})
+	}()
+	waitForPending(t, q, 1)
+
+	close(stop)
+	require.False(t, <-result)
+	close(release)
+
+	t.Run("Run after shutdown", func(t *testing.T) {
+		require.False(t, q.Run(nil, "other", PriorityNormal, func() error {
+			require.Fail(t, "function should not be executed")
+			return nil
+		}))
+	})
+}
@@ -41,6 +41,24 @@ import (
 
 type CreateMemberMod func(s *api.DeploymentStatus, g api.ServerGroup, m *api.MemberStatus) error
 
+// ActionResult is a strongly typed result of a finished plan action
+type ActionResult string
+
+const (
+	ActionResultSuccess ActionResult = "success"
+	ActionResultFailed  ActionResult = "failed"
+	ActionResultAborted ActionResult = "aborted"
+	ActionResultTimeout ActionResult = "timeout"
+)
+
+// ActionObserver is notified about the execution of plan actions.
+type ActionObserver interface {
+	// ActionStarted is called when the given plan action is started
+	ActionStarted(action api.Action)
+	// ActionFinished is called when the given plan action is finished with the given result
+	ActionFinished(action api.Action, result ActionResult)
+}
+
 // Context provides methods to the reconcile package.
 type Context interface {
 	resources.DeploymentStatusUpdate
@@ -51,6 +69,7 @@ type Context interface {
 	resources.DeploymentModInterfaces
 	resources.DeploymentCachedStatus
 	resources.ArangoAgencyGet
+	ActionObserver
 
 	// GetAPIObject returns the deployment as k8s object.
 	GetAPIObject() k8sutil.APIObject
@@ -98,6 +117,8 @@ type Context interface {
 	UpdatePvc(ctx context.Context, pvc *v1.PersistentVolumeClaim) error
 	// GetPvc gets a PVC by the given name, in the samespace of the deployment.
 	GetPvc(ctx context.Context, pvcName string) (*v1.PersistentVolumeClaim, error)
+	// GetPv gets a PV by the given name.
+	GetPv(ctx context.Context, pvName string) (*v1.PersistentVolume, error)
 	// GetTLSKeyfile returns the keyfile encoded TLS certificate+key for
 	// the given member.
 	GetTLSKeyfile(group api.ServerGroup, member api.MemberStatus) (string, error)
@@ -58,6 +58,8 @@ type PlanBuilderContext interface {
 	CreateEvent(evt *k8sutil.Event)
 	// GetPvc gets a PVC by the given name, in the samespace of the deployment.
 	GetPvc(ctx context.Context, pvcName string) (*core.PersistentVolumeClaim, error)
+	// GetPv gets a PV by the given name.
+	GetPv(ctx context.Context, pvName string) (*core.PersistentVolume, error)
 	// GetShardSyncStatus returns true if all shards are in sync
 	GetShardSyncStatus() bool
 	// InvalidateSyncStatus resets the sync state to false and triggers an inspection
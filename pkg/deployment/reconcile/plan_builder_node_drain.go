@@ -0,0 +1,143 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package reconcile
+
+import (
+	"context"
+
+	api "github.com/arangodb/kube-arangodb/pkg/apis/deployment/v1"
+	"github.com/arangodb/kube-arangodb/pkg/deployment/resources"
+	"github.com/arangodb/kube-arangodb/pkg/util/errors"
+	"github.com/arangodb/kube-arangodb/pkg/util/k8sutil"
+	inspectorInterface "github.com/arangodb/kube-arangodb/pkg/util/k8sutil/inspector"
+	"github.com/rs/zerolog"
+)
+
+// createNodeDrainPlan creates plan to relocate members which are scheduled on drained nodes.
+// DBServers are marked to be removed, so they are replaced and cleaned out.
+// Other members resign leadership and are restarted on another node.
+// Agents and single servers with node-local volumes cannot be restarted on another node, so they are skipped.
+func createNodeDrainPlan(ctx context.Context,
+	log zerolog.Logger, apiObject k8sutil.APIObject,
+	spec api.DeploymentSpec, status api.DeploymentStatus,
+	cachedStatus inspectorInterface.Inspector, context PlanBuilderContext) api.Plan {
+	if !spec.NodeDrain.IsEnabled() || spec.GetMode() == api.DeploymentModeSingle {
+		// Single server cannot be relocated without downtime
+		return nil
+	}
+
+	relocating := 0
+	status.Members.ForeachServerGroup(func(group api.ServerGroup, list api.MemberStatusList) error {
+		for _, m := range list {
+			if m.Conditions.IsTrue(api.ConditionTypeNodeDrain) && m.Conditions.IsTrue(api.ConditionTypeMarkedToRemove) {
+				relocating++
+			}
+		}
+		return nil
+	})
+
+	if relocating >= spec.NodeDrain.GetParallel() {
+		log.Debug().Int("relocating", relocating).Msg("Limit of members relocated from drained nodes reached")
+		return nil
+	}
+
+	var plan api.Plan
+
+	status.Members.ForeachServerGroup(func(group api.ServerGroup, list api.MemberStatusList) error {
+		for _, m := range list {
+			if !plan.IsEmpty() {
+				return nil
+			}
+
+			if !m.Conditions.IsTrue(api.ConditionTypeNodeDrain) || m.Conditions.IsTrue(api.ConditionTypeMarkedToRemove) {
+				continue
+			}
+
+			if !m.Phase.IsReady() || !m.Conditions.IsTrue(api.ConditionTypeReady) {
+				// Relocate only healthy members, failed members are handled by the resilience
+				continue
+			}
+
+			reason := "Member is scheduled on drained node"
+
+			if group == api.ServerGroupDBServers {
+				log.Info().Str("id", m.ID).Str("role", group.AsRole()).Msg("Creating replacement plan for member on drained node")
+				plan = append(plan, api.NewAction(api.ActionTypeMarkToRemoveMember, group, m.ID, reason))
+				return nil
+			}
+
+			if group == api.ServerGroupAgents || group == api.ServerGroupSingle {
+				if local, err := isMemberOnNodeLocalVolume(ctx, cachedStatus, context, m); err != nil {
+					log.Warn().Err(err).Str("id", m.ID).Str("role", group.AsRole()).Msg("Unable to check volume of member on drained node")
+					continue
+				} else if local {
+					log.Warn().Str("id", m.ID).Str("role", group.AsRole()).Msg("Member on drained node uses node-local volume and cannot be relocated")
+					continue
+				}
+			}
+
+			if !isDisruptionAllowed(apiObject, cachedStatus, group) {
+				log.Info().Str("id", m.ID).Str("role", group.AsRole()).Msg("Pod disruption budget does not allow relocation of member on drained node")
+				continue
+			}
+
+			plan = append(plan, createRotateMemberPlan(log, m, group, reason)...)
+			return nil
+		}
+
+		return nil
+	})
+
+	return plan
+}
+
+// isDisruptionAllowed returns false when pod disruption budget of the group does not allow to restart a member
+func isDisruptionAllowed(apiObject k8sutil.APIObject, cachedStatus inspectorInterface.Inspector, group api.ServerGroup) bool {
+	pdb, ok := cachedStatus.PodDisruptionBudget(resources.PDBNameForGroup(apiObject.GetName(), group))
+	if !ok {
+		return true
+	}
+
+	return pdb.Status.DisruptionsAllowed > 0
+}
+
+// isMemberOnNodeLocalVolume returns true when the member uses a volume which is bound to a single node
+func isMemberOnNodeLocalVolume(ctx context.Context, cachedStatus inspectorInterface.Inspector, planCtx PlanBuilderContext, m api.MemberStatus) (bool, error) {
+	if m.PersistentVolumeClaimName == "" {
+		return false, nil
+	}
+
+	pvc, ok := cachedStatus.PersistentVolumeClaim(m.PersistentVolumeClaimName)
+	if !ok {
+		return false, errors.Newf("PVC %s not found", m.PersistentVolumeClaimName)
+	}
+
+	if pvc.Spec.VolumeName == "" {
+		return false, nil
+	}
+
+	pv, err := planCtx.GetPv(ctx, pvc.Spec.VolumeName)
+	if err != nil {
+		return false, err
+	}
+
+	return k8sutil.IsPersistentVolumeNodeLocal(pv), nil
+}
@@ -0,0 +1,156 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package reconcile
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	core "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1beta1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/arangodb/kube-arangodb/pkg/apis/deployment/v1"
+	"github.com/arangodb/kube-arangodb/pkg/deployment/resources"
+	"github.com/arangodb/kube-arangodb/pkg/deployment/resources/inspector"
+	"github.com/arangodb/kube-arangodb/pkg/util"
+)
+
+func newNodeDrainMember(id string, drained, markedToRemove bool) api.MemberStatus {
+	m := api.MemberStatus{ID: id, Phase: api.MemberPhaseCreated}
+	m.Conditions.Update(api.ConditionTypeReady, true, "", "")
+	if drained {
+		m.Conditions.Update(api.ConditionTypeNodeDrain, true, "Node is cordoned", "")
+	}
+	if markedToRemove {
+		m.Conditions.Update(api.ConditionTypeMarkedToRemove, true, "", "")
+	}
+	return m
+}
+
+func Test_CreateNodeDrainPlan(t *testing.T) {
+	log := zerolog.Nop()
+	depl := &api.ArangoDeployment{
+		ObjectMeta: meta.ObjectMeta{
+			Name:      "test",
+			Namespace: "test",
+		},
+	}
+	spec := api.DeploymentSpec{
+		Mode:      api.NewMode(api.DeploymentModeCluster),
+		NodeDrain: &api.DeploymentNodeDrainSpec{Enabled: util.NewBool(true)},
+	}
+
+	pdbs := func(allowed int32) map[string]*policy.PodDisruptionBudget {
+		name := resources.PDBNameForGroup(depl.GetName(), api.ServerGroupCoordinators)
+		return map[string]*policy.PodDisruptionBudget{
+			name: {
+				ObjectMeta: meta.ObjectMeta{Name: name},
+				Status:     policy.PodDisruptionBudgetStatus{DisruptionsAllowed: allowed},
+			},
+		}
+	}
+
+	t.Run("Replace DBServer", func(t *testing.T) {
+		var status api.DeploymentStatus
+		status.Members.DBServers = api.MemberStatusList{newNodeDrainMember("PRMR-1", false, false), newNodeDrainMember("PRMR-2", true, false)}
+
+		plan := createNodeDrainPlan(context.Background(), log, depl, spec, status, inspector.NewEmptyInspector(), nil)
+		require.Len(t, plan, 1)
+		require.Equal(t, api.ActionTypeMarkToRemoveMember, plan[0].Type)
+		require.Equal(t, "PRMR-2", plan[0].MemberID)
+	})
+
+	t.Run("Respect parallel limit", func(t *testing.T) {
+		var status api.DeploymentStatus
+		status.Members.DBServers = api.MemberStatusList{newNodeDrainMember("PRMR-1", true, true), newNodeDrainMember("PRMR-2", true, false)}
+
+		require.Empty(t, createNodeDrainPlan(context.Background(), log, depl, spec, status, inspector.NewEmptyInspector(), nil))
+
+		parallelSpec := spec
+		parallelSpec.NodeDrain = &api.DeploymentNodeDrainSpec{Enabled: util.NewBool(true), Parallel: util.NewInt(2)}
+		plan := createNodeDrainPlan(context.Background(), log, depl, parallelSpec, status, inspector.NewEmptyInspector(), nil)
+		require.Len(t, plan, 1)
+		require.Equal(t, "PRMR-2", plan[0].MemberID)
+	})
+
+	t.Run("Rotate coordinator", func(t *testing.T) {
+		var status api.DeploymentStatus
+		status.Members.Coordinators = api.MemberStatusList{newNodeDrainMember("CRDN-1", true, false)}
+
+		blocked := inspector.NewInspectorFromData(nil, nil, nil, nil, nil, pdbs(0), nil, nil, nil)
+		require.Empty(t, createNodeDrainPlan(context.Background(), log, depl, spec, status, blocked, nil))
+
+		allowed := inspector.NewInspectorFromData(nil, nil, nil, nil, nil, pdbs(1), nil, nil, nil)
+		plan := createNodeDrainPlan(context.Background(), log, depl, spec, status, allowed, nil)
+		require.NotEmpty(t, plan)
+		require.Equal(t, api.ActionTypeResignLeadership, plan[1].Type)
+		require.Equal(t, api.ActionTypeKillMemberPod, plan[2].Type)
+		require.Equal(t, "CRDN-1", plan[2].MemberID)
+	})
+
+	t.Run("Skip agent on node-local volume", func(t *testing.T) {
+		var status api.DeploymentStatus
+		agent := newNodeDrainMember("AGNT-1", true, false)
+		agent.PersistentVolumeClaimName = "agent-pvc"
+		status.Members.Agents = api.MemberStatusList{agent}
+
+		pvcs := map[string]*core.PersistentVolumeClaim{
+			"agent-pvc": {
+				ObjectMeta: meta.ObjectMeta{Name: "agent-pvc"},
+				Spec:       core.PersistentVolumeClaimSpec{VolumeName: "agent-pv"},
+			},
+		}
+		cachedStatus := inspector.NewInspectorFromData(nil, nil, pvcs, nil, nil, nil, nil, nil, nil)
+
+		local := &testContext{PV: &core.PersistentVolume{
+			ObjectMeta: meta.ObjectMeta{Name: "agent-pv"},
+			Spec: core.PersistentVolumeSpec{
+				PersistentVolumeSource: core.PersistentVolumeSource{
+					Local: &core.LocalVolumeSource{Path: "/var/lib/arangodb"},
+				},
+			},
+		}}
+		require.Empty(t, createNodeDrainPlan(context.Background(), log, depl, spec, status, cachedStatus, local))
+
+		remote := &testContext{PV: &core.PersistentVolume{
+			ObjectMeta: meta.ObjectMeta{Name: "agent-pv"},
+		}}
+		plan := createNodeDrainPlan(context.Background(), log, depl, spec, status, cachedStatus, remote)
+		require.NotEmpty(t, plan)
+		require.Equal(t, "AGNT-1", plan[len(plan)-1].MemberID)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		var status api.DeploymentStatus
+		status.Members.DBServers = api.MemberStatusList{newNodeDrainMember("PRMR-1", true, false)}
+
+		require.Empty(t, createNodeDrainPlan(context.Background(), log, depl, api.DeploymentSpec{
+			Mode: api.NewMode(api.DeploymentModeCluster),
+		}, status, inspector.NewEmptyInspector(), nil), "disabled by default")
+
+		disabledSpec := spec
+		disabledSpec.NodeDrain = &api.DeploymentNodeDrainSpec{Enabled: util.NewBool(false)}
+		require.Empty(t, createNodeDrainPlan(context.Background(), log, depl, disabledSpec, status, inspector.NewEmptyInspector(), nil))
+	})
+}
@@ -84,6 +84,14 @@ func createNormalPlan(ctx context.Context, log zerolog.Logger, apiObject k8sutil
 		return currentPlan, false
 	}
 
+	if isDeploymentSuspended(spec, status) {
+		// Only suspend or resume members of suspended deployment
+		return recoverPlanAppender(log, newPlanAppender(NewWithPlanBuilder(ctx, log, apiObject, spec, status, cachedStatus, builderCtx), currentPlan).
+			ApplyIfEmpty(createSuspendPlan).
+			ApplyIfEmpty(createResumePlan)).
+			Plan(), true
+	}
+
 	return recoverPlanAppender(log, newPlanAppender(NewWithPlanBuilder(ctx, log, apiObject, spec, status, cachedStatus, builderCtx), currentPlan).
 		// Adjust topology settings
 		ApplyIfEmpty(createTopologyMemberAdjustmentPlan).
@@ -102,6 +110,8 @@ func createNormalPlan(ctx context.Context, log zerolog.Logger, apiObject k8sutil
 		ApplyIfEmpty(createJWTStatusUpdate).
 		// Check for cleaned out dbserver in created state
 		ApplyIfEmpty(createRemoveCleanedDBServersPlan).
+		// Check for members on drained nodes
+		ApplyIfEmpty(createNodeDrainPlan).
 		// Check for members to be removed
 		ApplyIfEmpty(createReplaceMemberPlan).
 		// Check for the need to rotate one or more members
@@ -0,0 +1,183 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package reconcile
+
+import (
+	"context"
+
+	api "github.com/arangodb/kube-arangodb/pkg/apis/deployment/v1"
+	"github.com/arangodb/kube-arangodb/pkg/util/k8sutil"
+	inspectorInterface "github.com/arangodb/kube-arangodb/pkg/util/k8sutil/inspector"
+	"github.com/rs/zerolog"
+)
+
+// suspendOrder defines the order in which server groups are shut down when the deployment is suspended.
+// Members are started again in the reverse order.
+var suspendOrder = []api.ServerGroup{
+	api.ServerGroupCoordinators,
+	api.ServerGroupSyncWorkers,
+	api.ServerGroupSyncMasters,
+	api.ServerGroupDBServers,
+	api.ServerGroupSingle,
+	api.ServerGroupAgents,
+}
+
+// isDeploymentSuspended returns true when the deployment is suspended or is being suspended or resumed.
+// Members are left shut down when the suspension is cancelled before the deployment is suspended,
+// such deployment is resumed as well. Members shut down by the suspension have the Suspended condition,
+// other members are shut down e.g. during scale down.
+func isDeploymentSuspended(spec api.DeploymentSpec, status api.DeploymentStatus) bool {
+	return spec.IsSuspended() || status.Phase.IsSuspended() || hasSuspendedMembers(status)
+}
+
+// hasSuspendedMembers returns true when any member is shut down because the deployment is suspended
+func hasSuspendedMembers(status api.DeploymentStatus) bool {
+	for _, m := range status.Members.AsList() {
+		if m.Member.Conditions.IsTrue(api.ConditionTypeSuspended) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// createSuspendPlan creates plan to enable maintenance and shut down all members of the suspended deployment
+func createSuspendPlan(ctx context.Context,
+	log zerolog.Logger, apiObject k8sutil.APIObject,
+	spec api.DeploymentSpec, status api.DeploymentStatus,
+	cachedStatus inspectorInterface.Inspector, context PlanBuilderContext) api.Plan {
+	if !spec.IsSuspended() || status.Phase.IsSuspended() {
+		return nil
+	}
+
+	var shutdown api.Plan
+
+	for _, group := range suspendOrder {
+		members := status.Members.MembersOfGroup(group)
+		for _, m := range members {
+			if m.Conditions.IsTrue(api.ConditionTypeSuspended) {
+				continue
+			}
+
+			shutdown = append(shutdown, api.NewAction(api.ActionTypeSetMemberCondition, group, m.ID, "Deployment is suspended").
+				AddParam(api.ConditionTypeSuspended.String(), "T"))
+
+			switch {
+			case m.Phase == api.MemberPhaseShuttingDown:
+				// Member is already shut down, it is started again when the deployment is resumed
+				continue
+			case m.Phase.IsPending():
+				// Pod of the member is not created yet
+				shutdown = append(shutdown, api.NewAction(api.ActionTypeMemberPhaseUpdate, group, m.ID, "Deployment is suspended").
+					AddParam(actionTypeMemberPhaseUpdatePhaseKey, api.MemberPhaseShuttingDown.String()))
+			default:
+				shutdown = append(shutdown, api.NewAction(api.ActionTypeShutdownMember, group, m.ID, "Deployment is suspended"))
+			}
+		}
+	}
+
+	if len(shutdown) == 0 {
+		return nil
+	}
+
+	log.Info().Int("members", len(shutdown)).Msg("Creating plan to suspend deployment")
+
+	var plan api.Plan
+	if spec.GetMode() != api.DeploymentModeSingle {
+		plan = append(plan, enableSuspendMaintenance("Deployment is suspended")...)
+	}
+
+	return append(plan, shutdown...)
+}
+
+// createResumePlan creates plan to start all members of the deployment which is not suspended anymore,
+// also when the deployment was only partially suspended
+func createResumePlan(ctx context.Context,
+	log zerolog.Logger, apiObject k8sutil.APIObject,
+	spec api.DeploymentSpec, status api.DeploymentStatus,
+	cachedStatus inspectorInterface.Inspector, context PlanBuilderContext) api.Plan {
+	if spec.IsSuspended() {
+		return nil
+	}
+
+	var plan api.Plan
+	started := 0
+
+	for id := len(suspendOrder) - 1; id >= 0; id-- {
+		group := suspendOrder[id]
+
+		var start, wait, resumed api.Plan
+		for _, m := range status.Members.MembersOfGroup(group) {
+			if !m.Conditions.IsTrue(api.ConditionTypeSuspended) {
+				continue
+			}
+
+			// Condition is removed when the member is up, until then failures of the member are not detected
+			resumed = append(resumed, api.NewAction(api.ActionTypeSetMemberCondition, group, m.ID, "Deployment is resumed").
+				AddParam(api.ConditionTypeSuspended.String(), ""))
+
+			if m.Phase != api.MemberPhaseShuttingDown {
+				// Member was not shut down yet when the suspension was cancelled
+				started++
+				continue
+			}
+
+			start = append(start,
+				api.NewAction(api.ActionTypeArangoMemberUpdatePodSpec, group, m.ID, "Propagating spec of pod"),
+				api.NewAction(api.ActionTypeArangoMemberUpdatePodStatus, group, m.ID, "Propagating status of pod"),
+				api.NewAction(api.ActionTypeMemberPhaseUpdate, group, m.ID, "Deployment is resumed").
+					AddParam(actionTypeMemberPhaseUpdatePhaseKey, api.MemberPhasePending.String()))
+			wait = append(wait, api.NewAction(api.ActionTypeWaitForMemberUp, group, m.ID))
+			started++
+		}
+
+		// All members of the group are started at once, agents need a quorum to become healthy
+		plan = append(plan, start...)
+		plan = append(plan, wait...)
+		plan = append(plan, resumed...)
+
+		if group == api.ServerGroupAgents && spec.GetMode() != api.DeploymentModeSingle {
+			// Maintenance could expire while the deployment was suspended
+			plan = append(plan, enableSuspendMaintenance("Deployment is resumed")...)
+		}
+	}
+
+	if started == 0 {
+		return nil
+	}
+
+	log.Info().Int("members", started).Msg("Creating plan to resume deployment")
+
+	if spec.GetMode() != api.DeploymentModeSingle && !spec.Database.GetMaintenance() {
+		plan = append(plan,
+			api.NewAction(api.ActionTypeDisableMaintenance, api.ServerGroupUnknown, "", "Deployment is resumed"),
+			api.NewAction(api.ActionTypeSetMaintenanceCondition, api.ServerGroupUnknown, "", "Deployment is resumed"))
+	}
+
+	return plan
+}
+
+func enableSuspendMaintenance(reason string) api.Plan {
+	return api.Plan{
+		api.NewAction(api.ActionTypeEnableMaintenance, api.ServerGroupUnknown, "", reason),
+		api.NewAction(api.ActionTypeSetMaintenanceCondition, api.ServerGroupUnknown, "", reason),
+	}
+}
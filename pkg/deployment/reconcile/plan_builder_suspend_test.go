@@ -0,0 +1,192 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package reconcile
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/arangodb/kube-arangodb/pkg/apis/deployment/v1"
+	"github.com/arangodb/kube-arangodb/pkg/deployment/resources/inspector"
+	"github.com/arangodb/kube-arangodb/pkg/util"
+)
+
+func newSuspendTestStatus(phase api.MemberPhase) api.DeploymentStatus {
+	status := api.DeploymentStatus{Phase: api.DeploymentPhaseRunning}
+	status.Members.Agents = api.MemberStatusList{{ID: "AGNT-1", Phase: phase}, {ID: "AGNT-2", Phase: phase}}
+	status.Members.DBServers = api.MemberStatusList{{ID: "PRMR-1", Phase: phase}}
+	status.Members.Coordinators = api.MemberStatusList{{ID: "CRDN-1", Phase: phase}}
+
+	if phase == api.MemberPhaseShuttingDown {
+		// Members are shut down by the suspension
+		for _, m := range status.Members.AsList() {
+			m.Member.Conditions.Update(api.ConditionTypeSuspended, true, "", "")
+			status.Members.Update(m.Member, m.Group)
+		}
+	}
+
+	return status
+}
+
+func planSummary(plan api.Plan) []string {
+	var r []string
+	for _, a := range plan {
+		r = append(r, string(a.Type)+":"+a.MemberID)
+	}
+	return r
+}
+
+func Test_CreateSuspendPlan(t *testing.T) {
+	ctx := context.Background()
+	log := zerolog.Nop()
+	depl := &api.ArangoDeployment{
+		ObjectMeta: meta.ObjectMeta{
+			Name:      "test",
+			Namespace: "test",
+		},
+	}
+	spec := api.DeploymentSpec{
+		Mode:      api.NewMode(api.DeploymentModeCluster),
+		Suspended: util.NewBool(true),
+	}
+
+	t.Run("Suspend", func(t *testing.T) {
+		status := newSuspendTestStatus(api.MemberPhaseCreated)
+
+		plan, changed := createNormalPlan(ctx, log, depl, nil, spec, status, inspector.NewEmptyInspector(), &testContext{})
+		require.True(t, changed)
+		require.Equal(t, []string{
+			"EnableMaintenance:",
+			"SetMaintenanceCondition:",
+			"SetMemberCondition:CRDN-1",
+			"ShutdownMember:CRDN-1",
+			"SetMemberCondition:PRMR-1",
+			"ShutdownMember:PRMR-1",
+			"SetMemberCondition:AGNT-1",
+			"ShutdownMember:AGNT-1",
+			"SetMemberCondition:AGNT-2",
+			"ShutdownMember:AGNT-2",
+		}, planSummary(plan))
+		require.Equal(t, "T", plan[2].Params[api.ConditionTypeSuspended.String()])
+
+		plan, _ = createHighPlan(ctx, log, depl, nil, spec, status, inspector.NewEmptyInspector(), &testContext{})
+		require.Empty(t, plan)
+	})
+
+	t.Run("Suspended", func(t *testing.T) {
+		status := newSuspendTestStatus(api.MemberPhaseShuttingDown)
+		status.Phase = api.DeploymentPhaseSuspended
+
+		plan, _ := createNormalPlan(ctx, log, depl, nil, spec, status, inspector.NewEmptyInspector(), &testContext{})
+		require.Empty(t, plan)
+	})
+
+	t.Run("Resume", func(t *testing.T) {
+		status := newSuspendTestStatus(api.MemberPhaseShuttingDown)
+		status.Phase = api.DeploymentPhaseSuspended
+
+		resumed := spec
+		resumed.Suspended = nil
+
+		plan, changed := createNormalPlan(ctx, log, depl, nil, resumed, status, inspector.NewEmptyInspector(), &testContext{})
+		require.True(t, changed)
+		require.Equal(t, []string{
+			"ArangoMemberUpdatePodSpec:AGNT-1",
+			"ArangoMemberUpdatePodStatus:AGNT-1",
+			"MemberPhaseUpdate:AGNT-1",
+			"ArangoMemberUpdatePodSpec:AGNT-2",
+			"ArangoMemberUpdatePodStatus:AGNT-2",
+			"MemberPhaseUpdate:AGNT-2",
+			"WaitForMemberUp:AGNT-1",
+			"WaitForMemberUp:AGNT-2",
+			"SetMemberCondition:AGNT-1",
+			"SetMemberCondition:AGNT-2",
+			"EnableMaintenance:",
+			"SetMaintenanceCondition:",
+			"ArangoMemberUpdatePodSpec:PRMR-1",
+			"ArangoMemberUpdatePodStatus:PRMR-1",
+			"MemberPhaseUpdate:PRMR-1",
+			"WaitForMemberUp:PRMR-1",
+			"SetMemberCondition:PRMR-1",
+			"ArangoMemberUpdatePodSpec:CRDN-1",
+			"ArangoMemberUpdatePodStatus:CRDN-1",
+			"MemberPhaseUpdate:CRDN-1",
+			"WaitForMemberUp:CRDN-1",
+			"SetMemberCondition:CRDN-1",
+			"DisableMaintenance:",
+			"SetMaintenanceCondition:",
+		}, planSummary(plan))
+		require.Equal(t, api.MemberPhasePending.String(), plan[2].Params[actionTypeMemberPhaseUpdatePhaseKey])
+	})
+
+	t.Run("Resume partially suspended", func(t *testing.T) {
+		// Suspension is cancelled before agents are shut down
+		status := newSuspendTestStatus(api.MemberPhaseShuttingDown)
+		status.Members.Agents = api.MemberStatusList{{ID: "AGNT-1", Phase: api.MemberPhaseCreated}, {ID: "AGNT-2", Phase: api.MemberPhaseCreated}}
+
+		resumed := spec
+		resumed.Suspended = nil
+
+		plan, _ := createHighPlan(ctx, log, depl, nil, resumed, status, inspector.NewEmptyInspector(), &testContext{})
+		require.Empty(t, plan)
+
+		plan, changed := createNormalPlan(ctx, log, depl, nil, resumed, status, inspector.NewEmptyInspector(), &testContext{})
+		require.True(t, changed)
+		require.Equal(t, []string{
+			"EnableMaintenance:",
+			"SetMaintenanceCondition:",
+			"ArangoMemberUpdatePodSpec:PRMR-1",
+			"ArangoMemberUpdatePodStatus:PRMR-1",
+			"MemberPhaseUpdate:PRMR-1",
+			"WaitForMemberUp:PRMR-1",
+			"SetMemberCondition:PRMR-1",
+			"ArangoMemberUpdatePodSpec:CRDN-1",
+			"ArangoMemberUpdatePodStatus:CRDN-1",
+			"MemberPhaseUpdate:CRDN-1",
+			"WaitForMemberUp:CRDN-1",
+			"SetMemberCondition:CRDN-1",
+			"DisableMaintenance:",
+			"SetMaintenanceCondition:",
+		}, planSummary(plan))
+	})
+	t.Run("Not suspended with shutting down member", func(t *testing.T) {
+		// Member is shut down by a scale down or replacement, not by the suspension
+		status := newSuspendTestStatus(api.MemberPhaseCreated)
+		status.Members.DBServers = api.MemberStatusList{{ID: "PRMR-1", Phase: api.MemberPhaseShuttingDown}}
+		status.Members.Coordinators = api.MemberStatusList{{ID: "CRDN-1", Phase: api.MemberPhaseNone}}
+
+		resumed := spec
+		resumed.Suspended = nil
+
+		require.False(t, isDeploymentSuspended(resumed, status))
+
+		plan, _ := createHighPlan(ctx, log, depl, nil, resumed, status, inspector.NewEmptyInspector(), &testContext{})
+		require.Equal(t, []string{
+			"ArangoMemberUpdatePodSpec:CRDN-1",
+			"ArangoMemberUpdatePodStatus:CRDN-1",
+			"MemberPhaseUpdate:CRDN-1",
+		}, planSummary(plan))
+	})
+}
@@ -88,7 +88,9 @@ type testContext struct {
 	ArangoDeployment *api.ArangoDeployment
 	PVC              *core.PersistentVolumeClaim
 	PVCErr           error
+	PV               *core.PersistentVolume
 	RecordedEvent    *k8sutil.Event
+	ActionResults    []ActionResult
 }
 
 func (c *testContext) GetAgencyCache() (agencyCache.State, bool) {
@@ -345,11 +347,26 @@ func (c *testContext) CreateEvent(evt *k8sutil.Event) {
 	c.RecordedEvent = evt
 }
 
+func (c *testContext) ActionStarted(_ api.Action) {
+}
+
+func (c *testContext) ActionFinished(_ api.Action, result ActionResult) {
+	c.ActionResults = append(c.ActionResults, result)
+}
+
 // GetPvc gets a PVC by the given name, in the samespace of the deployment.
 func (c *testContext) GetPvc(_ context.Context, pvcName string) (*core.PersistentVolumeClaim, error) {
 	return c.PVC, c.PVCErr
 }
 
+// GetPv gets a PV by the given name.
+func (c *testContext) GetPv(_ context.Context, pvName string) (*core.PersistentVolume, error) {
+	if c.PV == nil || c.PV.GetName() != pvName {
+		return nil, errors.WithStack(errors.Newf("PV %s not found", pvName))
+	}
+	return c.PV, nil
+}
+
 // GetExpectedPodArguments creates command line arguments for a server in the given group with given ID.
 func (c *testContext) GetExpectedPodArguments(apiObject meta.Object, deplSpec api.DeploymentSpec, group api.ServerGroup,
 	agents api.MemberStatusList, id string, version driver.Version) []string {
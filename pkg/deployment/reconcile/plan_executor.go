@@ -44,8 +44,38 @@ var (
 	actionsGeneratedMetrics = metrics.MustRegisterCounterVec(reconciliationComponent, "actions_generated", "Number of actions added to the plan", metrics.DeploymentName, metrics.ActionName, metrics.ActionPriority)
 	actionsSucceededMetrics = metrics.MustRegisterCounterVec(reconciliationComponent, "actions_succeeded", "Number of succeeded actions", metrics.DeploymentName, metrics.ActionName, metrics.ActionPriority)
 	actionsFailedMetrics    = metrics.MustRegisterCounterVec(reconciliationComponent, "actions_failed", "Number of failed actions", metrics.DeploymentName, metrics.ActionName, metrics.ActionPriority)
+	actionsTimeoutMetrics   = metrics.MustRegisterCounterVec(reconciliationComponent, "actions_timeout", "Number of actions which did not finish in time", metrics.DeploymentName, metrics.ActionName, metrics.ServerGroup)
+	actionsAbortedMetrics   = metrics.MustRegisterCounterVec(reconciliationComponent, "actions_aborted", "Number of aborted actions", metrics.DeploymentName, metrics.ActionName, metrics.ServerGroup)
+	actionsDurationMetrics  = metrics.MustRegisterHistogramVec(reconciliationComponent, "actions_duration_seconds", "Duration of succeeded actions (in sec)", metrics.DurationBuckets, metrics.DeploymentName, metrics.ActionName, metrics.ServerGroup)
+	planLengthMetrics       = metrics.MustRegisterGaugeVec(reconciliationComponent, "plan_length", "Number of actions in the plan", metrics.DeploymentName, metrics.ActionPriority)
 )
 
+// DeleteMetrics removes the plan and action metrics of the given deployment.
+func DeleteMetrics(deploymentName string) {
+	groups := append([]api.ServerGroup{api.ServerGroupUnknown}, api.AllServerGroups...)
+
+	actionsLock.Lock()
+	defer actionsLock.Unlock()
+
+	for _, pg := range []planner{plannerHigh{}, plannerNormal{}} {
+		planLengthMetrics.DeleteLabelValues(deploymentName, pg.Type())
+
+		for t := range actions {
+			actionsGeneratedMetrics.DeleteLabelValues(deploymentName, t.String(), pg.Type())
+			actionsSucceededMetrics.DeleteLabelValues(deploymentName, t.String(), pg.Type())
+			actionsFailedMetrics.DeleteLabelValues(deploymentName, t.String(), pg.Type())
+		}
+	}
+
+	for t := range actions {
+		for _, group := range groups {
+			actionsTimeoutMetrics.DeleteLabelValues(deploymentName, t.String(), group.AsRole())
+			actionsAbortedMetrics.DeleteLabelValues(deploymentName, t.String(), group.AsRole())
+			actionsDurationMetrics.DeleteLabelValues(deploymentName, t.String(), group.AsRole())
+		}
+	}
+}
+
 type planner interface {
 	Get(deployment *api.DeploymentStatus) api.Plan
 	Set(deployment *api.DeploymentStatus, p api.Plan) bool
@@ -122,12 +152,17 @@ func (d *Reconciler) executePlanStatus(ctx context.Context, cachedStatus inspect
 
 	plan := pg.Get(&loopStatus)
 
+	planLength := planLengthMetrics.WithLabelValues(d.context.GetName(), pg.Type())
+	planLength.Set(float64(len(plan)))
+
 	if len(plan) == 0 {
 		return false, nil
 	}
 
 	newPlan, callAgain, err := d.executePlan(ctx, cachedStatus, log, plan, pg)
 
+	planLength.Set(float64(len(newPlan)))
+
 	// Refresh current status
 	loopStatus, lastVersion := d.context.GetStatus()
 
@@ -177,9 +212,15 @@ func (d *Reconciler) executePlan(ctx context.Context, cachedStatus inspectorInte
 
 		action := d.createAction(log, planAction, cachedStatus)
 
+		actionStart := time.Now()
+		if !planAction.StartTime.IsZero() {
+			actionStart = planAction.StartTime.Time
+		}
+
 		done, abort, recall, err := d.executeAction(ctx, log, planAction, action)
 		if err != nil {
 			actionsFailedMetrics.WithLabelValues(d.context.GetName(), planAction.Type.String(), pg.Type()).Inc()
+			d.context.ActionFinished(planAction, ActionResultFailed)
 			return nil, false, errors.WithStack(err)
 		}
 
@@ -190,6 +231,8 @@ func (d *Reconciler) executePlan(ctx context.Context, cachedStatus inspectorInte
 
 		if done {
 			actionsSucceededMetrics.WithLabelValues(d.context.GetName(), planAction.Type.String(), pg.Type()).Inc()
+			d.context.ActionFinished(planAction, ActionResultSuccess)
+			metrics.ObserveDuration(actionsDurationMetrics.WithLabelValues(d.context.GetName(), planAction.Type.String(), planAction.Group.AsRole()), actionStart)
 			if len(plan) > 1 {
 				plan = plan[1:]
 				if plan[0].MemberID == api.MemberIDPreviousAction {
@@ -231,6 +274,7 @@ func (d *Reconciler) executePlan(ctx context.Context, cachedStatus inspectorInte
 func (d *Reconciler) executeAction(ctx context.Context, log zerolog.Logger, planAction api.Action, action Action) (done, abort, callAgain bool, err error) {
 	if planAction.StartTime.IsZero() {
 		// Not started yet
+		d.context.ActionStarted(planAction)
 		ready, err := action.Start(ctx)
 		if err != nil {
 			log.Error().Err(err).Msg("Failed to start action")
@@ -262,11 +306,15 @@ func (d *Reconciler) executeAction(ctx context.Context, log zerolog.Logger, plan
 
 	if abort {
 		log.Warn().Msg("Action aborted. Removing the entire plan")
+		actionsAbortedMetrics.WithLabelValues(d.context.GetName(), planAction.Type.String(), planAction.Group.AsRole()).Inc()
 		d.context.CreateEvent(k8sutil.NewPlanAbortedEvent(d.context.GetAPIObject(), string(planAction.Type), planAction.MemberID, planAction.Group.AsRole()))
+		d.context.ActionFinished(planAction, ActionResultAborted)
 		return false, true, false, nil
 	} else if time.Now().After(planAction.CreationTime.Add(action.Timeout(d.context.GetSpec()))) {
 		log.Warn().Msg("Action not finished in time. Removing the entire plan")
+		actionsTimeoutMetrics.WithLabelValues(d.context.GetName(), planAction.Type.String(), planAction.Group.AsRole()).Inc()
 		d.context.CreateEvent(k8sutil.NewPlanTimeoutEvent(d.context.GetAPIObject(), string(planAction.Type), planAction.MemberID, planAction.Group.AsRole()))
+		d.context.ActionFinished(planAction, ActionResultTimeout)
 		return false, true, false, nil
 	}
 
@@ -0,0 +1,117 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package reconcile
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/arangodb/kube-arangodb/pkg/apis/deployment/v1"
+)
+
+type testProgressAction struct {
+	ready, abort bool
+}
+
+func (a testProgressAction) Start(_ context.Context) (bool, error) {
+	return false, nil
+}
+
+func (a testProgressAction) CheckProgress(_ context.Context) (bool, bool, error) {
+	return a.ready, a.abort, nil
+}
+
+func (a testProgressAction) Timeout(_ api.DeploymentSpec) time.Duration {
+	return time.Minute
+}
+
+func (a testProgressAction) MemberID() string {
+	return ""
+}
+
+func TestExecuteAction_Metrics(t *testing.T) {
+	c := &testContext{
+		ArangoDeployment: &api.ArangoDeployment{},
+	}
+	logger := zerolog.Nop()
+	r := NewReconciler(logger, c)
+
+	started := metav1.NewTime(time.Now())
+	planAction := api.Action{
+		Type:         api.ActionTypeRotateMember,
+		Group:        api.ServerGroupDBServers,
+		CreationTime: metav1.NewTime(time.Now().Add(-time.Hour)),
+		StartTime:    &started,
+	}
+
+	t.Run("Timeout", func(t *testing.T) {
+		counter := actionsTimeoutMetrics.WithLabelValues(c.GetName(), planAction.Type.String(), planAction.Group.AsRole())
+		before := testutil.ToFloat64(counter)
+
+		done, abort, _, err := r.executeAction(context.Background(), logger, planAction, testProgressAction{})
+		require.NoError(t, err)
+		assert.False(t, done)
+		assert.True(t, abort)
+
+		assert.Equal(t, before+1, testutil.ToFloat64(counter))
+		assert.Equal(t, ActionResultTimeout, c.ActionResults[len(c.ActionResults)-1])
+	})
+
+	t.Run("Abort", func(t *testing.T) {
+		counter := actionsAbortedMetrics.WithLabelValues(c.GetName(), planAction.Type.String(), planAction.Group.AsRole())
+		before := testutil.ToFloat64(counter)
+
+		_, abort, _, err := r.executeAction(context.Background(), logger, planAction, testProgressAction{abort: true})
+		require.NoError(t, err)
+		assert.True(t, abort)
+
+		assert.Equal(t, before+1, testutil.ToFloat64(counter))
+		assert.Equal(t, ActionResultAborted, c.ActionResults[len(c.ActionResults)-1])
+	})
+}
+
+func TestDeleteMetrics(t *testing.T) {
+	planLengthMetrics.WithLabelValues("removed", "high").Set(1)
+	planLengthMetrics.WithLabelValues("other", "high").Set(1)
+	actionsGeneratedMetrics.WithLabelValues("removed", api.ActionTypeRotateMember.String(), "normal").Inc()
+	actionsTimeoutMetrics.WithLabelValues("removed", api.ActionTypeRotateMember.String(), api.ServerGroupDBServers.AsRole()).Inc()
+	actionsAbortedMetrics.WithLabelValues("removed", api.ActionTypeAddMember.String(), api.ServerGroupUnknown.AsRole()).Inc()
+
+	planLength := testutil.CollectAndCount(planLengthMetrics)
+	generated := testutil.CollectAndCount(actionsGeneratedMetrics)
+	timeouts := testutil.CollectAndCount(actionsTimeoutMetrics)
+	aborted := testutil.CollectAndCount(actionsAbortedMetrics)
+
+	DeleteMetrics("removed")
+
+	assert.Equal(t, planLength-1, testutil.CollectAndCount(planLengthMetrics))
+	assert.Equal(t, generated-1, testutil.CollectAndCount(actionsGeneratedMetrics))
+	assert.Equal(t, timeouts-1, testutil.CollectAndCount(actionsTimeoutMetrics))
+	assert.Equal(t, aborted-1, testutil.CollectAndCount(actionsAbortedMetrics))
+	assert.Equal(t, float64(1), testutil.ToFloat64(planLengthMetrics.WithLabelValues("other", "high")))
+}
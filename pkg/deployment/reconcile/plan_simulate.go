@@ -0,0 +1,41 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package reconcile
+
+import (
+	"context"
+
+	api "github.com/arangodb/kube-arangodb/pkg/apis/deployment/v1"
+	"github.com/arangodb/kube-arangodb/pkg/util/k8sutil"
+	inspectorInterface "github.com/arangodb/kube-arangodb/pkg/util/k8sutil/inspector"
+	"github.com/rs/zerolog"
+)
+
+// SimulatePlan returns the high priority and the normal plan which would be created for the given spec & status.
+// Plans which already exist in the status are returned unchanged, as they need to be completed first.
+// The plans are neither saved nor announced with events.
+func SimulatePlan(ctx context.Context, log zerolog.Logger, apiObject k8sutil.APIObject, spec api.DeploymentSpec,
+	status api.DeploymentStatus, cachedStatus inspectorInterface.Inspector, builderCtx PlanBuilderContext) (api.Plan, api.Plan) {
+	highPlan, _ := createHighPlan(ctx, log, apiObject, status.HighPriorityPlan, spec, status, cachedStatus, builderCtx)
+	normalPlan, _ := createNormalPlan(ctx, log, apiObject, status.Plan, spec, status, cachedStatus, builderCtx)
+
+	return highPlan, normalPlan
+}
@@ -0,0 +1,77 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package reconcile
+
+import (
+	"context"
+	"testing"
+
+	api "github.com/arangodb/kube-arangodb/pkg/apis/deployment/v1"
+	"github.com/arangodb/kube-arangodb/pkg/deployment/resources/inspector"
+	"github.com/arangodb/kube-arangodb/pkg/util"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSimulatePlan(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c := &testContext{}
+	spec := api.DeploymentSpec{
+		Mode: api.NewMode(api.DeploymentModeActiveFailover),
+	}
+	spec.SetDefaults("test")
+	depl := &api.ArangoDeployment{
+		ObjectMeta: meta.ObjectMeta{
+			Name:      "test_depl",
+			Namespace: "test",
+		},
+		Spec: spec,
+	}
+
+	var status api.DeploymentStatus
+	status.Hashes.JWT.Propagated = true
+	status.Hashes.TLS.Propagated = true
+	status.Hashes.Encryption.Propagated = true
+	status.Members.Agents = api.MemberStatusList{{ID: "a1", Phase: api.MemberPhaseCreated}, {ID: "a2", Phase: api.MemberPhaseCreated}, {ID: "a3", Phase: api.MemberPhaseCreated}}
+	status.Members.Single = api.MemberStatusList{{ID: "s1", Phase: api.MemberPhaseCreated}, {ID: "s2", Phase: api.MemberPhaseCreated}}
+
+	t.Run("Proposed scale up", func(t *testing.T) {
+		proposed := spec
+		proposed.Single.Count = util.NewInt(3)
+
+		highPlan, normalPlan := SimulatePlan(ctx, zerolog.Nop(), depl, proposed, status, inspector.NewEmptyInspector(), c)
+		require.Empty(t, highPlan)
+		require.Len(t, normalPlan, 1)
+		require.Equal(t, api.ActionTypeAddMember, normalPlan[0].Type)
+		require.Equal(t, api.ServerGroupSingle, normalPlan[0].Group)
+	})
+
+	t.Run("Existing plan", func(t *testing.T) {
+		current := status.DeepCopy()
+		current.Plan = api.Plan{api.NewAction(api.ActionTypeRotateMember, api.ServerGroupSingle, "s1")}
+
+		_, normalPlan := SimulatePlan(ctx, zerolog.Nop(), depl, spec, *current, inspector.NewEmptyInspector(), c)
+		require.Equal(t, current.Plan, normalPlan)
+	})
+}
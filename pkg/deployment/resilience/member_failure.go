@@ -47,6 +47,11 @@ const (
 // - They cannot be scheduled for a long time (TODO)
 func (r *Resilience) CheckMemberFailure(ctx context.Context) error {
 	status, lastVersion := r.context.GetStatus()
+	if r.context.GetSpec().IsSuspended() || status.Phase.IsSuspended() {
+		// Members of suspended deployment are shut down on purpose
+		return nil
+	}
+
 	updateStatusNeeded := false
 	if err := status.Members.ForeachServerGroup(func(group api.ServerGroup, list api.MemberStatusList) error {
 		for _, m := range list {
@@ -55,6 +60,11 @@ func (r *Resilience) CheckMemberFailure(ctx context.Context) error {
 				Str("role", group.AsRole()).
 				Logger()
 
+			if m.Conditions.IsTrue(api.ConditionTypeSuspended) {
+				// Member is shut down until the partially suspended deployment is resumed
+				continue
+			}
+
 			// Check if there are Members with Phase Upgrading or Rotation but no plan
 			switch m.Phase {
 			case api.MemberPhaseNone, api.MemberPhasePending:
@@ -0,0 +1,98 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package resilience
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	driver "github.com/arangodb/go-driver"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/arangodb/kube-arangodb/pkg/apis/deployment/v1"
+)
+
+type testContext struct {
+	spec   api.DeploymentSpec
+	status api.DeploymentStatus
+}
+
+func (c *testContext) GetSpec() api.DeploymentSpec {
+	return c.spec
+}
+
+func (c *testContext) GetStatus() (api.DeploymentStatus, int32) {
+	return *c.status.DeepCopy(), 0
+}
+
+func (c *testContext) UpdateStatus(ctx context.Context, status api.DeploymentStatus, lastVersion int32, force ...bool) error {
+	c.status = status
+	return nil
+}
+
+func (c *testContext) GetAgencyClients(ctx context.Context, predicate func(id string) bool) ([]driver.Connection, error) {
+	return nil, nil
+}
+
+func (c *testContext) GetDatabaseClient(ctx context.Context) (driver.Client, error) {
+	return nil, nil
+}
+
+func Test_CheckMemberFailure_ShuttingDownMember(t *testing.T) {
+	notReadySince := meta.NewTime(time.Now().Add(-2 * notReadySinceGracePeriod))
+
+	newContext := func() *testContext {
+		c := &testContext{
+			spec:   api.DeploymentSpec{Mode: api.NewMode(api.DeploymentModeCluster)},
+			status: api.DeploymentStatus{Phase: api.DeploymentPhaseRunning},
+		}
+		c.status.Members.Coordinators = api.MemberStatusList{
+			// Member is shut down by a scale down
+			{ID: "CRDN-1", Phase: api.MemberPhaseShuttingDown, CreatedAt: notReadySince},
+			{ID: "CRDN-2", Phase: api.MemberPhaseCreated, CreatedAt: notReadySince},
+		}
+		return c
+	}
+
+	t.Run("Failed member is detected", func(t *testing.T) {
+		c := newContext()
+
+		require.NoError(t, NewResilience(zerolog.Nop(), c).CheckMemberFailure(context.Background()))
+
+		m, _, ok := c.status.Members.ElementByID("CRDN-2")
+		require.True(t, ok)
+		require.Equal(t, api.MemberPhaseFailed, m.Phase)
+	})
+
+	t.Run("Suspended member is not failed", func(t *testing.T) {
+		c := newContext()
+		c.status.Members.Coordinators[1].Conditions.Update(api.ConditionTypeSuspended, true, "", "")
+
+		require.NoError(t, NewResilience(zerolog.Nop(), c).CheckMemberFailure(context.Background()))
+
+		m, _, ok := c.status.Members.ElementByID("CRDN-2")
+		require.True(t, ok)
+		require.Equal(t, api.MemberPhaseCreated, m.Phase)
+	})
+}
@@ -0,0 +1,94 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package resilience
+
+import (
+	"context"
+	"fmt"
+
+	api "github.com/arangodb/kube-arangodb/pkg/apis/deployment/v1"
+	"github.com/arangodb/kube-arangodb/pkg/util/errors"
+	inspectorInterface "github.com/arangodb/kube-arangodb/pkg/util/k8sutil/inspector"
+)
+
+// CheckNodeDrain performs a check for members which are scheduled on nodes which are going to be drained:
+// - The node is cordoned
+// - The node has one of the maintenance taints with NoSchedule or NoExecute effect
+// Such members get the NodeDrain condition, so they are relocated before the node is drained.
+func (r *Resilience) CheckNodeDrain(ctx context.Context, cachedStatus inspectorInterface.Inspector) error {
+	nodes, ok := cachedStatus.GetNodes()
+	if !ok {
+		// Nodes are not accessible in namespaced scope
+		return nil
+	}
+
+	spec := r.context.GetSpec()
+	status, lastVersion := r.context.GetStatus()
+	updateStatusNeeded := false
+	if err := status.Members.ForeachServerGroup(func(group api.ServerGroup, list api.MemberStatusList) error {
+		for _, m := range list {
+			if !spec.NodeDrain.IsEnabled() {
+				if m.Conditions.Remove(api.ConditionTypeNodeDrain) {
+					status.Members.Update(m, group)
+					updateStatusNeeded = true
+				}
+				continue
+			}
+
+			pod, ok := cachedStatus.Pod(m.PodName)
+			if !ok || pod.Spec.NodeName == "" {
+				// Keep the condition until the member is scheduled again
+				continue
+			}
+
+			node, ok := nodes.Node(pod.Spec.NodeName)
+			if !ok {
+				continue
+			}
+
+			if drained, reason := spec.NodeDrain.IsNodeDrained(node); drained {
+				if m.Conditions.Update(api.ConditionTypeNodeDrain, true, reason, fmt.Sprintf("Node %s is drained", node.GetName())) {
+					r.log.Info().
+						Str("id", m.ID).
+						Str("role", group.AsRole()).
+						Str("node", node.GetName()).
+						Msgf("Member is scheduled on drained node: %s", reason)
+					status.Members.Update(m, group)
+					updateStatusNeeded = true
+				}
+			} else if m.Conditions.Remove(api.ConditionTypeNodeDrain) {
+				status.Members.Update(m, group)
+				updateStatusNeeded = true
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return errors.WithStack(err)
+	}
+	if updateStatusNeeded {
+		if err := r.context.UpdateStatus(ctx, status, lastVersion); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,356 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package inspector
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	api "github.com/arangodb/kube-arangodb/pkg/apis/deployment/v1"
+	"github.com/arangodb/kube-arangodb/pkg/generated/clientset/versioned"
+	arangoInformer "github.com/arangodb/kube-arangodb/pkg/generated/informers/externalversions"
+	"github.com/arangodb/kube-arangodb/pkg/metrics"
+	"github.com/arangodb/kube-arangodb/pkg/util/errors"
+	"github.com/arangodb/kube-arangodb/pkg/util/globals"
+	inspectorInterface "github.com/arangodb/kube-arangodb/pkg/util/k8sutil/inspector"
+
+	monitoring "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	monitoringClient "github.com/prometheus-operator/prometheus-operator/pkg/client/versioned/typed/monitoring/v1"
+
+	core "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1beta1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	kubeInformer "k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	// informerResyncPeriod is the resync period of the shared informers.
+	informerResyncPeriod = 10 * time.Minute
+)
+
+// Informers keeps shared informers for the namespaces in which deployments are running.
+// Inspectors created by Informers are filled from the informer caches, so creating an
+// inspector does not send any request to the API server. A Refresh of such an inspector
+// lists the resources from the API server, so resources written before are visible.
+type Informers struct {
+	lock sync.Mutex
+
+	log  zerolog.Logger
+	stop <-chan struct{}
+
+	k kubernetes.Interface
+	m monitoringClient.MonitoringV1Interface
+	c versioned.Interface
+
+	nodes      *nodeInformer
+	namespaces map[string]*namespaceInformers
+}
+
+// NewInformers creates shared informers which run until the stop channel is closed.
+// Informers for a namespace are started with the first inspector for this namespace.
+func NewInformers(log zerolog.Logger, k kubernetes.Interface, m monitoringClient.MonitoringV1Interface, c versioned.Interface, stop <-chan struct{}) *Informers {
+	return &Informers{
+		log:        log,
+		stop:       stop,
+		k:          k,
+		m:          m,
+		c:          c,
+		namespaces: map[string]*namespaceInformers{},
+	}
+}
+
+// NewInspector returns the inspector for the given namespace filled from the informer caches.
+// It waits until the informers of the namespace are synced.
+func (f *Informers) NewInspector(ctx context.Context, namespace string) (inspectorInterface.Inspector, error) {
+	n, nodes, err := f.get(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	i := &inspector{
+		namespace: namespace,
+		k:         f.k,
+		m:         f.m,
+		c:         f.c,
+	}
+
+	i.fillFromInformers(ctx, n, nodes)
+
+	return i, nil
+}
+
+func (f *Informers) get(ctx context.Context, namespace string) (*namespaceInformers, *nodeInformer, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.nodes == nil {
+		nodes, err := newNodeInformer(ctx, f.k, f.stop)
+		if err != nil {
+			return nil, nil, err
+		}
+		f.nodes = nodes
+	}
+
+	n, ok := f.namespaces[namespace]
+	if !ok {
+		f.log.Debug().Str("namespace", namespace).Msg("Starting informers")
+		n = newNamespaceInformers(f.k, f.m, f.c, namespace, f.stop)
+		f.namespaces[namespace] = n
+	}
+
+	if err := n.waitForSync(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	if err := f.nodes.waitForSync(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	return n, f.nodes, nil
+}
+
+// namespaceInformers holds the informers of the resources inspected within one namespace.
+type namespaceInformers struct {
+	pods                 cache.SharedIndexInformer
+	secrets              cache.SharedIndexInformer
+	pvcs                 cache.SharedIndexInformer
+	services             cache.SharedIndexInformer
+	serviceAccounts      cache.SharedIndexInformer
+	podDisruptionBudgets cache.SharedIndexInformer
+	arangoMembers        cache.SharedIndexInformer
+	serviceMonitors      *serviceMonitorInformer
+}
+
+func newNamespaceInformers(k kubernetes.Interface, m monitoringClient.MonitoringV1Interface, c versioned.Interface, namespace string, stop <-chan struct{}) *namespaceInformers {
+	kubeFactory := kubeInformer.NewSharedInformerFactoryWithOptions(k, informerResyncPeriod,
+		kubeInformer.WithNamespace(namespace))
+	arangoFactory := arangoInformer.NewSharedInformerFactoryWithOptions(c, informerResyncPeriod,
+		arangoInformer.WithNamespace(namespace))
+
+	n := &namespaceInformers{
+		pods:                 kubeFactory.Core().V1().Pods().Informer(),
+		pvcs:                 kubeFactory.Core().V1().PersistentVolumeClaims().Informer(),
+		secrets:              kubeFactory.Core().V1().Secrets().Informer(),
+		services:             kubeFactory.Core().V1().Services().Informer(),
+		serviceAccounts:      kubeFactory.Core().V1().ServiceAccounts().Informer(),
+		podDisruptionBudgets: kubeFactory.Policy().V1beta1().PodDisruptionBudgets().Informer(),
+		arangoMembers:        arangoFactory.Database().V1().ArangoMembers().Informer(),
+		serviceMonitors: &serviceMonitorInformer{
+			m:         m,
+			namespace: namespace,
+			stop:      stop,
+		},
+	}
+
+	kubeFactory.Start(stop)
+	arangoFactory.Start(stop)
+
+	return n
+}
+
+func (n *namespaceInformers) waitForSync(ctx context.Context) error {
+	return waitForSync(ctx, n.pods.HasSynced, n.pvcs.HasSynced, n.secrets.HasSynced, n.services.HasSynced,
+		n.serviceAccounts.HasSynced, n.podDisruptionBudgets.HasSynced, n.arangoMembers.HasSynced)
+}
+
+func waitForSync(ctx context.Context, synced ...cache.InformerSynced) error {
+	ctxChild, cancel := globals.GetGlobalTimeouts().Reconciliation().WithTimeout(ctx)
+	defer cancel()
+
+	if !cache.WaitForCacheSync(ctxChild.Done(), synced...) {
+		return errors.Newf("Informers are not synced")
+	}
+
+	return nil
+}
+
+// serviceMonitorInformer starts the ServiceMonitor informer once the ServiceMonitor CRD is available.
+type serviceMonitorInformer struct {
+	lock sync.Mutex
+
+	m         monitoringClient.MonitoringV1Interface
+	namespace string
+	stop      <-chan struct{}
+
+	informer  cache.SharedIndexInformer
+	lastCheck time.Time
+}
+
+// get returns the informer or nil when the ServiceMonitor CRD is not available.
+// Availability of the CRD is checked at most once per resync period.
+func (s *serviceMonitorInformer) get(ctx context.Context) cache.SharedIndexInformer {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.informer != nil {
+		return s.informer
+	}
+
+	if time.Since(s.lastCheck) < informerResyncPeriod {
+		return nil
+	}
+	s.lastCheck = time.Now()
+
+	ctxChild, cancel := globals.GetGlobalTimeouts().Kubernetes().WithTimeout(ctx)
+	defer cancel()
+	if _, err := s.m.ServiceMonitors(s.namespace).List(ctxChild, meta.ListOptions{Limit: 1}); err != nil {
+		return nil
+	}
+
+	s.informer = cache.NewSharedIndexInformer(&cache.ListWatch{
+		ListFunc: func(options meta.ListOptions) (runtime.Object, error) {
+			return s.m.ServiceMonitors(s.namespace).List(context.Background(), options)
+		},
+		WatchFunc: func(options meta.ListOptions) (watch.Interface, error) {
+			return s.m.ServiceMonitors(s.namespace).Watch(context.Background(), options)
+		},
+	}, &monitoring.ServiceMonitor{}, informerResyncPeriod, cache.Indexers{})
+
+	go s.informer.Run(s.stop)
+
+	return s.informer
+}
+
+// nodeInformer holds the cluster wide node informer. Nodes are not inspected
+// when the operator is not authorized to list them.
+type nodeInformer struct {
+	authenticated bool
+
+	informer cache.SharedIndexInformer
+}
+
+func newNodeInformer(ctx context.Context, k kubernetes.Interface, stop <-chan struct{}) (*nodeInformer, error) {
+	ctxChild, cancel := globals.GetGlobalTimeouts().Kubernetes().WithTimeout(ctx)
+	defer cancel()
+	if _, err := k.CoreV1().Nodes().List(ctxChild, meta.ListOptions{Limit: 1}); err != nil {
+		if apiErrors.IsUnauthorized(err) {
+			return &nodeInformer{}, nil
+		}
+		return nil, err
+	}
+
+	factory := kubeInformer.NewSharedInformerFactory(k, informerResyncPeriod)
+	n := &nodeInformer{
+		authenticated: true,
+		informer:      factory.Core().V1().Nodes().Informer(),
+	}
+	factory.Start(stop)
+
+	return n, nil
+}
+
+func (n *nodeInformer) waitForSync(ctx context.Context) error {
+	if !n.authenticated {
+		return nil
+	}
+
+	return waitForSync(ctx, n.informer.HasSynced)
+}
+
+// fillFromInformers fills the inspector with copies of the cached objects,
+// so objects modified by the caller do not change the informer caches.
+func (i *inspector) fillFromInformers(ctx context.Context, n *namespaceInformers, nodesInf *nodeInformer) {
+	defer metrics.ObserveDuration(refreshDurationHistogram.WithLabelValues(i.namespace), time.Now())
+
+	i.pods = map[string]*core.Pod{}
+	for _, obj := range n.pods.GetStore().List() {
+		if pod, ok := obj.(*core.Pod); ok {
+			i.pods[pod.GetName()] = pod.DeepCopy()
+		}
+	}
+
+	i.secrets = map[string]*core.Secret{}
+	for _, obj := range n.secrets.GetStore().List() {
+		if secret, ok := obj.(*core.Secret); ok {
+			i.secrets[secret.GetName()] = secret.DeepCopy()
+		}
+	}
+
+	i.pvcs = map[string]*core.PersistentVolumeClaim{}
+	for _, obj := range n.pvcs.GetStore().List() {
+		if pvc, ok := obj.(*core.PersistentVolumeClaim); ok {
+			i.pvcs[pvc.GetName()] = pvc.DeepCopy()
+		}
+	}
+
+	i.services = map[string]*core.Service{}
+	for _, obj := range n.services.GetStore().List() {
+		if service, ok := obj.(*core.Service); ok {
+			i.services[service.GetName()] = service.DeepCopy()
+		}
+	}
+
+	i.serviceAccounts = map[string]*core.ServiceAccount{}
+	for _, obj := range n.serviceAccounts.GetStore().List() {
+		if serviceAccount, ok := obj.(*core.ServiceAccount); ok {
+			i.serviceAccounts[serviceAccount.GetName()] = serviceAccount.DeepCopy()
+		}
+	}
+
+	i.podDisruptionBudgets = map[string]*policy.PodDisruptionBudget{}
+	for _, obj := range n.podDisruptionBudgets.GetStore().List() {
+		if pdb, ok := obj.(*policy.PodDisruptionBudget); ok {
+			i.podDisruptionBudgets[pdb.GetName()] = pdb.DeepCopy()
+		}
+	}
+
+	i.arangoMembers = map[string]*api.ArangoMember{}
+	for _, obj := range n.arangoMembers.GetStore().List() {
+		if member, ok := obj.(*api.ArangoMember); ok {
+			i.arangoMembers[member.GetName()] = member.DeepCopy()
+		}
+	}
+
+	i.serviceMonitors = map[string]*monitoring.ServiceMonitor{}
+	if informer := n.serviceMonitors.get(ctx); informer != nil {
+		for _, obj := range informer.GetStore().List() {
+			if serviceMonitor, ok := obj.(*monitoring.ServiceMonitor); ok {
+				i.serviceMonitors[serviceMonitor.GetName()] = serviceMonitor.DeepCopy()
+			}
+		}
+	}
+
+	if !nodesInf.authenticated {
+		i.nodes = &nodeLoader{
+			authenticated: false,
+		}
+		return
+	}
+
+	nodes := map[string]*core.Node{}
+	for _, obj := range nodesInf.informer.GetStore().List() {
+		if node, ok := obj.(*core.Node); ok {
+			nodes[node.GetName()] = node.DeepCopy()
+		}
+	}
+	i.nodes = &nodeLoader{
+		authenticated: true,
+		nodes:         nodes,
+	}
+}
@@ -26,7 +26,9 @@ package inspector
 import (
 	"context"
 	"sync"
+	"time"
 
+	"github.com/arangodb/kube-arangodb/pkg/metrics"
 	"github.com/arangodb/kube-arangodb/pkg/util"
 
 	"github.com/arangodb/kube-arangodb/pkg/util/errors"
@@ -45,6 +47,10 @@ import (
 	"k8s.io/client-go/kubernetes"
 )
 
+var (
+	refreshDurationHistogram = metrics.MustRegisterHistogramVec("deployment_resources", "inspector_refresh_duration_seconds", "Duration of a single refresh of the cached resources (in sec)", nil, metrics.Namespace)
+)
+
 // SecretReadInterface has methods to work with Secret resources with ReadOnly mode.
 type SecretReadInterface interface {
 	Get(ctx context.Context, name string, opts meta.GetOptions) (*core.Secret, error)
@@ -153,6 +159,9 @@ func (i *inspector) IsStatic() bool {
 	return i.namespace == ""
 }
 
+// Refresh reloads all resources from the API server, also when the inspector was created
+// from the informer caches. Refresh is called after resources were modified, so the
+// changes have to be visible after it, which the informer caches do not guarantee.
 func (i *inspector) Refresh(ctx context.Context) error {
 	i.lock.Lock()
 	defer i.lock.Unlock()
@@ -161,6 +170,8 @@ func (i *inspector) Refresh(ctx context.Context) error {
 		return errors.New("Inspector created from static data")
 	}
 
+	defer metrics.ObserveDuration(refreshDurationHistogram.WithLabelValues(i.namespace), time.Now())
+
 	new, err := newInspector(ctx, i.k, i.m, i.c, i.namespace)
 	if err != nil {
 		return err
@@ -0,0 +1,245 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package inspector
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	api "github.com/arangodb/kube-arangodb/pkg/apis/deployment/v1"
+	arangofake "github.com/arangodb/kube-arangodb/pkg/generated/clientset/versioned/fake"
+	"github.com/arangodb/kube-arangodb/pkg/util/k8sutil"
+	inspectorInterface "github.com/arangodb/kube-arangodb/pkg/util/k8sutil/inspector"
+
+	monitoringFakeClient "github.com/prometheus-operator/prometheus-operator/pkg/client/versioned/fake"
+
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	kubetesting "k8s.io/client-go/testing"
+)
+
+const testNamespace = "test"
+
+// testClients are fake clients which count the requests sent to the API server.
+type testClients struct {
+	kube       *fake.Clientset
+	monitoring *monitoringFakeClient.Clientset
+	arango     *arangofake.Clientset
+
+	requests int64
+}
+
+func newTestClients(deployments int) *testClients {
+	var kubeObjects, arangoObjects []runtime.Object
+
+	for d := 0; d < deployments; d++ {
+		name := fmt.Sprintf("deployment-%d", d)
+		for m := 0; m < 9; m++ {
+			id := fmt.Sprintf("%s-member-%d", name, m)
+			labels := k8sutil.LabelsForMember(name, "dbserver", id)
+
+			kubeObjects = append(kubeObjects,
+				&core.Pod{ObjectMeta: meta.ObjectMeta{Name: id, Namespace: testNamespace, Labels: labels}},
+				&core.PersistentVolumeClaim{ObjectMeta: meta.ObjectMeta{Name: id, Namespace: testNamespace, Labels: labels}},
+				&core.Service{ObjectMeta: meta.ObjectMeta{Name: id, Namespace: testNamespace, Labels: labels}})
+			arangoObjects = append(arangoObjects,
+				&api.ArangoMember{ObjectMeta: meta.ObjectMeta{Name: id, Namespace: testNamespace}})
+		}
+		kubeObjects = append(kubeObjects,
+			&core.Secret{ObjectMeta: meta.ObjectMeta{Name: name + "-jwt", Namespace: testNamespace}})
+	}
+
+	c := &testClients{
+		kube:       fake.NewSimpleClientset(kubeObjects...),
+		monitoring: monitoringFakeClient.NewSimpleClientset(),
+		arango:     arangofake.NewSimpleClientset(arangoObjects...),
+	}
+
+	count := func(action kubetesting.Action) (bool, runtime.Object, error) {
+		atomic.AddInt64(&c.requests, 1)
+		return false, nil, nil
+	}
+	c.kube.PrependReactor("*", "*", count)
+	c.monitoring.PrependReactor("*", "*", count)
+	c.arango.PrependReactor("*", "*", count)
+
+	return c
+}
+
+func (c *testClients) Requests() int64 {
+	return atomic.LoadInt64(&c.requests)
+}
+
+func (c *testClients) NewInspector(t require.TestingT) inspectorInterface.Inspector {
+	i, err := NewInspector(context.Background(), c.kube, c.monitoring.MonitoringV1(), c.arango, testNamespace)
+	require.NoError(t, err)
+	return i
+}
+
+func (c *testClients) NewInformers(stop <-chan struct{}) *Informers {
+	return NewInformers(zerolog.Nop(), c.kube, c.monitoring.MonitoringV1(), c.arango, stop)
+}
+
+func Test_Informers_Inspector(t *testing.T) {
+	c := newTestClients(2)
+
+	_, err := c.kube.CoreV1().Pods(testNamespace).Create(context.Background(), &core.Pod{
+		ObjectMeta: meta.ObjectMeta{Name: "other", Namespace: testNamespace},
+	}, meta.CreateOptions{})
+	require.NoError(t, err)
+	_, err = c.kube.CoreV1().PersistentVolumeClaims(testNamespace).Create(context.Background(), &core.PersistentVolumeClaim{
+		ObjectMeta: meta.ObjectMeta{Name: "other", Namespace: testNamespace},
+	}, meta.CreateOptions{})
+	require.NoError(t, err)
+
+	expected := c.NewInspector(t)
+
+	stop := make(chan struct{})
+	defer close(stop)
+
+	informers := c.NewInformers(stop)
+	i, err := informers.NewInspector(context.Background(), testNamespace)
+	require.NoError(t, err)
+	require.False(t, i.IsStatic())
+
+	t.Run("Same objects as listed", func(t *testing.T) {
+		a, b := i.(*inspector), expected.(*inspector)
+		require.Equal(t, len(b.pods), len(a.pods))
+		require.Equal(t, len(b.pvcs), len(a.pvcs))
+		require.Equal(t, len(b.services), len(a.services))
+		require.Equal(t, len(b.secrets), len(a.secrets))
+		require.Equal(t, len(b.arangoMembers), len(a.arangoMembers))
+	})
+
+	t.Run("Objects without deployment label are cached", func(t *testing.T) {
+		_, ok := i.Pod("other")
+		require.True(t, ok)
+
+		_, ok = i.PersistentVolumeClaim("other")
+		require.True(t, ok)
+
+		_, ok = i.Pod("deployment-1-member-0")
+		require.True(t, ok)
+	})
+
+	t.Run("New inspector does not send requests", func(t *testing.T) {
+		requests := c.Requests()
+		_, err := informers.NewInspector(context.Background(), testNamespace)
+		require.NoError(t, err)
+		require.Equal(t, requests, c.Requests())
+	})
+
+	t.Run("Written objects are visible after refresh", func(t *testing.T) {
+		_, err := c.kube.CoreV1().Secrets(testNamespace).Create(context.Background(), &core.Secret{
+			ObjectMeta: meta.ObjectMeta{Name: "new", Namespace: testNamespace},
+		}, meta.CreateOptions{})
+		require.NoError(t, err)
+		_, err = c.kube.CoreV1().Pods(testNamespace).Create(context.Background(), &core.Pod{
+			ObjectMeta: meta.ObjectMeta{Name: "new", Namespace: testNamespace},
+		}, meta.CreateOptions{})
+		require.NoError(t, err)
+
+		require.NoError(t, i.Refresh(context.Background()))
+
+		_, ok := i.Secret("new")
+		require.True(t, ok)
+		_, ok = i.Pod("new")
+		require.True(t, ok)
+	})
+
+	t.Run("Written objects are visible in new inspectors", func(t *testing.T) {
+		_, err := c.kube.CoreV1().Secrets(testNamespace).Create(context.Background(), &core.Secret{
+			ObjectMeta: meta.ObjectMeta{Name: "next", Namespace: testNamespace},
+		}, meta.CreateOptions{})
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool {
+			n, err := informers.NewInspector(context.Background(), testNamespace)
+			require.NoError(t, err)
+			_, ok := n.Secret("next")
+			return ok
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("Cached objects are copies", func(t *testing.T) {
+		n, err := informers.NewInspector(context.Background(), testNamespace)
+		require.NoError(t, err)
+		pod, ok := n.Pod("deployment-0-member-0")
+		require.True(t, ok)
+		pod.Labels["changed"] = "true"
+
+		n, err = informers.NewInspector(context.Background(), testNamespace)
+		require.NoError(t, err)
+		pod, ok = n.Pod("deployment-0-member-0")
+		require.True(t, ok)
+		require.NotContains(t, pod.Labels, "changed")
+	})
+}
+
+// BenchmarkInspectorRefresh compares the inspector which lists all resources with the inspector
+// filled from the informer caches, as created for every inspection of a deployment.
+// The api-requests/op metric shows the number of requests sent to the API server by a single inspector.
+func BenchmarkInspectorRefresh(b *testing.B) {
+	for _, deployments := range []int{1, 10, 50} {
+		b.Run(fmt.Sprintf("List/%d", deployments), func(b *testing.B) {
+			c := newTestClients(deployments)
+			i := c.NewInspector(b)
+
+			start := c.Requests()
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				if err := i.Refresh(context.Background()); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(c.Requests()-start)/float64(b.N), "api-requests/op")
+		})
+
+		b.Run(fmt.Sprintf("Informers/%d", deployments), func(b *testing.B) {
+			c := newTestClients(deployments)
+
+			stop := make(chan struct{})
+			defer close(stop)
+
+			informers := c.NewInformers(stop)
+			if _, err := informers.NewInspector(context.Background(), testNamespace); err != nil {
+				b.Fatal(err)
+			}
+
+			start := c.Requests()
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				if _, err := informers.NewInspector(context.Background(), testNamespace); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(c.Requests()-start)/float64(b.N), "api-requests/op")
+		})
+	}
+}
@@ -291,6 +291,14 @@ func (r *Resources) InspectPods(ctx context.Context, cachedStatus inspectorInter
 		return 0, err
 	}
 
+	// Pods created recently may be missing in the informer caches,
+	// so the cache is reloaded before a pod is reported as gone
+	if !cachedStatus.IsStatic() && hasMembersWithGonePods(status, cachedStatus) {
+		if err := cachedStatus.Refresh(ctx); err != nil {
+			return 0, errors.WithStack(err)
+		}
+	}
+
 	// Go over all members, check for missing pods
 	status.Members.ForeachServerGroup(func(group api.ServerGroup, members api.MemberStatusList) error {
 		for _, m := range members {
@@ -405,3 +413,24 @@ func removeLabel(labels map[string]string, key string) map[string]string {
 
 	return labels
 }
+
+// hasMembersWithGonePods returns true when the pod of a member, which is expected to be running, does not exist.
+func hasMembersWithGonePods(status api.DeploymentStatus, cachedStatus inspectorInterface.Inspector) bool {
+	for _, m := range status.Members.AsList() {
+		if m.Member.PodName == "" {
+			continue
+		}
+
+		switch m.Member.Phase {
+		case api.MemberPhaseNone, api.MemberPhasePending, api.MemberPhaseShuttingDown, api.MemberPhaseUpgrading,
+			api.MemberPhaseFailed, api.MemberPhaseRotateStart, api.MemberPhaseRotating:
+			continue
+		}
+
+		if _, exists := cachedStatus.Pod(m.Member.PodName); !exists {
+			return true
+		}
+	}
+
+	return false
+}
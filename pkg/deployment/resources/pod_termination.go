@@ -58,6 +58,12 @@ func (r *Resources) prepareAgencyPodTermination(ctx context.Context, log zerolog
 		return nil
 	}
 
+	// Inspect deployment suspended state
+	if memberStatus.Phase == api.MemberPhaseShuttingDown && memberStatus.Conditions.IsTrue(api.ConditionTypeSuspended) {
+		log.Debug().Msg("Deployment is suspended, safe to remove agency serving finalizer")
+		return nil
+	}
+
 	// Check node the pod is scheduled on. Only if not in namespaced scope
 	agentDataWillBeGone := false
 	if nodes, ok := r.context.GetCachedStatus().GetNodes(); ok {
@@ -143,6 +149,12 @@ func (r *Resources) prepareDBServerPodTermination(ctx context.Context, log zerol
 		return nil
 	}
 
+	// Inspect deployment suspended state
+	if memberStatus.Phase == api.MemberPhaseShuttingDown && memberStatus.Conditions.IsTrue(api.ConditionTypeSuspended) {
+		log.Debug().Msg("Deployment is suspended, safe to remove dbserver pod")
+		return nil
+	}
+
 	// Check node the pod is scheduled on
 	dbserverDataWillBeGone := false
 	if nodes, ok := r.context.GetCachedStatus().GetNodes(); ok {
@@ -0,0 +1,285 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package deployment
+
+import (
+	"context"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	backupApi "github.com/arangodb/kube-arangodb/pkg/apis/backup/v1"
+	"github.com/arangodb/kube-arangodb/pkg/apis/deployment"
+	api "github.com/arangodb/kube-arangodb/pkg/apis/deployment/v1"
+	"github.com/arangodb/kube-arangodb/pkg/deployment/features"
+	"github.com/arangodb/kube-arangodb/pkg/deployment/patch"
+	backupLister "github.com/arangodb/kube-arangodb/pkg/generated/listers/backup/v1"
+	"github.com/arangodb/kube-arangodb/pkg/server"
+	"github.com/arangodb/kube-arangodb/pkg/util"
+	"github.com/arangodb/kube-arangodb/pkg/util/errors"
+	"github.com/arangodb/kube-arangodb/pkg/util/globals"
+	"github.com/arangodb/kube-arangodb/pkg/util/k8sutil"
+)
+
+// ScaleGroup sets the number of members of the given server group.
+func (d *Deployment) ScaleGroup(ctx context.Context, group api.ServerGroup, count int) error {
+	return d.updateSpecFromServer(ctx, func(spec *api.DeploymentSpec) {
+		gspec := spec.GetServerGroupSpec(group)
+		gspec.Count = util.NewInt(count)
+		spec.UpdateServerGroupSpec(group, gspec)
+	})
+}
+
+// SetMaintenance enables or disables the cluster maintenance mode.
+func (d *Deployment) SetMaintenance(ctx context.Context, enabled bool) error {
+	if !features.Maintenance().Enabled() {
+		return errors.WithStack(errors.Wrap(server.BadRequestError, "maintenance feature is not enabled"))
+	}
+	if !d.GetSpec().GetMode().HasAgents() {
+		return errors.WithStack(errors.Wrap(server.BadRequestError, "maintenance mode is not supported in single mode"))
+	}
+
+	return d.updateSpecFromServer(ctx, func(spec *api.DeploymentSpec) {
+		if spec.Database == nil {
+			spec.Database = &api.DatabaseSpec{}
+		}
+		spec.Database.Maintenance = util.NewBool(enabled)
+	})
+}
+
+// updateSpecFromServer applies the given modification on the latest version of the deployment spec
+// and stores it in the API server, if the result is valid.
+// The local copy of the deployment is not touched, since this runs outside of the deployment loop.
+// The change is picked up by the loop in the same way as a change made by the user.
+func (d *Deployment) updateSpecFromServer(ctx context.Context, modify func(spec *api.DeploymentSpec)) error {
+	c := d.deps.DatabaseCRCli.DatabaseV1().ArangoDeployments(d.GetNamespace())
+	for attempt := 1; ; attempt++ {
+		var current *api.ArangoDeployment
+		err := globals.GetGlobalTimeouts().Kubernetes().RunWithTimeout(ctx, func(ctxChild context.Context) error {
+			var err error
+			current, err = c.Get(ctxChild, d.GetName(), metav1.GetOptions{})
+			return err
+		})
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		newSpec := current.Spec.DeepCopy()
+		modify(newSpec)
+
+		// Validate will additionally check if
+		// 		min <= count <= max holds for the given server groups
+		if err := newSpec.Validate(); err != nil {
+			return errors.WithStack(errors.Wrap(server.BadRequestError, err.Error()))
+		}
+
+		if current.Spec.Equal(newSpec) {
+			// Nothing to update
+			return nil
+		}
+
+		update := current.DeepCopy()
+		update.Spec = *newSpec
+		err = globals.GetGlobalTimeouts().Kubernetes().RunWithTimeout(ctx, func(ctxChild context.Context) error {
+			_, err := c.Update(ctxChild, update, metav1.UpdateOptions{})
+			return err
+		})
+		if err == nil {
+			break
+		}
+		if attempt < 10 && k8sutil.IsConflict(err) {
+			// Deployment has been changed in the meantime (e.g. status update by the deployment loop)
+			continue
+		}
+		return errors.WithStack(err)
+	}
+
+	d.updateDeploymentTrigger.Trigger()
+	return nil
+}
+
+// RotateMember requests a rotation of the pod of the member with given ID.
+func (d *Deployment) RotateMember(ctx context.Context, id string) error {
+	return d.annotateMemberPod(ctx, id, deployment.ArangoDeploymentPodRotateAnnotation, nil)
+}
+
+// ReplaceMember requests a replacement of the member with given ID.
+func (d *Deployment) ReplaceMember(ctx context.Context, id string) error {
+	return d.annotateMemberPod(ctx, id, deployment.ArangoDeploymentPodReplaceAnnotation, func(group api.ServerGroup) error {
+		switch group {
+		case api.ServerGroupDBServers, api.ServerGroupAgents, api.ServerGroupCoordinators:
+			return nil
+		default:
+			return errors.WithStack(errors.Wrapf(server.BadRequestError, "members of group %s cannot be replaced", group.AsRole()))
+		}
+	})
+}
+
+// annotateMemberPod sets the given annotation on the pod of the member with given ID.
+func (d *Deployment) annotateMemberPod(ctx context.Context, id, annotation string, check func(group api.ServerGroup) error) error {
+	status, _ := d.GetStatus()
+	member, group, ok := status.Members.ElementByID(id)
+	if !ok {
+		return errors.WithStack(errors.Wrapf(server.NotFoundError, "member %s not found", id))
+	}
+
+	if check != nil {
+		if err := check(group); err != nil {
+			return err
+		}
+	}
+
+	if member.PodName == "" {
+		return errors.WithStack(errors.Wrapf(server.BadRequestError, "member %s has no pod", id))
+	}
+
+	ctxChild, cancel := globals.GetGlobalTimeouts().Kubernetes().WithTimeout(ctx)
+	defer cancel()
+	pod, err := d.deps.KubeCli.CoreV1().Pods(d.GetNamespace()).Get(ctxChild, member.PodName, metav1.GetOptions{})
+	if err != nil {
+		if k8sutil.IsNotFound(err) {
+			return errors.WithStack(errors.Wrapf(server.NotFoundError, "pod %s not found", member.PodName))
+		}
+		return errors.WithStack(err)
+	}
+
+	if _, ok := pod.GetAnnotations()[annotation]; ok {
+		// Already requested
+		return nil
+	}
+
+	var item patch.Item
+	if pod.GetAnnotations() == nil {
+		item = patch.ItemAdd(patch.NewPath("metadata", "annotations"), map[string]string{annotation: ""})
+	} else {
+		item = patch.ItemAdd(patch.NewPath("metadata", "annotations", annotation), "")
+	}
+
+	if err := d.ApplyPatchOnPod(ctx, pod, item); err != nil {
+		return errors.WithStack(err)
+	}
+
+	d.triggerInspection()
+	return nil
+}
+
+// Plans returns the current high priority and normal plan.
+func (d *Deployment) Plans() (api.Plan, api.Plan) {
+	status, _ := d.GetStatus()
+	return status.HighPriorityPlan, status.Plan
+}
+
+// Backups returns all ArangoBackups of the deployment.
+func (d *Deployment) Backups(ctx context.Context) ([]backupApi.ArangoBackup, error) {
+	var backups []*backupApi.ArangoBackup
+	if lister := d.getBackupLister(); lister != nil {
+		list, err := lister.ArangoBackups(d.GetNamespace()).List(labels.Everything())
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		backups = list
+	} else {
+		// Backup informers are not running in this operator instance
+		ctxChild, cancel := globals.GetGlobalTimeouts().Kubernetes().WithTimeout(ctx)
+		defer cancel()
+		list, err := d.deps.DatabaseCRCli.BackupV1().ArangoBackups(d.GetNamespace()).List(ctxChild, metav1.ListOptions{})
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		for i := range list.Items {
+			backups = append(backups, &list.Items[i])
+		}
+	}
+
+	var result []backupApi.ArangoBackup
+	for _, b := range backups {
+		if b.Spec.Deployment.Name == d.GetName() {
+			result = append(result, *b)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].GetName() < result[j].GetName()
+	})
+	return result, nil
+}
+
+// getBackupLister returns the lister of the ArangoBackup informer, or nil if it is not available.
+func (d *Deployment) getBackupLister() backupLister.ArangoBackupLister {
+	if d.deps.BackupLister == nil {
+		return nil
+	}
+	return d.deps.BackupLister()
+}
+
+// CreateBackup creates an ArangoBackup of the deployment.
+func (d *Deployment) CreateBackup(ctx context.Context, name string, spec backupApi.ArangoBackupSpec) (*backupApi.ArangoBackup, error) {
+	backup := &backupApi.ArangoBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: d.GetNamespace(),
+		},
+		Spec: spec,
+	}
+	backup.Spec.Deployment.Name = d.GetName()
+	if name != "" {
+		backup.Name = name
+	} else {
+		backup.GenerateName = d.GetName() + "-"
+	}
+
+	if err := backup.Spec.Validate(); err != nil {
+		return nil, errors.WithStack(errors.Wrap(server.BadRequestError, err.Error()))
+	}
+
+	ctxChild, cancel := globals.GetGlobalTimeouts().Kubernetes().WithTimeout(ctx)
+	defer cancel()
+	created, err := d.deps.DatabaseCRCli.BackupV1().ArangoBackups(d.GetNamespace()).Create(ctxChild, backup, metav1.CreateOptions{})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return created, nil
+}
+
+// DeleteBackup removes the ArangoBackup of the deployment with given name.
+func (d *Deployment) DeleteBackup(ctx context.Context, name string) error {
+	backups := d.deps.DatabaseCRCli.BackupV1().ArangoBackups(d.GetNamespace())
+
+	ctxChild, cancel := globals.GetGlobalTimeouts().Kubernetes().WithTimeout(ctx)
+	defer cancel()
+	backup, err := backups.Get(ctxChild, name, metav1.GetOptions{})
+	if err != nil {
+		if k8sutil.IsNotFound(err) {
+			return errors.WithStack(errors.Wrapf(server.NotFoundError, "backup %s not found", name))
+		}
+		return errors.WithStack(err)
+	}
+
+	if backup.Spec.Deployment.Name != d.GetName() {
+		return errors.WithStack(errors.Wrapf(server.NotFoundError, "backup %s not found", name))
+	}
+
+	ctxChild, cancel = globals.GetGlobalTimeouts().Kubernetes().WithTimeout(ctx)
+	defer cancel()
+	if err := backups.Delete(ctxChild, name, metav1.DeleteOptions{}); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package deployment
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	backupApi "github.com/arangodb/kube-arangodb/pkg/apis/backup/v1"
+	api "github.com/arangodb/kube-arangodb/pkg/apis/deployment/v1"
+	"github.com/arangodb/kube-arangodb/pkg/deployment/features"
+	arangofake "github.com/arangodb/kube-arangodb/pkg/generated/clientset/versioned/fake"
+	backupLister "github.com/arangodb/kube-arangodb/pkg/generated/listers/backup/v1"
+)
+
+func TestSetMaintenance_WithoutDatabaseSpec(t *testing.T) {
+	enabled := features.Maintenance().EnabledPointer()
+	previous := *enabled
+	*enabled = true
+	defer func() {
+		*enabled = previous
+	}()
+
+	d, _ := createTestDeployment(t, Config{}, &api.ArangoDeployment{
+		Spec: api.DeploymentSpec{Mode: api.NewMode(api.DeploymentModeCluster)},
+	})
+	require.Nil(t, d.apiObject.Spec.Database)
+	d.deps.DatabaseCRCli = arangofake.NewSimpleClientset(d.apiObject.DeepCopy())
+
+	require.NoError(t, d.SetMaintenance(context.Background(), true))
+
+	current, err := d.deps.DatabaseCRCli.DatabaseV1().ArangoDeployments(testNamespace).Get(context.Background(), testDeploymentName, metav1.GetOptions{})
+	require.NoError(t, err)
+	require.NotNil(t, current.Spec.Database)
+	assert.True(t, current.Spec.Database.GetMaintenance())
+}
+
+func TestBackups_FromLister(t *testing.T) {
+	d, _ := createTestDeployment(t, Config{}, &api.ArangoDeployment{})
+
+	newBackup := func(name, deployment string) *backupApi.ArangoBackup {
+		return &backupApi.ArangoBackup{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+			Spec:       backupApi.ArangoBackupSpec{Deployment: backupApi.ArangoBackupSpecDeployment{Name: deployment}},
+		}
+	}
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	require.NoError(t, indexer.Add(newBackup("b", testDeploymentName)))
+	require.NoError(t, indexer.Add(newBackup("a", testDeploymentName)))
+	require.NoError(t, indexer.Add(newBackup("other", "other")))
+	d.deps.BackupLister = func() backupLister.ArangoBackupLister {
+		return backupLister.NewArangoBackupLister(indexer)
+	}
+	// Backups must not be listed from the API server
+	d.deps.DatabaseCRCli = arangofake.NewSimpleClientset(newBackup("live", testDeploymentName))
+
+	backups, err := d.Backups(context.Background())
+	require.NoError(t, err)
+	require.Len(t, backups, 2)
+	assert.Equal(t, "a", backups[0].GetName())
+	assert.Equal(t, "b", backups[1].GetName())
+}
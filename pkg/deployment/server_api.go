@@ -24,7 +24,10 @@ package deployment
 
 import (
 	"context"
+	"net"
+	nhttp "net/http"
 	"sort"
+	"strconv"
 
 	"github.com/arangodb/kube-arangodb/pkg/util/errors"
 
@@ -252,3 +255,75 @@ func (d *Deployment) Members() map[api.ServerGroup][]server.Member {
 	})
 	return result
 }
+
+// MetricsTargets returns the metrics endpoints of all arangod members of the deployment.
+func (d *Deployment) MetricsTargets() ([]server.MetricsTarget, error) {
+	apiObject := d.apiObject
+
+	transport, err := d.getMetricsTransport()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var authorization string
+	if auth, err := d.getAuth(); err != nil {
+		return nil, errors.WithStack(err)
+	} else if auth != nil {
+		authorization = auth.Get("value")
+	}
+
+	scheme := "http"
+	if apiObject.Spec.TLS.IsSecure() {
+		scheme = "https"
+	}
+
+	path := k8sutil.ArangoExporterInternalEndpoint
+	if i := apiObject.Status.CurrentImage; i != nil && i.ArangoDBVersion.CompareTo("3.8.0") >= 0 {
+		path = k8sutil.ArangoExporterInternalEndpointV2
+	}
+
+	var result []server.MetricsTarget
+	status, _ := d.GetStatus()
+	status.Members.ForeachServerGroup(func(group api.ServerGroup, list api.MemberStatusList) error {
+		if !group.IsArangod() {
+			return nil
+		}
+		for _, m := range list {
+			host := m.GetEndpoint(k8sutil.CreatePodDNSName(apiObject, group.AsRole(), m.ID))
+			result = append(result, server.MetricsTarget{
+				Group:         group.AsRole(),
+				ID:            m.ID,
+				PodName:       m.PodName,
+				URL:           scheme + "://" + net.JoinHostPort(host, strconv.Itoa(k8sutil.ArangoPort)) + path,
+				Authorization: authorization,
+				Transport:     transport,
+			})
+		}
+		return nil
+	})
+	return result, nil
+}
+
+// getMetricsTransport returns the transport used to scrape the metrics of the members.
+// It is created once per deployment (and TLS setting), so scrapes reuse connections instead of leaking them.
+func (d *Deployment) getMetricsTransport() (nhttp.RoundTripper, error) {
+	d.metricsTransport.mutex.Lock()
+	defer d.metricsTransport.mutex.Unlock()
+
+	secure := d.apiObject.Spec.TLS.IsSecure()
+	if t := d.metricsTransport.transport; t != nil && d.metricsTransport.secure == secure {
+		return t, nil
+	}
+
+	config, err := d.getConnConfig()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if old, ok := d.metricsTransport.transport.(interface{ CloseIdleConnections() }); ok {
+		old.CloseIdleConnections()
+	}
+	d.metricsTransport.transport = config.Transport
+	d.metricsTransport.secure = secure
+	return config.Transport, nil
+}
@@ -0,0 +1,129 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package deployment
+
+import (
+	"context"
+
+	core "k8s.io/api/core/v1"
+
+	api "github.com/arangodb/kube-arangodb/pkg/apis/deployment/v1"
+	"github.com/arangodb/kube-arangodb/pkg/deployment/reconcile"
+	"github.com/arangodb/kube-arangodb/pkg/server"
+	"github.com/arangodb/kube-arangodb/pkg/util/k8sutil"
+)
+
+// Events returns a channel receiving the events of the deployment with an ID higher than the given one.
+// The channel is closed when the given context is done.
+func (d *Deployment) Events(ctx context.Context, lastEventID uint64) <-chan server.DeploymentEvent {
+	return d.events.Subscribe(ctx, lastEventID)
+}
+
+// ActionStarted publishes the start of the given plan action.
+func (d *Deployment) ActionStarted(action api.Action) {
+	d.events.Publish(newActionEvent(server.DeploymentEventActionStarted, action))
+}
+
+// ActionFinished publishes the result of the given plan action.
+func (d *Deployment) ActionFinished(action api.Action, result reconcile.ActionResult) {
+	ev := newActionEvent(server.DeploymentEventActionFinished, action)
+	ev.Result = string(result)
+	d.events.Publish(ev)
+}
+
+// publishKubernetesEvent publishes the given event recorded for the deployment.
+func (d *Deployment) publishKubernetesEvent(evt *k8sutil.Event) {
+	d.events.Publish(server.DeploymentEvent{
+		Type:      server.DeploymentEventKubernetes,
+		EventType: evt.Type,
+		Reason:    evt.Reason,
+		Message:   evt.Message,
+	})
+}
+
+// publishStatusEvents publishes the changes between the given statuses.
+func (d *Deployment) publishStatusEvents(before, after api.DeploymentStatus) {
+	for _, ev := range statusEvents(before, after) {
+		d.events.Publish(ev)
+	}
+}
+
+func newActionEvent(t server.DeploymentEventType, action api.Action) server.DeploymentEvent {
+	return server.DeploymentEvent{
+		Type:       t,
+		Group:      action.Group.AsRole(),
+		MemberID:   action.MemberID,
+		ActionID:   action.ID,
+		ActionType: action.Type.String(),
+		Reason:     action.Reason,
+	}
+}
+
+// statusEvents returns the events for the phase change of the deployment
+// and the member condition transitions between the given statuses.
+func statusEvents(before, after api.DeploymentStatus) []server.DeploymentEvent {
+	var events []server.DeploymentEvent
+
+	if before.Phase != after.Phase {
+		events = append(events, server.DeploymentEvent{
+			Type:  server.DeploymentEventPhase,
+			Phase: string(after.Phase),
+		})
+	}
+
+	after.Members.ForeachServerGroup(func(group api.ServerGroup, list api.MemberStatusList) error {
+		for _, m := range list {
+			var previous api.ConditionList
+			if old, _, ok := before.Members.ElementByID(m.ID); ok {
+				previous = old.Conditions
+			}
+			for _, c := range m.Conditions {
+				if old, ok := previous.Get(c.Type); ok && old.Status == c.Status {
+					continue
+				}
+				events = append(events, server.DeploymentEvent{
+					Type:      server.DeploymentEventMemberCondition,
+					Group:     group.AsRole(),
+					MemberID:  m.ID,
+					Condition: string(c.Type),
+					Status:    string(c.Status),
+					Reason:    c.Reason,
+					Message:   c.Message,
+				})
+			}
+			for _, old := range previous {
+				if _, ok := m.Conditions.Get(old.Type); !ok && old.IsTrue() {
+					// Removed conditions are no longer true
+					events = append(events, server.DeploymentEvent{
+						Type:      server.DeploymentEventMemberCondition,
+						Group:     group.AsRole(),
+						MemberID:  m.ID,
+						Condition: string(old.Type),
+						Status:    string(core.ConditionFalse),
+					})
+				}
+			}
+		}
+		return nil
+	})
+
+	return events
+}
@@ -0,0 +1,68 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package deployment
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	api "github.com/arangodb/kube-arangodb/pkg/apis/deployment/v1"
+	"github.com/arangodb/kube-arangodb/pkg/server"
+)
+
+func TestStatusEvents(t *testing.T) {
+	member := api.MemberStatus{ID: "PRMR-1"}
+	member.Conditions.Update(api.ConditionTypeReady, true, "", "")
+	member.Conditions.Update(api.ConditionTypeTerminating, true, "", "")
+
+	before := api.DeploymentStatus{
+		Phase: api.DeploymentPhaseNone,
+		Members: api.DeploymentStatusMembers{
+			DBServers: api.MemberStatusList{member},
+		},
+	}
+
+	assert.Empty(t, statusEvents(before, *before.DeepCopy()))
+
+	after := *before.DeepCopy()
+	after.Phase = api.DeploymentPhaseRunning
+	after.Members.DBServers[0].Conditions.Update(api.ConditionTypeReady, false, "Pod Not Ready", "")
+	after.Members.DBServers[0].Conditions.Remove(api.ConditionTypeTerminating)
+	after.Members.DBServers[0].Conditions.Update(api.ConditionTypeMemberOfCluster, true, "", "")
+
+	events := statusEvents(before, after)
+	require.Len(t, events, 4)
+	assert.Equal(t, server.DeploymentEvent{Type: server.DeploymentEventPhase, Phase: "Running"}, events[0])
+	assert.Equal(t, server.DeploymentEvent{
+		Type:      server.DeploymentEventMemberCondition,
+		Group:     "dbserver",
+		MemberID:  "PRMR-1",
+		Condition: string(api.ConditionTypeReady),
+		Status:    "False",
+		Reason:    "Pod Not Ready",
+	}, events[1])
+	assert.Equal(t, string(api.ConditionTypeMemberOfCluster), events[2].Condition)
+	assert.Equal(t, "True", events[2].Status)
+	assert.Equal(t, string(api.ConditionTypeTerminating), events[3].Condition)
+	assert.Equal(t, "False", events[3].Status)
+}
@@ -0,0 +1,213 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package deployment
+
+import (
+	"context"
+
+	"github.com/arangodb/go-driver"
+	"github.com/arangodb/go-driver/agency"
+	monitoringFakeClient "github.com/prometheus-operator/prometheus-operator/pkg/client/versioned/fake"
+	"github.com/rs/zerolog"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+
+	api "github.com/arangodb/kube-arangodb/pkg/apis/deployment/v1"
+	agencyCache "github.com/arangodb/kube-arangodb/pkg/deployment/agency"
+	"github.com/arangodb/kube-arangodb/pkg/deployment/reconcile"
+	"github.com/arangodb/kube-arangodb/pkg/deployment/resources"
+	"github.com/arangodb/kube-arangodb/pkg/deployment/resources/inspector"
+	arangofake "github.com/arangodb/kube-arangodb/pkg/generated/clientset/versioned/fake"
+	"github.com/arangodb/kube-arangodb/pkg/server"
+	"github.com/arangodb/kube-arangodb/pkg/util/arangod/conn"
+	"github.com/arangodb/kube-arangodb/pkg/util/errors"
+)
+
+// SimulationInput holds the state of a deployment for which plans are simulated.
+type SimulationInput struct {
+	// Deployment is the current ArangoDeployment including its status.
+	Deployment *api.ArangoDeployment
+	// Spec is the proposed spec. If nil, the spec of the deployment is used.
+	Spec *api.DeploymentSpec
+	// Config is the configuration of the operator used to render pods.
+	Config Config
+
+	Members                []api.ArangoMember
+	Pods                   []core.Pod
+	PersistentVolumeClaims []core.PersistentVolumeClaim
+	Secrets                []core.Secret
+	Services               []core.Service
+
+	// AgencyState is the agency state served to the plan builders. If nil, an empty state is used.
+	AgencyState *agencyCache.State
+	// ShardsInSync is the shard synchronization state served to the plan builders.
+	ShardsInSync bool
+}
+
+// SimulationResult holds the simulated plans.
+type SimulationResult struct {
+	// Spec is the proposed spec with defaults applied, as the operator would accept it.
+	Spec api.DeploymentSpec `json:"spec"`
+	// ResetFields lists the immutable fields which were reset to the current values.
+	ResetFields []string `json:"resetFields,omitempty"`
+	// HighPriorityPlan is the high priority plan.
+	HighPriorityPlan api.Plan `json:"highPriorityPlan,omitempty"`
+	// Plan is the normal plan.
+	Plan api.Plan `json:"plan,omitempty"`
+}
+
+// SimulatePlan returns the plans the operator would create for the given input.
+// Kubernetes objects are served from the input by fake clients, so nothing is written to the cluster.
+// Connections to ArangoDB servers are not available, plan builders which need them report an error
+// and are skipped.
+func SimulatePlan(ctx context.Context, log zerolog.Logger, input SimulationInput) (SimulationResult, error) {
+	if input.Deployment == nil {
+		return SimulationResult{}, errors.Newf("deployment is required")
+	}
+
+	current := input.Deployment.DeepCopy()
+	apiObject := current.DeepCopy()
+
+	var result SimulationResult
+	if input.Spec != nil {
+		// Accept the proposed spec like the operator does on update
+		specBefore := current.Spec
+		if current.Status.AcceptedSpec != nil {
+			specBefore = *current.Status.AcceptedSpec.DeepCopy()
+		}
+		apiObject.Spec = *input.Spec.DeepCopy()
+		apiObject.Spec.SetDefaultsFrom(specBefore)
+		apiObject.Spec.SetDefaults(apiObject.GetName())
+		result.ResetFields = specBefore.ResetImmutableFields(&apiObject.Spec)
+		if len(result.ResetFields) > 0 {
+			apiObject.Spec.SetDefaults(apiObject.GetName())
+		}
+	} else {
+		apiObject.Spec.SetDefaults(apiObject.GetName())
+	}
+	if err := apiObject.Spec.Validate(); err != nil {
+		return SimulationResult{}, errors.Wrap(err, "spec validation failed")
+	}
+	result.Spec = apiObject.Spec
+
+	var kubeObjects []runtime.Object
+	for i := range input.Pods {
+		kubeObjects = append(kubeObjects, &input.Pods[i])
+	}
+	for i := range input.PersistentVolumeClaims {
+		kubeObjects = append(kubeObjects, &input.PersistentVolumeClaims[i])
+	}
+	for i := range input.Secrets {
+		kubeObjects = append(kubeObjects, &input.Secrets[i])
+	}
+	for i := range input.Services {
+		kubeObjects = append(kubeObjects, &input.Services[i])
+	}
+
+	arangoObjects := []runtime.Object{current}
+	for i := range input.Members {
+		arangoObjects = append(arangoObjects, &input.Members[i])
+	}
+
+	deps := Dependencies{
+		Log:               log,
+		KubeCli:           fake.NewSimpleClientset(kubeObjects...),
+		KubeMonitoringCli: monitoringFakeClient.NewSimpleClientset().MonitoringV1(),
+		DatabaseCRCli:     arangofake.NewSimpleClientset(arangoObjects...),
+		EventRecorder:     &record.FakeRecorder{},
+	}
+
+	state := agencyCache.State{}
+	if input.AgencyState != nil {
+		state = *input.AgencyState
+	}
+
+	d := &Deployment{
+		apiObject:   apiObject,
+		name:        apiObject.GetName(),
+		namespace:   apiObject.GetNamespace(),
+		config:      input.Config,
+		deps:        deps,
+		eventCh:     make(chan *deploymentEvent, deploymentEventQueueSize),
+		stopCh:      make(chan struct{}),
+		agencyCache: agencyCache.NewStaticCache(state),
+		events:      server.NewEventStream(server.DefaultEventStreamHistory),
+	}
+	d.clientCache = offlineClientCache{auth: conn.NewFactory(d.getAuth, d.getConnConfig).GetAuth()}
+	d.status.last = *(current.Status.DeepCopy())
+	d.status.published = *(current.Status.DeepCopy())
+	if d.status.last.AcceptedSpec == nil {
+		d.status.last.AcceptedSpec = apiObject.Spec.DeepCopy()
+	}
+
+	cachedStatus, err := inspector.NewInspector(ctx, d.getKubeCli(), d.getMonitoringV1Cli(), d.getArangoCli(), d.GetNamespace())
+	if err != nil {
+		return SimulationResult{}, errors.WithStack(err)
+	}
+	d.SetCachedStatus(cachedStatus)
+	d.resources = resources.NewResources(log, d)
+
+	status, _ := d.GetStatus()
+	result.HighPriorityPlan, result.Plan = reconcile.SimulatePlan(ctx, log, apiObject, apiObject.Spec, status, cachedStatus,
+		simulationContext{Deployment: d, shardsInSync: input.ShardsInSync})
+
+	return result, nil
+}
+
+// simulationContext overrides the parts of the deployment which depend on the state of the ArangoDB servers.
+type simulationContext struct {
+	*Deployment
+
+	shardsInSync bool
+}
+
+func (s simulationContext) GetShardSyncStatus() bool {
+	return s.shardsInSync
+}
+
+func (s simulationContext) InvalidateSyncStatus() {}
+
+// offlineClientCache is a client cache which never connects to ArangoDB servers.
+type offlineClientCache struct {
+	auth conn.Auth
+}
+
+func (o offlineClientCache) GetAuth() conn.Auth {
+	return o.auth
+}
+
+func (o offlineClientCache) Connection(_ context.Context, host string) (driver.Connection, error) {
+	return nil, errors.Newf("connection to %s is not available in simulation", host)
+}
+
+func (o offlineClientCache) Get(_ context.Context, group api.ServerGroup, id string) (driver.Client, error) {
+	return nil, errors.Newf("connection to %s %s is not available in simulation", group.AsRole(), id)
+}
+
+func (o offlineClientCache) GetDatabase(_ context.Context) (driver.Client, error) {
+	return nil, errors.Newf("connection to database is not available in simulation")
+}
+
+func (o offlineClientCache) GetAgency(_ context.Context) (agency.Agency, error) {
+	return nil, errors.Newf("connection to agency is not available in simulation")
+}
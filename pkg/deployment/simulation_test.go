@@ -0,0 +1,110 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package deployment
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/arangodb/kube-arangodb/pkg/apis/deployment/v1"
+	"github.com/arangodb/kube-arangodb/pkg/util"
+)
+
+func TestSimulatePlan(t *testing.T) {
+	newDeployment := func() *api.ArangoDeployment {
+		d := &api.ArangoDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      testDeploymentName,
+				Namespace: testNamespace,
+			},
+			Spec: api.DeploymentSpec{
+				Mode:          api.NewMode(api.DeploymentModeCluster),
+				Image:         util.NewString(testImage),
+				StorageEngine: api.NewStorageEngine(api.StorageEngineRocksDB),
+				Authentication: api.AuthenticationSpec{
+					JWTSecretName: util.NewString(api.JWTSecretNameDisabled),
+				},
+				TLS: api.TLSSpec{
+					CASecretName: util.NewString(api.CASecretNameDisabled),
+				},
+			},
+		}
+		d.Spec.SetDefaults(d.GetName())
+
+		for _, m := range []struct {
+			group api.ServerGroup
+			id    string
+		}{
+			{api.ServerGroupAgents, "a1"}, {api.ServerGroupAgents, "a2"}, {api.ServerGroupAgents, "a3"},
+			{api.ServerGroupDBServers, "d1"}, {api.ServerGroupDBServers, "d2"}, {api.ServerGroupDBServers, "d3"},
+			{api.ServerGroupCoordinators, "c1"}, {api.ServerGroupCoordinators, "c2"}, {api.ServerGroupCoordinators, "c3"},
+		} {
+			require.NoError(t, d.Status.Members.Add(api.MemberStatus{ID: m.id, Phase: api.MemberPhaseCreated}, m.group))
+		}
+
+		return d
+	}
+
+	t.Run("Scale up", func(t *testing.T) {
+		d := newDeployment()
+		spec := d.Spec.DeepCopy()
+		spec.DBServers.Count = util.NewInt(4)
+
+		result, err := SimulatePlan(context.Background(), zerolog.Nop(), SimulationInput{Deployment: d, Spec: spec})
+		require.NoError(t, err)
+		require.Empty(t, result.ResetFields)
+
+		found := false
+		for _, a := range result.Plan {
+			if a.Type == api.ActionTypeAddMember && a.Group == api.ServerGroupDBServers {
+				found = true
+			}
+		}
+		require.True(t, found, "AddMember action for DBServers expected in %v", result.Plan)
+
+		// The input must not be modified
+		require.Equal(t, 3, d.Spec.DBServers.GetCount())
+	})
+
+	t.Run("Immutable field", func(t *testing.T) {
+		d := newDeployment()
+		spec := d.Spec.DeepCopy()
+		spec.StorageEngine = api.NewStorageEngine(api.StorageEngineMMFiles)
+
+		result, err := SimulatePlan(context.Background(), zerolog.Nop(), SimulationInput{Deployment: d, Spec: spec})
+		require.NoError(t, err)
+		require.Contains(t, result.ResetFields, "storageEngine")
+		require.Equal(t, api.StorageEngineRocksDB, result.Spec.GetStorageEngine())
+	})
+
+	t.Run("Invalid spec", func(t *testing.T) {
+		d := newDeployment()
+		spec := d.Spec.DeepCopy()
+		spec.DBServers.Count = util.NewInt(-1)
+
+		_, err := SimulatePlan(context.Background(), zerolog.Nop(), SimulationInput{Deployment: d, Spec: spec})
+		require.Error(t, err)
+	})
+}
@@ -26,28 +26,30 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"io/ioutil"
 	"net/url"
 	"os"
 	"path"
-	"strings"
-	"sync/atomic"
+	"sync"
 	"time"
 
 	"github.com/arangodb/go-driver"
 	"github.com/arangodb/kube-arangodb/pkg/util/k8sutil"
+	"github.com/arangodb/kube-arangodb/pkg/util/metrics"
+	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/rs/zerolog/log"
 )
 
 const (
-	monitorMetricTemplate  = "arangodb_member_health{role=\"%s\",id=\"%s\"} %d \n"
 	successRefreshInterval = time.Second * 120
 	failRefreshInterval    = time.Second * 15
+
+	// staleTimeout defines after which time without successful refresh member metrics are no longer exposed
+	staleTimeout = successRefreshInterval + 4*failRefreshInterval
 )
 
-var currentMembersStatus atomic.Value
+var _ prometheus.Collector = &monitor{}
 
 func NewMonitor(arangodbEndpoint string, auth Authentication, sslVerify bool, timeout time.Duration) *monitor {
 	uri, err := setPath(arangodbEndpoint, k8sutil.ArangoExporterClusterHealthEndpoint)
@@ -59,34 +61,81 @@ func NewMonitor(arangodbEndpoint string, auth Authentication, sslVerify bool, ti
 	return &monitor{
 		factory:   newHttpClientFactory(arangodbEndpoint, auth, sslVerify, timeout),
 		healthURI: uri,
+
+		memberHealthMetric:      metrics.NewDescription("arangodb_member_health", "Reachability of the member (1 if reachable)", []string{"role", "id"}, nil),
+		memberHealthTimeMetric:  metrics.NewDescription("arangodb_member_health_last_success_timestamp_seconds", "Unix time the member was reachable the last time", []string{"role", "id"}, nil),
+		memberStatusMetric:      metrics.NewDescription("arangodb_member_status", "Health status of the member reported by the agency supervision", []string{"role", "id", "status"}, nil),
+		memberSyncStatusMetric:  metrics.NewDescription("arangodb_member_sync_status", "Sync status of the member reported by the agency supervision", []string{"role", "id", "status"}, nil),
+		memberLeaderMetric:      metrics.NewDescription("arangodb_member_leader", "Leadership of the agent (1 if leading)", []string{"role", "id"}, nil),
+		clusterHealthTimeMetric: metrics.NewDescription("arangodb_exporter_cluster_health_last_success_timestamp_seconds", "Unix time of the last successful cluster health fetch", nil, nil),
 	}
 }
 
+// memberState holds the last known state of a single member.
+type memberState struct {
+	health       driver.ServerHealth
+	reachable    bool
+	lastHealthy  time.Time
+	healthFailed bool
+}
+
 type monitor struct {
 	factory   httpClientFactory
 	healthURI *url.URL
+
+	lock        sync.Mutex
+	members     map[driver.ServerID]memberState
+	lastSuccess time.Time
+
+	memberHealthMetric, memberHealthTimeMetric, memberStatusMetric, memberSyncStatusMetric, memberLeaderMetric, clusterHealthTimeMetric metrics.Description
+}
+
+func (m *monitor) Describe(descs chan<- *prometheus.Desc) {
+	metrics.NewPushDescription(descs).Push(m.memberHealthMetric, m.memberHealthTimeMetric, m.memberStatusMetric, m.memberSyncStatusMetric, m.memberLeaderMetric, m.clusterHealthTimeMetric)
+}
+
+func (m *monitor) Collect(out chan<- prometheus.Metric) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.lastSuccess.IsZero() {
+		return
+	}
+
+	p := metrics.NewPushMetric(out)
+	p.Push(m.clusterHealthTimeMetric.Gauge(float64(m.lastSuccess.Unix())))
+
+	if time.Since(m.lastSuccess) > staleTimeout {
+		// Do not expose outdated member state
+		return
+	}
+
+	for id, member := range m.members {
+		role := string(member.health.Role)
+
+		p.Push(m.memberHealthMetric.Gauge(boolToFloat(member.reachable), role, string(id)))
+		if !member.lastHealthy.IsZero() {
+			p.Push(m.memberHealthTimeMetric.Gauge(float64(member.lastHealthy.Unix()), role, string(id)))
+		}
+		if member.health.Status != "" {
+			p.Push(m.memberStatusMetric.Gauge(1, role, string(id), string(member.health.Status)))
+		}
+		if member.health.SyncStatus != "" {
+			p.Push(m.memberSyncStatusMetric.Gauge(1, role, string(id), string(member.health.SyncStatus)))
+		}
+		if member.health.Leading != nil {
+			p.Push(m.memberLeaderMetric.Gauge(boolToFloat(*member.health.Leading), role, string(id)))
+		}
+	}
 }
 
-// UpdateMonitorStatus load monitor metrics for current cluster into currentMembersStatus
-func (m monitor) UpdateMonitorStatus(ctx context.Context) {
+// UpdateMonitorStatus refreshes the state of all members of the current cluster on a regular basis
+func (m *monitor) UpdateMonitorStatus(ctx context.Context) {
 	for {
 		sleep := successRefreshInterval
 
-		health, err := m.GetClusterHealth()
-		if err != nil {
-			log.Error().Err(err).Msg("GetClusterHealth error")
+		if err := m.refresh(); err != nil {
 			sleep = failRefreshInterval
-		} else {
-			var output strings.Builder
-			for key, value := range health.Health {
-				entry, err := m.GetMemberStatus(key, value)
-				if err != nil {
-					log.Error().Err(err).Msg("GetMemberStatus error")
-					sleep = failRefreshInterval
-				}
-				output.WriteString(entry)
-			}
-			currentMembersStatus.Store(output.String())
 		}
 
 		select {
@@ -98,8 +147,50 @@ func (m monitor) UpdateMonitorStatus(ctx context.Context) {
 	}
 }
 
+// refresh loads the cluster health and checks the reachability of every member
+func (m *monitor) refresh() error {
+	health, err := m.GetClusterHealth()
+	if err != nil {
+		log.Error().Err(err).Msg("GetClusterHealth error")
+		return err
+	}
+
+	m.lock.Lock()
+	previous := m.members
+	m.lock.Unlock()
+
+	var lastErr error
+	now := time.Now()
+	members := make(map[driver.ServerID]memberState, len(health.Health))
+	for id, value := range health.Health {
+		state := memberState{
+			health: value,
+		}
+		if p, ok := previous[id]; ok {
+			state.lastHealthy = p.lastHealthy
+		}
+
+		if err := m.GetMemberStatus(id, value); err != nil {
+			log.Error().Err(err).Msg("GetMemberStatus error")
+			lastErr = err
+		} else {
+			state.reachable = true
+			state.lastHealthy = now
+		}
+		members[id] = state
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.members = members
+	m.lastSuccess = now
+
+	return lastErr
+}
+
 // GetClusterHealth returns current ArangoDeployment cluster health status
-func (m monitor) GetClusterHealth() (*driver.ClusterHealth, error) {
+func (m *monitor) GetClusterHealth() (*driver.ClusterHealth, error) {
 	c, req, err := m.factory()
 	if err != nil {
 		return nil, err
@@ -124,34 +215,32 @@ func (m monitor) GetClusterHealth() (*driver.ClusterHealth, error) {
 	return &result, err
 }
 
-// GetMemberStatus returns Prometheus monitor metric for specific member
-func (m monitor) GetMemberStatus(id driver.ServerID, member driver.ServerHealth) (string, error) {
-	result := fmt.Sprintf(monitorMetricTemplate, member.Role, id, 0)
-
+// GetMemberStatus checks if the specific member is reachable
+func (m *monitor) GetMemberStatus(id driver.ServerID, member driver.ServerHealth) error {
 	c, req, err := m.factory()
 	if err != nil {
-		return result, err
+		return err
 	}
 
 	req.URL, err = setPath(member.Endpoint, k8sutil.ArangoExporterStatusEndpoint)
 	if err != nil {
-		return result, err
+		return err
 	}
 
 	resp, err := c.Do(req)
 	if err != nil {
-		return result, err
+		return err
 	}
 
+	defer resp.Body.Close()
 	if resp.StatusCode != 200 {
-		defer resp.Body.Close()
 		body, err := ioutil.ReadAll(resp.Body)
 		if err != nil {
-			return result, err
+			return err
 		}
-		return result, errors.New(string(body))
+		return errors.New(string(body))
 	}
-	return fmt.Sprintf(monitorMetricTemplate, member.Role, id, 1), nil
+	return nil
 }
 
 func setPath(uri, uriPath string) (*url.URL, error) {
@@ -163,3 +252,10 @@ func setPath(uri, uriPath string) (*url.URL, error) {
 	u.Scheme = "https"
 	return u, nil
 }
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -30,13 +30,19 @@ import (
 	"time"
 
 	"github.com/arangodb/kube-arangodb/pkg/util/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/rs/zerolog/log"
 )
 
 var _ http.Handler = &passthru{}
 
-func NewPassthru(arangodbEndpoint string, auth Authentication, sslVerify bool, timeout time.Duration) (http.Handler, error) {
+// NewPassthru returns handler which merges arangod metrics with metrics gathered by the given gatherer
+func NewPassthru(arangodbEndpoint string, auth Authentication, sslVerify bool, timeout time.Duration, gatherer prometheus.Gatherer) (http.Handler, error) {
 	return &passthru{
-		factory: newHttpClientFactory(arangodbEndpoint, auth, sslVerify, timeout),
+		factory:  newHttpClientFactory(arangodbEndpoint, auth, sslVerify, timeout),
+		gatherer: gatherer,
 	}, nil
 }
 
@@ -80,7 +86,8 @@ func newHttpClientFactory(arangodbEndpoint string, auth Authentication, sslVerif
 }
 
 type passthru struct {
-	factory httpClientFactory
+	factory  httpClientFactory
+	gatherer prometheus.Gatherer
 }
 
 func (p passthru) get() (*http.Response, error) {
@@ -118,23 +125,99 @@ func (p passthru) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	responseStr := string(response)
+	if data.StatusCode != http.StatusOK {
+		// Pass errors from arangod as they are
+		resp.WriteHeader(data.StatusCode)
+		resp.Write(response)
+		return
+	}
 
 	// Fix Header response
-	responseStr = strings.ReplaceAll(responseStr, "guage", "gauge")
+	responseStr := strings.ReplaceAll(string(response), "guage", "gauge")
 
-	// Attach monitor data
-	monitorData := currentMembersStatus.Load()
-	if monitorData != nil {
-		responseStr = responseStr + monitorData.(string)
+	arangodFamilies, err := parseMetrics(responseStr)
+	if err != nil {
+		// Do not drop the whole scrape because of a single unexpected line, pass arangod metrics through as they are
+		log.Warn().Err(err).Msg("Unable to parse arangod metrics, passing them through without merging")
+		p.writeUnparsed(resp, responseStr)
+		return
 	}
 
-	resp.WriteHeader(data.StatusCode)
-	_, err = resp.Write([]byte(responseStr))
+	gatherers := prometheus.Gatherers{
+		prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+			return arangodFamilies, nil
+		}),
+	}
+	if p.gatherer != nil {
+		gatherers = append(gatherers, p.gatherer)
+	}
+
+	families, err := gatherers.Gather()
 	if err != nil {
 		// Ignore error
 		resp.WriteHeader(http.StatusInternalServerError)
-		resp.Write([]byte("Unable to write body"))
+		resp.Write([]byte(err.Error()))
+		return
+	}
+
+	format := expfmt.NegotiateIncludingOpenMetrics(req.Header)
+	resp.Header().Set("Content-Type", string(format))
+	resp.WriteHeader(http.StatusOK)
+
+	enc := expfmt.NewEncoder(resp, format)
+	for _, family := range families {
+		if err := enc.Encode(family); err != nil {
+			// Headers are already sent
+			return
+		}
+	}
+
+	if closer, ok := enc.(expfmt.Closer); ok {
+		closer.Close()
+	}
+}
+
+// writeUnparsed writes arangod metrics as they are, followed by the metrics of the gatherer in the text format
+func (p passthru) writeUnparsed(resp http.ResponseWriter, responseStr string) {
+	resp.Header().Set("Content-Type", string(expfmt.FmtText))
+	resp.WriteHeader(http.StatusOK)
+	resp.Write([]byte(responseStr))
+
+	if p.gatherer == nil {
 		return
 	}
+
+	families, err := p.gatherer.Gather()
+	if err != nil {
+		// Headers are already sent
+		log.Warn().Err(err).Msg("Unable to gather exporter metrics")
+		return
+	}
+
+	if responseStr != "" && !strings.HasSuffix(responseStr, "\n") {
+		resp.Write([]byte("\n"))
+	}
+
+	enc := expfmt.NewEncoder(resp, expfmt.FmtText)
+	for _, family := range families {
+		if err := enc.Encode(family); err != nil {
+			// Headers are already sent
+			return
+		}
+	}
+}
+
+// parseMetrics parses metrics in the prometheus text format
+func parseMetrics(data string) ([]*dto.MetricFamily, error) {
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(strings.NewReader(data))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	result := make([]*dto.MetricFamily, 0, len(families))
+	for _, family := range families {
+		result = append(result, family)
+	}
+	return result, nil
 }
@@ -0,0 +1,182 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package exporter
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arangodb/go-driver"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testArangodMetrics = `# HELP arangodb_client_connection_statistics_total Total number of connections
+# TYPE arangodb_client_connection_statistics_total counter
+arangodb_client_connection_statistics_total 12
+# HELP arangodb_server_statistics_cpu_cores Number of CPU cores
+# TYPE arangodb_server_statistics_cpu_cores guage
+arangodb_server_statistics_cpu_cores 4
+`
+
+func testPassthru(t *testing.T, status int, body string) (*httptest.Server, *monitor) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "bearer token", r.Header.Get("Authorization"))
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	auth := func() (string, error) {
+		return "token", nil
+	}
+
+	mon := NewMonitor(server.URL, auth, false, time.Second)
+	registry := prometheus.NewRegistry()
+	require.NoError(t, registry.Register(mon))
+
+	h, err := NewPassthru(server.URL, auth, false, time.Second, registry)
+	require.NoError(t, err)
+
+	s := httptest.NewServer(h)
+	t.Cleanup(s.Close)
+
+	return s, mon
+}
+
+func TestPassthru_Merge(t *testing.T) {
+	s, mon := testPassthru(t, http.StatusOK, testArangodMetrics)
+
+	leading := true
+	mon.members = map[driver.ServerID]memberState{
+		"AGNT-1": {
+			health:      driver.ServerHealth{Role: driver.ServerRoleAgent, Status: driver.ServerStatusGood, Leading: &leading},
+			reachable:   true,
+			lastHealthy: time.Now(),
+		},
+		"PRMR-1": {
+			health: driver.ServerHealth{Role: driver.ServerRoleDBServer, Status: driver.ServerStatusFailed, SyncStatus: driver.ServerSyncStatusUnknown},
+		},
+	}
+	mon.lastSuccess = time.Now()
+
+	resp, err := http.Get(s.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(resp.Body)
+	require.NoError(t, err)
+
+	require.Contains(t, families, "arangodb_server_statistics_cpu_cores")
+	assert.Equal(t, "GAUGE", families["arangodb_server_statistics_cpu_cores"].GetType().String())
+	require.Contains(t, families, "arangodb_client_connection_statistics_total")
+
+	require.Contains(t, families, "arangodb_member_health")
+	assert.Len(t, families["arangodb_member_health"].GetMetric(), 2)
+	require.Contains(t, families, "arangodb_member_leader")
+	assert.Len(t, families["arangodb_member_leader"].GetMetric(), 1)
+	require.Contains(t, families, "arangodb_member_status")
+	require.Contains(t, families, "arangodb_member_sync_status")
+	assert.Len(t, families["arangodb_member_health_last_success_timestamp_seconds"].GetMetric(), 1)
+	require.Contains(t, families, "arangodb_exporter_cluster_health_last_success_timestamp_seconds")
+}
+
+func TestPassthru_Stale(t *testing.T) {
+	s, mon := testPassthru(t, http.StatusOK, testArangodMetrics)
+
+	mon.members = map[driver.ServerID]memberState{
+		"PRMR-1": {
+			health:    driver.ServerHealth{Role: driver.ServerRoleDBServer},
+			reachable: true,
+		},
+	}
+	mon.lastSuccess = time.Now().Add(-2 * staleTimeout)
+
+	resp, err := http.Get(s.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(resp.Body)
+	require.NoError(t, err)
+
+	assert.NotContains(t, families, "arangodb_member_health")
+	assert.Contains(t, families, "arangodb_exporter_cluster_health_last_success_timestamp_seconds")
+}
+
+func TestPassthru_OpenMetrics(t *testing.T) {
+	s, _ := testPassthru(t, http.StatusOK, testArangodMetrics)
+
+	req, err := http.NewRequest(http.MethodGet, s.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "application/openmetrics-text; version=0.0.1")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.True(t, strings.HasPrefix(resp.Header.Get("Content-Type"), "application/openmetrics-text"))
+}
+
+func TestPassthru_Errors(t *testing.T) {
+	t.Run("Upstream error", func(t *testing.T) {
+		s, _ := testPassthru(t, http.StatusUnauthorized, "unauthorized")
+
+		resp, err := http.Get(s.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("Invalid metrics", func(t *testing.T) {
+		body := testArangodMetrics + "invalid metric line with spaces"
+		s, mon := testPassthru(t, http.StatusOK, body)
+
+		mon.members = map[driver.ServerID]memberState{
+			"PRMR-1": {
+				health:    driver.ServerHealth{Role: driver.ServerRoleDBServer, Status: driver.ServerStatusGood},
+				reachable: true,
+			},
+		}
+		mon.lastSuccess = time.Now()
+
+		resp, err := http.Get(s.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		data, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		// arangod metrics are passed through as they are, followed by the exporter metrics
+		assert.True(t, strings.HasPrefix(string(data), strings.ReplaceAll(body, "guage", "gauge")+"\n"))
+		assert.Regexp(t, `\narangodb_member_health\{id="PRMR-1",role="[^"]+"\} 1\n`, string(data))
+		assert.Contains(t, string(data), "# TYPE arangodb_exporter_cluster_health_last_success_timestamp_seconds gauge\n")
+	})
+}
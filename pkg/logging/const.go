@@ -32,6 +32,7 @@ const (
 	LoggerNameProvisioner           = "provisioner"
 	LoggerNameReconciliation        = "reconciliation"
 	LoggerNameEventRecorder         = "event-recorder"
+	LoggerNameAudit                 = "audit"
 )
 
 func LoggerNames() []string {
@@ -45,5 +46,6 @@ func LoggerNames() []string {
 		LoggerNameProvisioner,
 		LoggerNameReconciliation,
 		LoggerNameEventRecorder,
+		LoggerNameAudit,
 	}
 }
@@ -33,10 +33,16 @@ const (
 
 	// DeploymentName is a label key used for the name of a deployment
 	DeploymentName = "deployment"
+	// DeploymentReplicationName is a label key used for the name of a deployment replication
+	DeploymentReplicationName = "replication"
 	// ActionName is a label key used for the name of an action
 	ActionName = "action"
 	// ActionPriority is a label key used for the priority of an action
 	ActionPriority = "priority"
+	// ServerGroup is a label key used for the server group of a member
+	ServerGroup = "group"
+	// Namespace is a label key used for the namespace of a resource
+	Namespace = "namespace"
 	// Result is a label key used for the result of an action (Success|Failed)
 	Result = "result"
 	// Success is a label value used for successful actions
@@ -114,6 +120,33 @@ func MustRegisterSummary(component, name, help string, objectives map[float64]fl
 	return m
 }
 
+// MustRegisterHistogramVec creates and registers a histogram vector.
+// If buckets are not provided prometheus.DefBuckets are used.
+// Must be called from `init`.
+func MustRegisterHistogramVec(component, name, help string, buckets []float64, labelNames ...string) *prometheus.HistogramVec {
+	if buckets == nil {
+		buckets = prometheus.DefBuckets
+	}
+	m := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: component,
+		Name:      name,
+		Help:      help,
+		Buckets:   buckets,
+	}, labelNames)
+	prometheus.MustRegister(m)
+	return m
+}
+
+// DurationBuckets are histogram buckets (in seconds) for long running operations, from 1 second to 2 hours.
+var DurationBuckets = []float64{1, 5, 15, 30, 60, 120, 300, 600, 1200, 1800, 3600, 7200}
+
+// ObserveDuration observes the duration since the given start time
+// in seconds.
+func ObserveDuration(o prometheus.Observer, startTime time.Time) {
+	o.Observe(time.Since(startTime).Seconds())
+}
+
 // SetDuration sets a gauge value for the duration since the given start time
 // in seconds.
 func SetDuration(g prometheus.Gauge, startTime time.Time) {
@@ -50,6 +50,8 @@ import (
 	"github.com/arangodb/kube-arangodb/pkg/backup/handlers/arango/policy"
 	backupOper "github.com/arangodb/kube-arangodb/pkg/backup/operator"
 	"github.com/arangodb/kube-arangodb/pkg/deployment"
+	"github.com/arangodb/kube-arangodb/pkg/deployment/queue"
+	"github.com/arangodb/kube-arangodb/pkg/deployment/resources/inspector"
 	"github.com/arangodb/kube-arangodb/pkg/generated/clientset/versioned"
 	"github.com/arangodb/kube-arangodb/pkg/logging"
 	"github.com/arangodb/kube-arangodb/pkg/replication"
@@ -59,6 +61,7 @@ import (
 
 	arangoClientSet "github.com/arangodb/kube-arangodb/pkg/generated/clientset/versioned"
 	arangoInformer "github.com/arangodb/kube-arangodb/pkg/generated/informers/externalversions"
+	backupLister "github.com/arangodb/kube-arangodb/pkg/generated/listers/backup/v1"
 )
 
 const (
@@ -80,6 +83,10 @@ type Operator struct {
 	deployments            map[string]*deployment.Deployment
 	deploymentReplications map[string]*replication.DeploymentReplication
 	localStorages          map[string]*storage.LocalStorage
+	informers              *inspector.Informers
+	queue                  queue.Queue
+	backupLister           backupLister.ArangoBackupLister
+	backupPolicyLister     backupLister.ArangoBackupPolicyLister
 }
 
 type Config struct {
@@ -95,6 +102,7 @@ type Config struct {
 	EnableBackup                bool
 	AllowChaos                  bool
 	ScalingIntegrationEnabled   bool
+	DeploymentWorkers           int
 	SingleMode                  bool
 	Scope                       scope.Scope
 }
@@ -251,6 +259,12 @@ func (o *Operator) onStartBackup(stop <-chan struct{}) {
 		panic(err)
 	}
 
+	// Expose the informer caches to the server
+	o.Dependencies.LivenessProbe.Lock()
+	o.backupLister = arangoInformer.Backup().V1().ArangoBackups().Lister()
+	o.backupPolicyLister = arangoInformer.Backup().V1().ArangoBackupPolicies().Lister()
+	o.Dependencies.LivenessProbe.Unlock()
+
 	prometheus.MustRegister(operator)
 
 	operator.Start(8, stop)
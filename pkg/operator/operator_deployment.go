@@ -32,6 +32,8 @@ import (
 
 	api "github.com/arangodb/kube-arangodb/pkg/apis/deployment/v1"
 	"github.com/arangodb/kube-arangodb/pkg/deployment"
+	"github.com/arangodb/kube-arangodb/pkg/deployment/queue"
+	"github.com/arangodb/kube-arangodb/pkg/deployment/resources/inspector"
 	"github.com/arangodb/kube-arangodb/pkg/metrics"
 	"github.com/arangodb/kube-arangodb/pkg/util/k8sutil"
 )
@@ -59,6 +61,11 @@ func (o *Operator) runDeployments(stop <-chan struct{}) {
 			DeleteFunc: o.onDeleteArangoDeployment,
 		})
 
+	o.informers = inspector.NewInformers(o.log, o.Dependencies.KubeCli, o.Dependencies.KubeMonitoringCli, o.Dependencies.CRCli, stop)
+	if o.Config.DeploymentWorkers > 0 {
+		o.queue = queue.NewQueue(o.Config.DeploymentWorkers, stop)
+	}
+
 	o.Dependencies.DeploymentProbe.SetReady()
 	rw.Run(stop)
 }
@@ -219,6 +226,9 @@ func (o *Operator) makeDeploymentConfigAndDeps(apiObject *api.ArangoDeployment)
 		KubeExtCli:        o.Dependencies.KubeExtCli,
 		DatabaseCRCli:     o.Dependencies.CRCli,
 		EventRecorder:     o.Dependencies.EventRecorder,
+		Informers:         o.informers,
+		Queue:             o.queue,
+		BackupLister:      o.getBackupLister,
 	}
 	return cfg, deps
 }
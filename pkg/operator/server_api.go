@@ -25,8 +25,13 @@ package operator
 import (
 	"sort"
 
+	"k8s.io/apimachinery/pkg/labels"
+
 	"github.com/arangodb/kube-arangodb/pkg/util/errors"
+	"github.com/arangodb/kube-arangodb/pkg/util/k8sutil"
 
+	backupApi "github.com/arangodb/kube-arangodb/pkg/apis/backup/v1"
+	backupLister "github.com/arangodb/kube-arangodb/pkg/generated/listers/backup/v1"
 	"github.com/arangodb/kube-arangodb/pkg/server"
 )
 
@@ -128,3 +133,95 @@ func (o *Operator) GetLocalStorage(name string) (server.LocalStorage, error) {
 	}
 	return nil, errors.WithStack(server.NotFoundError)
 }
+
+// BackupOperator provides the backup operator (if any)
+func (o *Operator) BackupOperator() server.BackupOperator {
+	if !o.Config.EnableBackup {
+		return nil
+	}
+	return o
+}
+
+// getBackupListers returns the listers of the backup informers, available once the backup operator is started.
+func (o *Operator) getBackupListers() (backupLister.ArangoBackupLister, backupLister.ArangoBackupPolicyLister, error) {
+	o.Dependencies.LivenessProbe.Lock()
+	defer o.Dependencies.LivenessProbe.Unlock()
+
+	if o.backupLister == nil || o.backupPolicyLister == nil {
+		return nil, nil, errors.WithStack(errors.Wrap(server.NotFoundError, "backup operator is not started"))
+	}
+	return o.backupLister, o.backupPolicyLister, nil
+}
+
+// getBackupLister returns the lister of the ArangoBackup informer, or nil if the backup operator is not started.
+func (o *Operator) getBackupLister() backupLister.ArangoBackupLister {
+	backups, _, err := o.getBackupListers()
+	if err != nil {
+		return nil
+	}
+	return backups
+}
+
+// GetBackups returns all ArangoBackups managed by the operator
+func (o *Operator) GetBackups() ([]*backupApi.ArangoBackup, error) {
+	backups, _, err := o.getBackupListers()
+	if err != nil {
+		return nil, err
+	}
+	result, err := backups.ArangoBackups(o.Namespace).List(labels.Everything())
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].GetName() < result[j].GetName()
+	})
+	return result, nil
+}
+
+// GetBackup returns the ArangoBackup, managed by the operator, with given name
+func (o *Operator) GetBackup(name string) (*backupApi.ArangoBackup, error) {
+	backups, _, err := o.getBackupListers()
+	if err != nil {
+		return nil, err
+	}
+	b, err := backups.ArangoBackups(o.Namespace).Get(name)
+	if err != nil {
+		if k8sutil.IsNotFound(err) {
+			return nil, errors.WithStack(server.NotFoundError)
+		}
+		return nil, errors.WithStack(err)
+	}
+	return b, nil
+}
+
+// GetBackupPolicies returns all ArangoBackupPolicies managed by the operator
+func (o *Operator) GetBackupPolicies() ([]*backupApi.ArangoBackupPolicy, error) {
+	_, policies, err := o.getBackupListers()
+	if err != nil {
+		return nil, err
+	}
+	result, err := policies.ArangoBackupPolicies(o.Namespace).List(labels.Everything())
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].GetName() < result[j].GetName()
+	})
+	return result, nil
+}
+
+// GetBackupPolicy returns the ArangoBackupPolicy, managed by the operator, with given name
+func (o *Operator) GetBackupPolicy(name string) (*backupApi.ArangoBackupPolicy, error) {
+	_, policies, err := o.getBackupListers()
+	if err != nil {
+		return nil, err
+	}
+	p, err := policies.ArangoBackupPolicies(o.Namespace).Get(name)
+	if err != nil {
+		if k8sutil.IsNotFound(err) {
+			return nil, errors.WithStack(server.NotFoundError)
+		}
+		return nil, errors.WithStack(err)
+	}
+	return p, nil
+}
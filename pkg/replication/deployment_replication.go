@@ -89,6 +89,7 @@ type DeploymentReplication struct {
 	inspectTrigger         trigger.Trigger
 	recentInspectionErrors int
 	clientCache            client.ClientCache
+	metrics                replicationMetrics
 }
 
 // New creates a new DeploymentReplication from the given API object.
@@ -105,6 +106,8 @@ func New(config Config, deps Dependencies, apiObject *api.ArangoDeploymentReplic
 		stopCh:    make(chan struct{}),
 	}
 
+	localInventory.Add(dr)
+
 	go dr.run()
 
 	return dr, nil
@@ -125,6 +128,8 @@ func (dr *DeploymentReplication) Delete() {
 	dr.deps.Log.Info().Msg("deployment replication is deleted by user")
 	if atomic.CompareAndSwapInt32(&dr.stopped, 0, 1) {
 		close(dr.stopCh)
+		localInventory.Remove(dr)
+		cancelFailuresCounters.DeleteLabelValues(dr.apiObject.GetNamespace(), dr.apiObject.GetName())
 	}
 }
 
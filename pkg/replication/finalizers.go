@@ -157,6 +157,7 @@ func (dr *DeploymentReplication) inspectFinalizerDeplReplStopSync(ctx context.Co
 		if err != nil && !client.IsPreconditionFailed(err) {
 			log.Warn().Err(err).Bool("abort", abort).Msg("Failed to stop synchronization")
 			dr.status.CancelFailures++
+			cancelFailuresCounters.WithLabelValues(p.GetNamespace(), p.GetName()).Inc()
 			if err := dr.updateCRStatus(); err != nil {
 				log.Warn().Err(err).Msg("Failed to update status to reflect cancel-failures increment")
 			}
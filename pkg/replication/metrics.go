@@ -0,0 +1,226 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+//
+
+package replication
+
+import (
+	"sync"
+	"time"
+
+	"github.com/arangodb/arangosync-client/client"
+	"github.com/prometheus/client_golang/prometheus"
+
+	api "github.com/arangodb/kube-arangodb/pkg/apis/replication/v1"
+	operatorMetrics "github.com/arangodb/kube-arangodb/pkg/metrics"
+	"github.com/arangodb/kube-arangodb/pkg/util/metrics"
+)
+
+const (
+	// Component name for metrics of this package
+	metricsComponent = "deployment_replication"
+
+	endpointSource      = "source"
+	endpointDestination = "destination"
+)
+
+var (
+	cancelFailuresCounters = operatorMetrics.MustRegisterCounterVec(metricsComponent, "cancel_failures", "Number of failed attempts to cancel synchronization", operatorMetrics.Namespace, operatorMetrics.DeploymentReplicationName)
+)
+
+func init() {
+	localInventory = inventory{
+		replications:                 map[string]map[string]*DeploymentReplication{},
+		replicationsMetric:           metrics.NewDescription("arangodb_operator_deployment_replications", "Number of active deployment replications", []string{"namespace", "replication"}, nil),
+		replicationShardsMetric:      metrics.NewDescription("arangodb_operator_deployment_replication_shards", "Number of shards per synchronization status", []string{"namespace", "replication", "endpoint", "status"}, nil),
+		replicationDatabasesMetric:   metrics.NewDescription("arangodb_operator_deployment_replication_databases_out_of_sync", "Number of databases with at least one shard not in running state", []string{"namespace", "replication", "endpoint"}, nil),
+		replicationCollectionsMetric: metrics.NewDescription("arangodb_operator_deployment_replication_collections_out_of_sync", "Number of collections with at least one shard not in running state", []string{"namespace", "replication", "endpoint"}, nil),
+		replicationDelayMetric:       metrics.NewDescription("arangodb_operator_deployment_replication_delay_seconds", "Highest synchronization delay of all shards as reported by the syncmaster", []string{"namespace", "replication", "endpoint"}, nil),
+		replicationInspectionMetric:  metrics.NewDescription("arangodb_operator_deployment_replication_last_inspection_timestamp", "Unix time of the last inspection without errors", []string{"namespace", "replication"}, nil),
+	}
+
+	prometheus.MustRegister(&localInventory)
+}
+
+var localInventory inventory
+
+var _ prometheus.Collector = &inventory{}
+
+type inventory struct {
+	lock         sync.Mutex
+	replications map[string]map[string]*DeploymentReplication
+
+	replicationsMetric, replicationShardsMetric, replicationDatabasesMetric, replicationCollectionsMetric, replicationDelayMetric, replicationInspectionMetric metrics.Description
+}
+
+func (i *inventory) Describe(descs chan<- *prometheus.Desc) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	metrics.NewPushDescription(descs).Push(i.replicationsMetric, i.replicationShardsMetric, i.replicationDatabasesMetric, i.replicationCollectionsMetric, i.replicationDelayMetric, i.replicationInspectionMetric)
+}
+
+func (i *inventory) Collect(m chan<- prometheus.Metric) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	p := metrics.NewPushMetric(m)
+	for namespace, replications := range i.replications {
+		for name, replication := range replications {
+			p.Push(i.replicationsMetric.Gauge(1, namespace, name))
+
+			state := replication.metrics.get()
+
+			if !state.lastInspection.IsZero() {
+				p.Push(i.replicationInspectionMetric.Gauge(float64(state.lastInspection.Unix()), namespace, name))
+			}
+
+			for _, endpoint := range []endpointMetrics{state.source, state.destination} {
+				if endpoint.name == "" {
+					// Not inspected yet
+					continue
+				}
+				for status, count := range endpoint.shards {
+					p.Push(i.replicationShardsMetric.Gauge(float64(count), namespace, name, endpoint.name, status))
+				}
+				p.Push(i.replicationDatabasesMetric.Gauge(float64(endpoint.databasesOutOfSync), namespace, name, endpoint.name))
+				p.Push(i.replicationCollectionsMetric.Gauge(float64(endpoint.collectionsOutOfSync), namespace, name, endpoint.name))
+				if endpoint.hasDelay {
+					p.Push(i.replicationDelayMetric.Gauge(endpoint.delay.Seconds(), namespace, name, endpoint.name))
+				}
+			}
+		}
+	}
+}
+
+func (i *inventory) Add(dr *DeploymentReplication) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	name, namespace := dr.apiObject.GetName(), dr.apiObject.GetNamespace()
+
+	if _, ok := i.replications[namespace]; !ok {
+		i.replications[namespace] = map[string]*DeploymentReplication{}
+	}
+
+	i.replications[namespace][name] = dr
+}
+
+func (i *inventory) Remove(dr *DeploymentReplication) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	name, namespace := dr.apiObject.GetName(), dr.apiObject.GetNamespace()
+
+	if replications, ok := i.replications[namespace]; ok {
+		if replications[name] == dr {
+			delete(replications, name)
+		}
+		if len(replications) == 0 {
+			delete(i.replications, namespace)
+		}
+	}
+}
+
+// endpointMetrics holds the metrics of the source or destination endpoint.
+type endpointMetrics struct {
+	name                 string
+	shards               map[string]int
+	databasesOutOfSync   int
+	collectionsOutOfSync int
+	delay                time.Duration
+	hasDelay             bool
+}
+
+// newEndpointMetrics creates endpoint metrics from the given endpoint status
+// and the shard details reported by the syncmaster.
+func newEndpointMetrics(name string, status api.EndpointStatus, shards []client.ShardSyncInfo) endpointMetrics {
+	result := endpointMetrics{
+		name:   name,
+		shards: map[string]int{},
+	}
+	for _, db := range status.Databases {
+		dbInSync := true
+		for _, col := range db.Collections {
+			colInSync := true
+			for _, s := range col.Shards {
+				result.shards[s.Status]++
+				if s.Status != string(client.SyncStatusRunning) {
+					colInSync = false
+				}
+			}
+			if !colInSync {
+				result.collectionsOutOfSync++
+				dbInSync = false
+			}
+		}
+		if !dbInSync {
+			result.databasesOutOfSync++
+		}
+	}
+	for _, s := range shards {
+		if s.Delay > result.delay {
+			result.delay = s.Delay
+		}
+		result.hasDelay = true
+	}
+	return result
+}
+
+// replicationMetricsState is a snapshot of the metrics of a deployment replication.
+type replicationMetricsState struct {
+	lastInspection      time.Time
+	source, destination endpointMetrics
+}
+
+// replicationMetrics holds the metrics of a deployment replication.
+// It is updated by the inspection loop and read by the metrics collector.
+type replicationMetrics struct {
+	lock  sync.Mutex
+	state replicationMetricsState
+}
+
+func (m *replicationMetrics) get() replicationMetricsState {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return m.state
+}
+
+func (m *replicationMetrics) setSource(e endpointMetrics) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.state.source = e
+}
+
+func (m *replicationMetrics) setDestination(e endpointMetrics) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.state.destination = e
+}
+
+func (m *replicationMetrics) setLastInspection(t time.Time) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.state.lastInspection = t
+}
@@ -0,0 +1,77 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+//
+
+package replication
+
+import (
+	"testing"
+	"time"
+
+	"github.com/arangodb/arangosync-client/client"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/arangodb/kube-arangodb/pkg/apis/replication/v1"
+)
+
+func TestNewEndpointMetrics(t *testing.T) {
+	shards := []client.ShardSyncInfo{
+		{Database: "db1", Collection: "a", ShardIndex: 0, Status: client.SyncStatusRunning, Delay: time.Second},
+		{Database: "db1", Collection: "a", ShardIndex: 1, Status: client.SyncStatusInitialSync, Delay: 5 * time.Second},
+		{Database: "db1", Collection: "b", ShardIndex: 0, Status: client.SyncStatusRunning},
+		{Database: "db2", Collection: "c", ShardIndex: 0, Status: client.SyncStatusRunning},
+	}
+
+	m := newEndpointMetrics(endpointDestination, createEndpointStatusFromShards(shards), shards)
+
+	assert.Equal(t, endpointDestination, m.name)
+	assert.Equal(t, map[string]int{"running": 3, "initial-sync": 1}, m.shards)
+	assert.Equal(t, 1, m.databasesOutOfSync)
+	assert.Equal(t, 1, m.collectionsOutOfSync)
+	assert.True(t, m.hasDelay)
+	assert.Equal(t, 5*time.Second, m.delay)
+
+	empty := newEndpointMetrics(endpointSource, createEndpointStatusFromShards(nil), nil)
+	assert.False(t, empty.hasDelay)
+	assert.Empty(t, empty.shards)
+}
+
+func TestDeleteRemovesCancelFailures(t *testing.T) {
+	dr := &DeploymentReplication{
+		apiObject: &api.ArangoDeploymentReplication{
+			ObjectMeta: metav1.ObjectMeta{Name: "replication", Namespace: "test"},
+		},
+		deps:   Dependencies{Log: zerolog.Nop()},
+		stopCh: make(chan struct{}),
+	}
+	localInventory.Add(dr)
+
+	cancelFailuresCounters.WithLabelValues("test", "replication").Inc()
+	cancelFailuresCounters.WithLabelValues("other", "replication").Inc()
+	before := testutil.CollectAndCount(cancelFailuresCounters)
+
+	dr.Delete()
+
+	assert.Equal(t, before-1, testutil.CollectAndCount(cancelFailuresCounters))
+	assert.NotContains(t, localInventory.replications, "test")
+}
@@ -0,0 +1,246 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+//
+
+package replication
+
+import (
+	"context"
+	"time"
+
+	"github.com/arangodb/kube-arangodb/pkg/util/errors"
+
+	"github.com/arangodb/arangosync-client/client"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/arangodb/kube-arangodb/pkg/apis/replication/v1"
+	"github.com/arangodb/kube-arangodb/pkg/util/k8sutil"
+)
+
+const (
+	actionInspectionInterval = time.Second * 10 // Interval used to inspect the deployment replication while an action is running
+)
+
+// inspectAction runs the next step of the action (switchover/failover) requested in `spec.action`.
+// Returns true when an action is in progress, in which case the regular inspection must be skipped,
+// since synchronization is stopped on purpose.
+func (dr *DeploymentReplication) inspectAction(ctx context.Context) (bool, error) {
+	action := dr.apiObject.Spec.GetAction()
+
+	if dr.status.Action == nil {
+		if action == api.DeploymentReplicationActionNone || dr.apiObject.Spec.IsPaused() {
+			// Actions are not started while synchronization is paused
+			return false, nil
+		}
+		return dr.startAction(action)
+	}
+
+	if action != dr.status.Action.Type {
+		switch dr.status.Phase {
+		case api.DeploymentReplicationPhaseWaitingForSync, api.DeploymentReplicationPhaseStoppingSync:
+			// Synchronization is not stopped yet, so a switchover can still be withdrawn or turned into a failover.
+			// Once canceling has started, removing the action has no effect.
+			if action == api.DeploymentReplicationActionNone {
+				if dr.status.Phase == api.DeploymentReplicationPhaseWaitingForSync {
+					return dr.abortAction()
+				}
+			} else {
+				dr.deps.Log.Info().Str("action", string(action)).Msg("Requested action changed")
+				dr.status.Action.Type = action
+				if action.IsForced() {
+					dr.status.Phase = api.DeploymentReplicationPhaseStoppingSync
+				}
+				if err := dr.updateCRStatus(); err != nil {
+					return true, errors.WithStack(err)
+				}
+			}
+		}
+	}
+
+	switch dr.status.Phase {
+	case api.DeploymentReplicationPhaseWaitingForSync:
+		return true, dr.inspectActionWaitingForSync(ctx)
+	case api.DeploymentReplicationPhaseStoppingSync:
+		return true, dr.inspectActionStoppingSync(ctx)
+	case api.DeploymentReplicationPhaseReversing:
+		return true, dr.inspectActionReversing()
+	default:
+		// Unknown step, start over
+		dr.status.Action = nil
+		dr.status.Phase = api.DeploymentReplicationPhaseNone
+		if action == api.DeploymentReplicationActionNone {
+			dr.status.Conditions.Update(api.ConditionTypeActionInProgress, false, "Aborted", "Action removed from spec")
+			if err := dr.updateCRStatus(); err != nil {
+				return false, errors.WithStack(err)
+			}
+			return false, nil
+		}
+		return dr.startAction(action)
+	}
+}
+
+// startAction validates the given action and moves to its first step.
+func (dr *DeploymentReplication) startAction(action api.DeploymentReplicationAction) (bool, error) {
+	log := dr.deps.Log.With().Str("action", string(action)).Logger()
+
+	switch action {
+	case api.DeploymentReplicationActionSwitchover, api.DeploymentReplicationActionFailover:
+	default:
+		return false, errors.Newf("Unknown action '%s'", action)
+	}
+
+	// Both endpoints must be usable in the reversed direction
+	if err := dr.apiObject.Spec.Reversed().Validate(); err != nil {
+		log.Warn().Err(err).Msg("Cannot reverse replication")
+		dr.createEvent(k8sutil.NewErrorEvent("Action rejected", err, dr.apiObject))
+		dr.status.Conditions.Update(api.ConditionTypeActionInProgress, false, "Rejected", err.Error())
+		spec := dr.apiObject.Spec
+		spec.Action = nil
+		if err := dr.updateCRSpec(spec); err != nil {
+			return false, errors.WithStack(err)
+		}
+		return false, nil
+	}
+
+	log.Info().Msg("Starting action")
+	dr.status.Action = &api.DeploymentReplicationActionStatus{
+		Type:      action,
+		StartTime: metav1.Now(),
+	}
+	if action.IsForced() {
+		dr.status.Phase = api.DeploymentReplicationPhaseStoppingSync
+		dr.status.Conditions.Update(api.ConditionTypeActionInProgress, true, string(action), "Aborting synchronization")
+	} else {
+		dr.status.Phase = api.DeploymentReplicationPhaseWaitingForSync
+		dr.status.Conditions.Update(api.ConditionTypeActionInProgress, true, string(action), "Waiting for destination to be in sync")
+	}
+	dr.createEvent(k8sutil.NewReplicationActionStartedEvent(dr.apiObject, string(action)))
+	if err := dr.updateCRStatus(); err != nil {
+		return true, errors.WithStack(err)
+	}
+	return true, nil
+}
+
+// abortAction stops the running action before synchronization has been stopped.
+func (dr *DeploymentReplication) abortAction() (bool, error) {
+	dr.deps.Log.Info().Str("action", string(dr.status.Action.Type)).Msg("Action withdrawn")
+	dr.status.Action = nil
+	dr.status.Phase = api.DeploymentReplicationPhaseNone
+	dr.status.Conditions.Update(api.ConditionTypeActionInProgress, false, "Aborted", "Action removed from spec")
+	if err := dr.updateCRStatus(); err != nil {
+		return false, errors.WithStack(err)
+	}
+	return false, nil
+}
+
+// inspectActionWaitingForSync moves to the next step once all shards of the destination are in sync.
+func (dr *DeploymentReplication) inspectActionWaitingForSync(ctx context.Context) error {
+	destClient, err := dr.createSyncMasterClient(dr.apiObject.Spec.Destination)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	destStatus, err := destClient.Master().Status(ctx)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	dr.status.Destination = createEndpointStatus(destStatus, "")
+	dr.metrics.setDestination(newEndpointMetrics(endpointDestination, dr.status.Destination, getEndpointShards(destStatus, "")))
+	if destStatus.Status == client.SyncStatusRunning && isEndpointInSync(dr.status.Destination) {
+		dr.deps.Log.Info().Msg("Destination is in sync, stopping synchronization")
+		dr.status.Phase = api.DeploymentReplicationPhaseStoppingSync
+		dr.status.Conditions.Update(api.ConditionTypeActionInProgress, true, string(dr.status.Action.Type), "Stopping writes on source and canceling synchronization")
+	}
+	if err := dr.updateCRStatus(); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// inspectActionStoppingSync cancels synchronization on the destination.
+// Without force, the source is switched to read-only until the remaining changes are synchronized.
+func (dr *DeploymentReplication) inspectActionStoppingSync(ctx context.Context) error {
+	log := dr.deps.Log
+	action := dr.status.Action.Type
+	destClient, err := dr.createSyncMasterClient(dr.apiObject.Spec.Destination)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	req := client.CancelSynchronizationRequest{
+		WaitTimeout: time.Minute * 3,
+		Force:       action.IsForced(),
+	}
+	if req.Force {
+		req.ForceTimeout = time.Minute * 2
+	}
+	log.Info().Bool("force", req.Force).Msg("Canceling synchronization")
+	if _, err := destClient.Master().CancelSynchronization(ctx, req); err != nil && !client.IsPreconditionFailed(err) {
+		dr.status.CancelFailures++
+		cancelFailuresCounters.WithLabelValues(dr.apiObject.GetNamespace(), dr.apiObject.GetName()).Inc()
+		dr.status.Conditions.Update(api.ConditionTypeActionInProgress, true, string(action), "Failed to cancel synchronization: "+err.Error())
+		if err := dr.updateCRStatus(); err != nil {
+			log.Warn().Err(err).Msg("Failed to update status to reflect cancel-failures increment")
+		}
+		return errors.WithStack(err)
+	}
+	dr.status.Phase = api.DeploymentReplicationPhaseReversing
+	dr.status.Conditions.Update(api.ConditionTypeActionInProgress, true, string(action), "Reversing replication direction")
+	if err := dr.updateCRStatus(); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// inspectActionReversing swaps source and destination in the spec and finishes the action.
+// Synchronization in the new direction is configured by the regular inspection.
+func (dr *DeploymentReplication) inspectActionReversing() error {
+	action := dr.status.Action.Type
+	previous := dr.status.DeepCopy()
+	dr.status.Action = nil
+	dr.status.Phase = api.DeploymentReplicationPhaseNone
+	dr.status.Source = api.EndpointStatus{}
+	dr.status.Destination = api.EndpointStatus{}
+	dr.status.CancelFailures = 0
+	dr.status.Conditions.Update(api.ConditionTypeConfigured, false, "Reversed", "Source and destination have been swapped")
+	dr.status.Conditions.Update(api.ConditionTypeActionInProgress, false, "Completed", string(action)+" completed")
+	if err := dr.updateCRSpec(dr.apiObject.Spec.Reversed()); err != nil {
+		// Retry this step with the next inspection
+		dr.status = *previous
+		return errors.WithStack(err)
+	}
+	dr.deps.Log.Info().Str("action", string(action)).Msg("Action completed")
+	dr.createEvent(k8sutil.NewReplicationActionFinishedEvent(dr.apiObject, string(action)))
+	return nil
+}
+
+// isEndpointInSync returns true when the given endpoint status has shards and all of them are running.
+func isEndpointInSync(status api.EndpointStatus) bool {
+	shards := 0
+	for _, db := range status.Databases {
+		for _, col := range db.Collections {
+			for _, s := range col.Shards {
+				if s.Status != string(client.SyncStatusRunning) {
+					return false
+				}
+				shards++
+			}
+		}
+	}
+	return shards > 0
+}
@@ -0,0 +1,49 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+//
+
+package replication
+
+import (
+	"testing"
+
+	"github.com/arangodb/arangosync-client/client"
+	"github.com/stretchr/testify/assert"
+
+	api "github.com/arangodb/kube-arangodb/pkg/apis/replication/v1"
+)
+
+func TestIsEndpointInSync(t *testing.T) {
+	running := api.ShardStatus{Status: string(client.SyncStatusRunning)}
+	initial := api.ShardStatus{Status: string(client.SyncStatusInitialSync)}
+	endpoint := func(shards ...api.ShardStatus) api.EndpointStatus {
+		return api.EndpointStatus{
+			Databases: []api.DatabaseStatus{
+				{Name: "db", Collections: []api.CollectionStatus{{Name: "col", Shards: shards}}},
+			},
+		}
+	}
+
+	assert.False(t, isEndpointInSync(api.EndpointStatus{}), "empty status")
+	assert.False(t, isEndpointInSync(endpoint()), "no shards")
+	assert.False(t, isEndpointInSync(endpoint(running, initial)), "shard in initial sync")
+	assert.True(t, isEndpointInSync(endpoint(running)), "single running shard")
+	assert.True(t, isEndpointInSync(endpoint(running, running)), "all shards running")
+}
@@ -66,11 +66,27 @@ func (dr *DeploymentReplication) inspectDeploymentReplication(lastInterval time.
 			log.Warn().Err(err).Msg("Failed to run finalizers")
 			hasError = true
 		}
+	} else if inProgress, err := dr.inspectAction(ctx); inProgress || err != nil {
+		// Switchover/failover in progress
+		if err != nil {
+			log.Warn().Err(err).Msg("Failed to run action")
+			hasError = true
+		}
+		if inProgress {
+			nextInterval = actionInspectionInterval
+		}
+	} else if paused, err := dr.inspectPause(ctx); paused || err != nil {
+		// Synchronization is suspended
+		if err != nil {
+			log.Warn().Err(err).Msg("Failed to pause or resume synchronization")
+			hasError = true
+		}
 	} else {
 		// Inspect configuration status
 		destClient, err := dr.createSyncMasterClient(spec.Destination)
 		if err != nil {
 			log.Warn().Err(err).Msg("Failed to create destination syncmaster client")
+			hasError = true
 		} else {
 			// Fetch status of destination
 			updateStatusNeeded := false
@@ -79,22 +95,26 @@ func (dr *DeploymentReplication) inspectDeploymentReplication(lastInterval time.
 			destEndpoint, err := destClient.Master().GetEndpoints(ctx)
 			if err != nil {
 				log.Warn().Err(err).Msg("Failed to fetch endpoints from destination syncmaster")
+				hasError = true
 			}
 			destStatus, err := destClient.Master().Status(ctx)
 			if err != nil {
 				log.Warn().Err(err).Msg("Failed to fetch status from destination syncmaster")
+				hasError = true
 			} else {
 				// Inspect destination status
 				if destStatus.Status.IsActive() {
 					isIncomingEndpoint, err := dr.isIncomingEndpoint(destStatus, spec.Source)
 					if err != nil {
 						log.Warn().Err(err).Msg("Failed to check is-incoming-endpoint")
+						hasError = true
 					} else {
 						if isIncomingEndpoint {
 							// Destination is correctly configured
 							dr.status.Conditions.Update(api.ConditionTypeConfigured, true, "Active", "Destination syncmaster is configured correctly and active")
 							// Fetch shard status
 							dr.status.Destination = createEndpointStatus(destStatus, "")
+							dr.metrics.setDestination(newEndpointMetrics(endpointDestination, dr.status.Destination, getEndpointShards(destStatus, "")))
 							updateStatusNeeded = true
 						} else {
 							// Sync is active, but from different source
@@ -118,10 +138,12 @@ func (dr *DeploymentReplication) inspectDeploymentReplication(lastInterval time.
 			sourceClient, err := dr.createSyncMasterClient(spec.Source)
 			if err != nil {
 				log.Warn().Err(err).Msg("Failed to create source syncmaster client")
+				hasError = true
 			} else {
 				sourceStatus, err := sourceClient.Master().Status(ctx)
 				if err != nil {
 					log.Warn().Err(err).Msg("Failed to fetch status from source syncmaster")
+					hasError = true
 				}
 
 				//if sourceStatus.Status.IsActive() {
@@ -132,6 +154,7 @@ func (dr *DeploymentReplication) inspectDeploymentReplication(lastInterval time.
 					// Destination is know in source
 					// Fetch shard status
 					dr.status.Source = createEndpointStatus(sourceStatus, outgoingID)
+					dr.metrics.setSource(newEndpointMetrics(endpointSource, dr.status.Source, getEndpointShards(sourceStatus, outgoingID)))
 					updateStatusNeeded = true
 				} else {
 					// We cannot find the destination in the source status
@@ -153,6 +176,7 @@ func (dr *DeploymentReplication) inspectDeploymentReplication(lastInterval time.
 				log.Info().Msg("Canceling synchronization")
 				if _, err := destClient.Master().CancelSynchronization(ctx, req); err != nil {
 					log.Warn().Err(err).Msg("Failed to cancel synchronization")
+					cancelFailuresCounters.WithLabelValues(dr.apiObject.GetNamespace(), dr.apiObject.GetName()).Inc()
 					hasError = true
 				} else {
 					log.Info().Msg("Canceled synchronization")
@@ -190,6 +214,10 @@ func (dr *DeploymentReplication) inspectDeploymentReplication(lastInterval time.
 		}
 	}
 
+	if !hasError {
+		dr.metrics.setLastInspection(time.Now())
+	}
+
 	// Update next interval (on errors)
 	if hasError {
 		if dr.recentInspectionErrors == 0 {
@@ -234,18 +262,23 @@ func (dr *DeploymentReplication) hasOutgoingEndpoint(status client.SyncInfo, epS
 
 // createEndpointStatus creates an api EndpointStatus from the given sync status.
 func createEndpointStatus(status client.SyncInfo, outgoingID string) api.EndpointStatus {
-	result := api.EndpointStatus{}
+	return createEndpointStatusFromShards(getEndpointShards(status, outgoingID))
+}
+
+// getEndpointShards returns the incoming shards of the given sync status (if outgoingID is empty),
+// or the shards of the outgoing synchronization with given ID.
+func getEndpointShards(status client.SyncInfo, outgoingID string) []client.ShardSyncInfo {
 	if outgoingID == "" {
-		return createEndpointStatusFromShards(status.Shards)
+		return status.Shards
 	}
 	for _, o := range status.Outgoing {
 		if o.ID != outgoingID {
 			continue
 		}
-		return createEndpointStatusFromShards(o.Shards)
+		return o.Shards
 	}
 
-	return result
+	return nil
 }
 
 // createEndpointStatusFromShards creates an api EndpointStatus from the given list of shard statuses.
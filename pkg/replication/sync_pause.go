@@ -0,0 +1,107 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+//
+
+package replication
+
+import (
+	"context"
+	"time"
+
+	"github.com/arangodb/kube-arangodb/pkg/util/errors"
+
+	"github.com/arangodb/arangosync-client/client"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/arangodb/kube-arangodb/pkg/apis/replication/v1"
+)
+
+// inspectPause suspends synchronization when `spec.paused` is set.
+// Returns true when the replication is paused, in which case the regular inspection must be skipped.
+// When `spec.paused` is cleared, the phase is reset so the regular inspection configures
+// synchronization again. The destination keeps its data, so synchronization resumes incrementally.
+// Pausing is retried with every inspection until it succeeds, see pauseSynchronization.
+func (dr *DeploymentReplication) inspectPause(ctx context.Context) (bool, error) {
+	log := dr.deps.Log
+
+	if !dr.apiObject.Spec.IsPaused() {
+		if dr.status.Phase != api.DeploymentReplicationPhasePaused {
+			return false, nil
+		}
+		log.Info().Msg("Resuming synchronization")
+		dr.status.Phase = api.DeploymentReplicationPhaseNone
+		dr.status.PausedTime = nil
+		if err := dr.updateCRStatus(); err != nil {
+			return false, errors.WithStack(err)
+		}
+		return false, nil
+	}
+
+	if dr.status.Phase == api.DeploymentReplicationPhasePaused {
+		// Already paused
+		return true, nil
+	}
+
+	destClient, err := dr.createSyncMasterClient(dr.apiObject.Spec.Destination)
+	if err != nil {
+		return true, errors.WithStack(err)
+	}
+	return true, dr.pauseSynchronization(ctx, destClient.Master())
+}
+
+// synchronizationCanceler is the part of the syncmaster API used to pause synchronization.
+type synchronizationCanceler interface {
+	CancelSynchronization(ctx context.Context, input client.CancelSynchronizationRequest) (client.CancelSynchronizationResponse, error)
+}
+
+// pauseSynchronization cancels synchronization on the destination and switches to the paused phase.
+// A cancel that does not finish in time (e.g. during a large initial sync) is recorded in the
+// status and retried with the next inspection. After maxCancelFailures attempts the cancel is forced.
+func (dr *DeploymentReplication) pauseSynchronization(ctx context.Context, master synchronizationCanceler) error {
+	log := dr.deps.Log
+	req := client.CancelSynchronizationRequest{
+		WaitTimeout: time.Minute * 3,
+		Force:       dr.status.CancelFailures > maxCancelFailures,
+	}
+	if req.Force {
+		req.ForceTimeout = time.Minute * 2
+	}
+	log.Info().Bool("force", req.Force).Msg("Pausing synchronization")
+	if _, err := master.CancelSynchronization(ctx, req); err != nil && !client.IsPreconditionFailed(err) {
+		dr.status.CancelFailures++
+		cancelFailuresCounters.WithLabelValues(dr.apiObject.GetNamespace(), dr.apiObject.GetName()).Inc()
+		dr.status.Conditions.Update(api.ConditionTypeConfigured, false, "PauseFailed", "Failed to pause synchronization: "+err.Error())
+		if err := dr.updateCRStatus(); err != nil {
+			log.Warn().Err(err).Msg("Failed to update status to reflect cancel-failures increment")
+		}
+		return errors.WithStack(err)
+	}
+
+	now := metav1.Now()
+	dr.status.Phase = api.DeploymentReplicationPhasePaused
+	dr.status.PausedTime = &now
+	dr.status.CancelFailures = 0
+	dr.status.Conditions.Update(api.ConditionTypeConfigured, false, "Paused", "Synchronization is paused")
+	if err := dr.updateCRStatus(); err != nil {
+		return errors.WithStack(err)
+	}
+	log.Info().Msg("Paused synchronization")
+	return nil
+}
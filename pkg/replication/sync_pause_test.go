@@ -0,0 +1,134 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+//
+
+package replication
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/arangodb/arangosync-client/client"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/arangodb/kube-arangodb/pkg/apis/replication/v1"
+	"github.com/arangodb/kube-arangodb/pkg/generated/clientset/versioned/fake"
+	"github.com/arangodb/kube-arangodb/pkg/util"
+)
+
+type fakeCanceler struct {
+	requests []client.CancelSynchronizationRequest
+	err      error
+}
+
+func (f *fakeCanceler) CancelSynchronization(_ context.Context, input client.CancelSynchronizationRequest) (client.CancelSynchronizationResponse, error) {
+	f.requests = append(f.requests, input)
+	return client.CancelSynchronizationResponse{}, f.err
+}
+
+func newPauseTestReplication(paused bool, status api.DeploymentReplicationStatus) *DeploymentReplication {
+	obj := &api.ArangoDeploymentReplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "replication", Namespace: "test"},
+		Spec:       api.DeploymentReplicationSpec{Paused: util.NewBool(paused)},
+		Status:     status,
+	}
+	crcli := fake.NewSimpleClientset(obj)
+	return &DeploymentReplication{
+		apiObject: obj,
+		status:    *status.DeepCopy(),
+		deps:      Dependencies{Log: zerolog.Nop(), CRCli: crcli},
+	}
+}
+
+func TestPauseSynchronization(t *testing.T) {
+	dr := newPauseTestReplication(true, api.DeploymentReplicationStatus{})
+	master := &fakeCanceler{}
+
+	require.NoError(t, dr.pauseSynchronization(context.Background(), master))
+
+	require.Len(t, master.requests, 1)
+	assert.False(t, master.requests[0].Force)
+	assert.Equal(t, api.DeploymentReplicationPhasePaused, dr.status.Phase)
+	assert.NotNil(t, dr.status.PausedTime)
+	c, ok := dr.status.Conditions.Get(api.ConditionTypeConfigured)
+	require.True(t, ok)
+	assert.Equal(t, "Paused", c.Reason)
+	assert.Equal(t, api.DeploymentReplicationPhasePaused, dr.apiObject.Status.Phase, "status must be persisted")
+
+	// Pausing an already paused replication does not call the syncmaster again
+	paused, err := dr.inspectPause(context.Background())
+	require.NoError(t, err)
+	assert.True(t, paused)
+	assert.Len(t, master.requests, 1)
+}
+
+func TestPauseSynchronization_Timeout(t *testing.T) {
+	dr := newPauseTestReplication(true, api.DeploymentReplicationStatus{})
+	master := &fakeCanceler{err: errors.New("timeout waiting for initial synchronization")}
+
+	for i := 0; i <= maxCancelFailures; i++ {
+		require.Error(t, dr.pauseSynchronization(context.Background(), master))
+
+		assert.NotEqual(t, api.DeploymentReplicationPhasePaused, dr.status.Phase)
+		assert.Equal(t, i+1, dr.status.CancelFailures)
+		c, ok := dr.status.Conditions.Get(api.ConditionTypeConfigured)
+		require.True(t, ok, "failure must be visible in the conditions")
+		assert.Equal(t, "PauseFailed", c.Reason)
+		assert.Contains(t, c.Message, "timeout waiting for initial synchronization")
+		assert.Equal(t, dr.status.CancelFailures, dr.apiObject.Status.CancelFailures, "status must be persisted")
+	}
+	for _, req := range master.requests {
+		assert.False(t, req.Force)
+	}
+
+	// Once the limit is exceeded, the cancel is forced
+	master.err = nil
+	require.NoError(t, dr.pauseSynchronization(context.Background(), master))
+
+	last := master.requests[len(master.requests)-1]
+	assert.True(t, last.Force)
+	assert.NotZero(t, last.ForceTimeout)
+	assert.Equal(t, api.DeploymentReplicationPhasePaused, dr.status.Phase)
+	assert.Zero(t, dr.status.CancelFailures)
+}
+
+func TestInspectPause_Resume(t *testing.T) {
+	now := metav1.Now()
+	dr := newPauseTestReplication(false, api.DeploymentReplicationStatus{
+		Phase:      api.DeploymentReplicationPhasePaused,
+		PausedTime: &now,
+	})
+
+	paused, err := dr.inspectPause(context.Background())
+	require.NoError(t, err)
+	assert.False(t, paused)
+	assert.Equal(t, api.DeploymentReplicationPhaseNone, dr.status.Phase)
+	assert.Nil(t, dr.status.PausedTime)
+	assert.Equal(t, api.DeploymentReplicationPhaseNone, dr.apiObject.Status.Phase)
+
+	// Not paused and not in the paused phase: nothing to do
+	paused, err = dr.inspectPause(context.Background())
+	require.NoError(t, err)
+	assert.False(t, paused)
+}
@@ -0,0 +1,47 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package server
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// audit records every request passing through it in the audit log,
+// once the request has been handled.
+func (s *Server) audit(c *gin.Context) {
+	start := time.Now()
+
+	c.Next()
+
+	event := s.deps.AuditLog.Info().
+		Str("user", getUsername(c)).
+		Str("client", c.ClientIP()).
+		Str("method", c.Request.Method).
+		Str("path", c.Request.URL.Path).
+		Int("status", c.Writer.Status()).
+		Dur("duration", time.Since(start))
+	for _, p := range c.Params {
+		event = event.Str(p.Key, p.Value)
+	}
+	event.Msg("Audit")
+}
@@ -43,8 +43,24 @@ import (
 const (
 	tokenExpirationTime = time.Hour
 	bearerPrefix        = "bearer "
+
+	// usernameKey is the gin context key of the name of the authenticated user
+	usernameKey       = "username"
+	anonymousUsername = "anonymous"
+	// anonymousKey is the gin context key set when the request has been let through without credentials
+	anonymousKey = "anonymous"
 )
 
+// authentication is implemented by all authentication modes of the server
+type authentication interface {
+	// checkAuthentication is a middleware which aborts requests which are not authenticated (or not authorized)
+	checkAuthentication(c *gin.Context)
+	// handleLogin handles a POST /login request
+	handleLogin(c *gin.Context)
+}
+
+var _ authentication = &serverAuthentication{}
+
 type serverAuthentication struct {
 	log     zerolog.Logger
 	secrets typedv1.SecretInterface
@@ -63,6 +79,7 @@ type serverAuthentication struct {
 
 type tokenEntry struct {
 	Token     string
+	Username  string
 	ExpiresAt time.Time
 }
 
@@ -74,6 +91,7 @@ func (t *tokenEntry) IsExpired() bool {
 type loginRequest struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
+	Token    string `json:"token,omitempty"`
 }
 
 // loginResponse is the JSON structure returned from `/login`.
@@ -144,18 +162,31 @@ func (s *serverAuthentication) checkLogin(username, password string) error {
 	return nil
 }
 
-// Handle the authentication check
+// Handle the authentication check.
+// When anonymous access is allowed, requests without valid credentials are let through as anonymous,
+// while requests with a valid token are still authenticated as its user.
 func (s *serverAuthentication) checkAuthentication(c *gin.Context) {
-	if s.allowAnonymous {
-		// All ok
+	username, err := s.authenticate(c)
+	if err != nil {
+		if s.allowAnonymous {
+			// All ok
+			c.Set(usernameKey, anonymousUsername)
+			c.Set(anonymousKey, true)
+			return
+		}
+		sendError(c, err)
+		c.Abort()
 		return
 	}
+	c.Set(usernameKey, username)
+}
+
+// authenticate looks up the bearer token of the request and returns the name of its user.
+func (s *serverAuthentication) authenticate(c *gin.Context) (string, error) {
 	// Fetch authorization token
 	authHdr := strings.ToLower(c.Request.Header.Get("Authorization"))
 	if !strings.HasPrefix(authHdr, bearerPrefix) {
-		sendError(c, errors.WithStack(errors.Wrap(UnauthorizedError, "missing bearer token")))
-		c.Abort()
-		return
+		return "", errors.WithStack(errors.Wrap(UnauthorizedError, "missing bearer token"))
 	}
 	token := strings.TrimSpace(authHdr[len(bearerPrefix):])
 	// Lookup token
@@ -163,17 +194,14 @@ func (s *serverAuthentication) checkAuthentication(c *gin.Context) {
 	defer s.tokens.mutex.Unlock()
 	if entry, found := s.tokens.tokens[token]; !found {
 		s.log.Debug().Str("token", token).Msg("Invalid token")
-		sendError(c, errors.WithStack(errors.Wrap(UnauthorizedError, "invalid credentials")))
-		c.Abort()
-		return
+		return "", errors.WithStack(errors.Wrap(UnauthorizedError, "invalid credentials"))
 	} else if entry.IsExpired() {
 		s.log.Debug().Str("token", token).Msg("Token expired")
-		sendError(c, errors.WithStack(errors.Wrap(UnauthorizedError, "credentials expired")))
-		c.Abort()
-		return
+		return "", errors.WithStack(errors.Wrap(UnauthorizedError, "credentials expired"))
 	} else {
 		// All good, renew expiration
 		entry.ExpiresAt = time.Now().Add(tokenExpirationTime)
+		return entry.Username, nil
 	}
 }
 
@@ -194,6 +222,7 @@ func (s *serverAuthentication) handleLogin(c *gin.Context) {
 	defer s.tokens.mutex.Unlock()
 	s.tokens.tokens[token] = &tokenEntry{
 		Token:     token,
+		Username:  req.Username,
 		ExpiresAt: time.Now().Add(tokenExpirationTime),
 	}
 	// Send response
@@ -201,3 +230,18 @@ func (s *serverAuthentication) handleLogin(c *gin.Context) {
 		Token: token,
 	})
 }
+
+// getUsername returns the name of the authenticated user of the request
+func getUsername(c *gin.Context) string {
+	return c.GetString(usernameKey)
+}
+
+// requireAuthenticated is a middleware which aborts requests let through anonymously,
+// regardless of whether anonymous access is allowed.
+func requireAuthenticated(c *gin.Context) {
+	if c.GetBool(anonymousKey) {
+		sendError(c, errors.WithStack(errors.Wrap(UnauthorizedError, "authentication required")))
+		c.Abort()
+		return
+	}
+}
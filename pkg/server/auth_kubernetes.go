@@ -0,0 +1,317 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	authenticationApi "k8s.io/api/authentication/v1"
+	authorizationApi "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	authenticationv1 "k8s.io/client-go/kubernetes/typed/authentication/v1"
+	authorizationv1 "k8s.io/client-go/kubernetes/typed/authorization/v1"
+
+	"github.com/arangodb/kube-arangodb/pkg/apis/backup"
+	"github.com/arangodb/kube-arangodb/pkg/apis/deployment"
+	"github.com/arangodb/kube-arangodb/pkg/apis/replication"
+	storage "github.com/arangodb/kube-arangodb/pkg/apis/storage/v1alpha"
+	"github.com/arangodb/kube-arangodb/pkg/util/errors"
+)
+
+const (
+	// AuthModeBasic authenticates users with the username & password from the admin secret
+	AuthModeBasic = "basic"
+	// AuthModeKubernetes authenticates users with Kubernetes bearer tokens
+	// and authorizes every request against the RBAC rules of the cluster
+	AuthModeKubernetes = "kubernetes"
+
+	// reviewCacheTime is the time results of TokenReviews & SubjectAccessReviews are cached
+	reviewCacheTime = time.Minute
+	reviewTimeout   = 10 * time.Second
+)
+
+// routeResource describes the Kubernetes resource which is accessed by an API route.
+type routeResource struct {
+	Verb      string
+	Group     string
+	Resource  string
+	NameParam string
+	Scoped    bool
+}
+
+// routeResources maps API routes (method + path) to the resources they access.
+var routeResources = map[string]routeResource{
+	"GET /api/operators": {Verb: "list", Group: deployment.ArangoDeploymentGroupName, Resource: deployment.ArangoDeploymentResourcePlural, Scoped: true},
+
+	"GET /api/deployment":                           {Verb: "list", Group: deployment.ArangoDeploymentGroupName, Resource: deployment.ArangoDeploymentResourcePlural, Scoped: true},
+	"GET /api/deployment/:name":                     {Verb: "get", Group: deployment.ArangoDeploymentGroupName, Resource: deployment.ArangoDeploymentResourcePlural, NameParam: "name", Scoped: true},
+	"GET /api/deployment/:name/plan":                {Verb: "get", Group: deployment.ArangoDeploymentGroupName, Resource: deployment.ArangoDeploymentResourcePlural, NameParam: "name", Scoped: true},
+	"GET /api/deployment/:name/events":              {Verb: "get", Group: deployment.ArangoDeploymentGroupName, Resource: deployment.ArangoDeploymentResourcePlural, NameParam: "name", Scoped: true},
+	"GET /api/deployment/:name/shards":              {Verb: "get", Group: deployment.ArangoDeploymentGroupName, Resource: deployment.ArangoDeploymentResourcePlural, NameParam: "name", Scoped: true},
+	"GET /api/deployment/:name/supervision":         {Verb: "get", Group: deployment.ArangoDeploymentGroupName, Resource: deployment.ArangoDeploymentResourcePlural, NameParam: "name", Scoped: true},
+	"POST /api/deployment/:name/scale":              {Verb: "update", Group: deployment.ArangoDeploymentGroupName, Resource: deployment.ArangoDeploymentResourcePlural, NameParam: "name", Scoped: true},
+	"POST /api/deployment/:name/member/:id/rotate":  {Verb: "update", Group: deployment.ArangoDeploymentGroupName, Resource: deployment.ArangoDeploymentResourcePlural, NameParam: "name", Scoped: true},
+	"POST /api/deployment/:name/member/:id/replace": {Verb: "update", Group: deployment.ArangoDeploymentGroupName, Resource: deployment.ArangoDeploymentResourcePlural, NameParam: "name", Scoped: true},
+	"PUT /api/deployment/:name/maintenance":         {Verb: "update", Group: deployment.ArangoDeploymentGroupName, Resource: deployment.ArangoDeploymentResourcePlural, NameParam: "name", Scoped: true},
+	"GET /api/deployment/:name/backup":              {Verb: "list", Group: backup.ArangoBackupGroupName, Resource: backup.ArangoBackupResourcePlural, Scoped: true},
+	"POST /api/deployment/:name/backup":             {Verb: "create", Group: backup.ArangoBackupGroupName, Resource: backup.ArangoBackupResourcePlural, Scoped: true},
+	"DELETE /api/deployment/:name/backup/:backup":   {Verb: "delete", Group: backup.ArangoBackupGroupName, Resource: backup.ArangoBackupResourcePlural, NameParam: "backup", Scoped: true},
+
+	"GET /api/deployment-replication":       {Verb: "list", Group: replication.ArangoDeploymentReplicationGroupName, Resource: replication.ArangoDeploymentReplicationResourcePlural, Scoped: true},
+	"GET /api/deployment-replication/:name": {Verb: "get", Group: replication.ArangoDeploymentReplicationGroupName, Resource: replication.ArangoDeploymentReplicationResourcePlural, NameParam: "name", Scoped: true},
+
+	"GET /metrics/deployment/:name": {Verb: "get", Group: deployment.ArangoDeploymentGroupName, Resource: deployment.ArangoDeploymentResourcePlural, NameParam: "name", Scoped: true},
+
+	"GET /api/storage":       {Verb: "list", Group: storage.SchemeGroupVersion.Group, Resource: storage.ArangoLocalStorageResourcePlural},
+	"GET /api/storage/:name": {Verb: "get", Group: storage.SchemeGroupVersion.Group, Resource: storage.ArangoLocalStorageResourcePlural, NameParam: "name"},
+
+	"GET /api/backup":              {Verb: "list", Group: backup.ArangoBackupGroupName, Resource: backup.ArangoBackupResourcePlural, Scoped: true},
+	"GET /api/backup/:name":        {Verb: "get", Group: backup.ArangoBackupGroupName, Resource: backup.ArangoBackupResourcePlural, NameParam: "name", Scoped: true},
+	"GET /api/backup-policy":       {Verb: "list", Group: backup.ArangoBackupGroupName, Resource: backup.ArangoBackupPolicyResourcePlural, Scoped: true},
+	"GET /api/backup-policy/:name": {Verb: "get", Group: backup.ArangoBackupGroupName, Resource: backup.ArangoBackupPolicyResourcePlural, NameParam: "name", Scoped: true},
+}
+
+var _ authentication = &kubernetesAuthentication{}
+
+// kubernetesAuthentication authenticates requests using Kubernetes bearer tokens (TokenReview)
+// and authorizes them using SubjectAccessReviews.
+type kubernetesAuthentication struct {
+	log                  zerolog.Logger
+	tokenReviews         authenticationv1.TokenReviewInterface
+	subjectAccessReviews authorizationv1.SubjectAccessReviewInterface
+	namespace            string
+
+	lock   sync.Mutex
+	users  map[string]reviewCacheEntry
+	access map[string]reviewCacheEntry
+}
+
+type reviewCacheEntry struct {
+	user      authenticationApi.UserInfo
+	allowed   bool
+	expiresAt time.Time
+}
+
+// newKubernetesAuthentication creates a new kubernetes authentication service
+// for the given arguments.
+func newKubernetesAuthentication(log zerolog.Logger, tokenReviews authenticationv1.TokenReviewInterface, subjectAccessReviews authorizationv1.SubjectAccessReviewInterface, namespace string) *kubernetesAuthentication {
+	return &kubernetesAuthentication{
+		log:                  log,
+		tokenReviews:         tokenReviews,
+		subjectAccessReviews: subjectAccessReviews,
+		namespace:            namespace,
+		users:                map[string]reviewCacheEntry{},
+		access:               map[string]reviewCacheEntry{},
+	}
+}
+
+// Handle the authentication & authorization check
+func (k *kubernetesAuthentication) checkAuthentication(c *gin.Context) {
+	token, ok := getBearerToken(c)
+	if !ok {
+		sendError(c, errors.WithStack(errors.Wrap(UnauthorizedError, "missing bearer token")))
+		c.Abort()
+		return
+	}
+
+	user, err := k.authenticate(c.Request.Context(), token)
+	if err != nil {
+		sendError(c, err)
+		c.Abort()
+		return
+	}
+	c.Set(usernameKey, user.Username)
+
+	resource, found := routeResources[c.Request.Method+" "+c.FullPath()]
+	if !found {
+		// Unknown routes are denied
+		sendError(c, errors.WithStack(errors.Wrap(ForbiddenError, "access to the resource is not allowed")))
+		c.Abort()
+		return
+	}
+
+	if err := k.authorize(c.Request.Context(), token, user, k.resourceAttributes(c, resource)); err != nil {
+		sendError(c, err)
+		c.Abort()
+		return
+	}
+}
+
+// Handle a POST /login request.
+// The given token is validated and returned, so it can be used as bearer token.
+func (k *kubernetesAuthentication) handleLogin(c *gin.Context) {
+	var req loginRequest
+	if err := c.BindJSON(&req); err != nil {
+		sendError(c, err)
+		return
+	}
+	token := req.Token
+	if token == "" {
+		// Allow passing the token as password
+		token = req.Password
+	}
+	if _, err := k.authenticate(c.Request.Context(), token); err != nil {
+		sendError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, loginResponse{
+		Token: token,
+	})
+}
+
+// authenticate validates the given token and returns the user it belongs to.
+func (k *kubernetesAuthentication) authenticate(ctx context.Context, token string) (authenticationApi.UserInfo, error) {
+	if token == "" {
+		return authenticationApi.UserInfo{}, errors.WithStack(errors.Wrap(UnauthorizedError, "missing bearer token"))
+	}
+
+	key := hashToken(token)
+	if entry, ok := k.getCached(k.users, key); ok {
+		if !entry.allowed {
+			return authenticationApi.UserInfo{}, errors.WithStack(errors.Wrap(UnauthorizedError, "invalid credentials"))
+		}
+		return entry.user, nil
+	}
+
+	ctxChild, cancel := context.WithTimeout(ctx, reviewTimeout)
+	defer cancel()
+	review, err := k.tokenReviews.Create(ctxChild, &authenticationApi.TokenReview{
+		Spec: authenticationApi.TokenReviewSpec{
+			Token: token,
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		k.log.Warn().Err(err).Msg("TokenReview failed")
+		return authenticationApi.UserInfo{}, errors.WithStack(errors.Wrap(UnauthorizedError, "unable to review token"))
+	}
+
+	k.setCached(k.users, key, reviewCacheEntry{user: review.Status.User, allowed: review.Status.Authenticated})
+
+	if !review.Status.Authenticated {
+		k.log.Debug().Str("error", review.Status.Error).Msg("Token not authenticated")
+		return authenticationApi.UserInfo{}, errors.WithStack(errors.Wrap(UnauthorizedError, "invalid credentials"))
+	}
+	return review.Status.User, nil
+}
+
+// authorize checks if the given user is allowed to access the given resource.
+func (k *kubernetesAuthentication) authorize(ctx context.Context, token string, user authenticationApi.UserInfo, attributes *authorizationApi.ResourceAttributes) error {
+	key := strings.Join([]string{hashToken(token), attributes.Verb, attributes.Group, attributes.Resource, attributes.Namespace, attributes.Name}, "/")
+	if entry, ok := k.getCached(k.access, key); ok {
+		if !entry.allowed {
+			return errors.WithStack(errors.Wrapf(ForbiddenError, "%s %s is not allowed", attributes.Verb, attributes.Resource))
+		}
+		return nil
+	}
+
+	extra := make(map[string]authorizationApi.ExtraValue, len(user.Extra))
+	for name, value := range user.Extra {
+		extra[name] = authorizationApi.ExtraValue(value)
+	}
+
+	ctxChild, cancel := context.WithTimeout(ctx, reviewTimeout)
+	defer cancel()
+	review, err := k.subjectAccessReviews.Create(ctxChild, &authorizationApi.SubjectAccessReview{
+		Spec: authorizationApi.SubjectAccessReviewSpec{
+			ResourceAttributes: attributes,
+			User:               user.Username,
+			Groups:             user.Groups,
+			UID:                user.UID,
+			Extra:              extra,
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		k.log.Warn().Err(err).Msg("SubjectAccessReview failed")
+		return errors.WithStack(errors.Wrap(ForbiddenError, "unable to review access"))
+	}
+
+	k.setCached(k.access, key, reviewCacheEntry{user: user, allowed: review.Status.Allowed})
+
+	if !review.Status.Allowed {
+		return errors.WithStack(errors.Wrapf(ForbiddenError, "%s %s is not allowed", attributes.Verb, attributes.Resource))
+	}
+	return nil
+}
+
+// resourceAttributes returns the attributes of the resource accessed by the request.
+func (k *kubernetesAuthentication) resourceAttributes(c *gin.Context, resource routeResource) *authorizationApi.ResourceAttributes {
+	attributes := &authorizationApi.ResourceAttributes{
+		Verb:     resource.Verb,
+		Group:    resource.Group,
+		Resource: resource.Resource,
+	}
+	if resource.Scoped {
+		attributes.Namespace = k.namespace
+	}
+	if resource.NameParam != "" {
+		attributes.Name = c.Params.ByName(resource.NameParam)
+	}
+	return attributes
+}
+
+func (k *kubernetesAuthentication) getCached(cache map[string]reviewCacheEntry, key string) (reviewCacheEntry, bool) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	entry, ok := cache[key]
+	if !ok || entry.expiresAt.Before(time.Now()) {
+		return reviewCacheEntry{}, false
+	}
+	return entry, true
+}
+
+func (k *kubernetesAuthentication) setCached(cache map[string]reviewCacheEntry, key string, entry reviewCacheEntry) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	now := time.Now()
+	// Remove expired entries
+	for name, e := range cache {
+		if e.expiresAt.Before(now) {
+			delete(cache, name)
+		}
+	}
+
+	entry.expiresAt = now.Add(reviewCacheTime)
+	cache[key] = entry
+}
+
+// getBearerToken returns the bearer token of the request
+func getBearerToken(c *gin.Context) (string, bool) {
+	authHdr := c.Request.Header.Get("Authorization")
+	if len(authHdr) < len(bearerPrefix) || !strings.EqualFold(authHdr[:len(bearerPrefix)], bearerPrefix) {
+		return "", false
+	}
+	return strings.TrimSpace(authHdr[len(bearerPrefix):]), true
+}
+
+func hashToken(token string) string {
+	h := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(h[:])
+}
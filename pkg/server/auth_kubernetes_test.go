@@ -0,0 +1,148 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authenticationApi "k8s.io/api/authentication/v1"
+	authorizationApi "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	kubetesting "k8s.io/client-go/testing"
+)
+
+func newTestKubernetesAuthentication(t *testing.T) (*gin.Engine, *int, *[]authorizationApi.ResourceAttributes) {
+	client := fake.NewSimpleClientset()
+
+	tokenReviews := 0
+	client.PrependReactor("create", "tokenreviews", func(action kubetesting.Action) (bool, runtime.Object, error) {
+		tokenReviews++
+		review := action.(kubetesting.CreateAction).GetObject().(*authenticationApi.TokenReview)
+		if review.Spec.Token == "Valid-Token" {
+			review.Status.Authenticated = true
+			review.Status.User = authenticationApi.UserInfo{Username: "alice", Groups: []string{"dev"}}
+		}
+		return true, review, nil
+	})
+
+	var reviewed []authorizationApi.ResourceAttributes
+	client.PrependReactor("create", "subjectaccessreviews", func(action kubetesting.Action) (bool, runtime.Object, error) {
+		review := action.(kubetesting.CreateAction).GetObject().(*authorizationApi.SubjectAccessReview)
+		require.Equal(t, "alice", review.Spec.User)
+		attributes := *review.Spec.ResourceAttributes
+		reviewed = append(reviewed, attributes)
+		review.Status.Allowed = attributes.Verb == "get" || attributes.Verb == "list"
+		return true, review, nil
+	})
+
+	auth := newKubernetesAuthentication(zerolog.Nop(), client.AuthenticationV1().TokenReviews(), client.AuthorizationV1().SubjectAccessReviews(), "ns")
+
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.POST("/login", auth.handleLogin)
+	api := r.Group("/api", auth.checkAuthentication)
+	ok := func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"user": getUsername(c)})
+	}
+	api.GET("/deployment/:name", ok)
+	api.POST("/deployment/:name/scale", ok)
+	api.GET("/unknown", ok)
+	r.GET("/metrics/deployment/:name", auth.checkAuthentication, ok)
+
+	return r, &tokenReviews, &reviewed
+}
+
+func doAuthRequest(r http.Handler, method, path, token string) int {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	r.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestKubernetesAuthentication(t *testing.T) {
+	r, tokenReviews, reviewed := newTestKubernetesAuthentication(t)
+
+	assert.Equal(t, http.StatusUnauthorized, doAuthRequest(r, http.MethodGet, "/api/deployment/test", ""))
+	assert.Equal(t, http.StatusUnauthorized, doAuthRequest(r, http.MethodGet, "/api/deployment/test", "invalid"))
+
+	assert.Equal(t, http.StatusOK, doAuthRequest(r, http.MethodGet, "/api/deployment/test", "Valid-Token"))
+	require.Len(t, *reviewed, 1)
+	assert.Equal(t, authorizationApi.ResourceAttributes{
+		Namespace: "ns",
+		Verb:      "get",
+		Group:     "database.arangodb.com",
+		Resource:  "arangodeployments",
+		Name:      "test",
+	}, (*reviewed)[0])
+
+	assert.Equal(t, http.StatusForbidden, doAuthRequest(r, http.MethodPost, "/api/deployment/test/scale", "Valid-Token"))
+	require.Len(t, *reviewed, 2)
+	assert.Equal(t, "update", (*reviewed)[1].Verb)
+
+	// Routes without known resources are denied
+	assert.Equal(t, http.StatusForbidden, doAuthRequest(r, http.MethodGet, "/api/unknown", "Valid-Token"))
+
+	// Reviews are cached
+	before := *tokenReviews
+	assert.Equal(t, http.StatusOK, doAuthRequest(r, http.MethodGet, "/api/deployment/test", "Valid-Token"))
+	assert.Equal(t, before, *tokenReviews)
+	assert.Len(t, *reviewed, 2)
+}
+
+func TestKubernetesAuthentication_DeploymentMetrics(t *testing.T) {
+	r, _, reviewed := newTestKubernetesAuthentication(t)
+
+	assert.Equal(t, http.StatusOK, doAuthRequest(r, http.MethodGet, "/metrics/deployment/test", "Valid-Token"))
+	require.Len(t, *reviewed, 1)
+	assert.Equal(t, authorizationApi.ResourceAttributes{
+		Namespace: "ns",
+		Verb:      "get",
+		Group:     "database.arangodb.com",
+		Resource:  "arangodeployments",
+		Name:      "test",
+	}, (*reviewed)[0])
+}
+
+func TestKubernetesAuthentication_Login(t *testing.T) {
+	r, _, _ := newTestKubernetesAuthentication(t)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"token":"Valid-Token"}`))
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Valid-Token")
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"token":"invalid"}`))
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
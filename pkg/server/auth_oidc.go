@@ -0,0 +1,667 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dchest/uniuri"
+	"github.com/gin-gonic/gin"
+	jg "github.com/golang-jwt/jwt"
+	"github.com/rs/zerolog"
+	"golang.org/x/oauth2"
+	core "k8s.io/api/core/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	typedv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"github.com/arangodb/kube-arangodb/pkg/util/errors"
+)
+
+const (
+	// AuthModeOIDC authenticates users with an OpenID Connect provider using the authorization code flow
+	AuthModeOIDC = "oidc"
+
+	// OIDCClientSecretKey is the key of the client secret in the Secret referenced by OIDCConfig.ClientSecretName
+	OIDCClientSecretKey = "clientSecret"
+	// OIDCSessionKeyKey is the key of the session signing key in the Secret referenced by OIDCConfig.SessionSecretName
+	OIDCSessionKeyKey = "sessionKey"
+	// DefaultOIDCSessionSecretName is the default name of the Secret holding the session signing key
+	DefaultOIDCSessionSecretName = "arangodb-operator-dashboard-session"
+	// DefaultOIDCGroupsClaim is the default ID token claim holding the groups of the user
+	DefaultOIDCGroupsClaim = "groups"
+
+	// OIDCLoginPath starts the authorization code flow
+	OIDCLoginPath = "/login/oidc"
+	// OIDCCallbackPath is the redirect URL which has to be registered at the provider
+	OIDCCallbackPath = "/login/oidc/callback"
+
+	roleAdmin    = "admin"
+	roleReadOnly = "read-only"
+
+	oidcSessionCookie = "arangodb_operator_session"
+	oidcStateCookie   = "arangodb_operator_oidc_state"
+	oidcSessionTime   = 8 * time.Hour
+	oidcStateTime     = 10 * time.Minute
+	oidcTimeout       = 10 * time.Second
+
+	// Audiences of the tokens signed by the server, so a token of one kind is never accepted as the other
+	oidcSessionAudience = "arangodb-operator-session"
+	oidcStateAudience   = "arangodb-operator-oidc-state"
+)
+
+// OIDCConfig holds the settings of the OpenID Connect authentication mode
+type OIDCConfig struct {
+	IssuerURL         string   // URL of the OpenID Connect issuer
+	ClientID          string   // Client ID of the dashboard
+	ClientSecretName  string   // Name of the Secret containing the client secret (empty for public clients)
+	SessionSecretName string   // Name of the Secret containing the key signing the sessions (created if missing)
+	RedirectURL       string   // External URL of the callback endpoint (OIDCCallbackPath)
+	Scopes            []string // Scopes requested in addition to `openid`
+	GroupsClaim       string   // ID token claim holding the groups of the user
+	AdminGroups       []string // Groups with read-write access
+	ReadOnlyGroups    []string // Groups with read-only access
+}
+
+// oidcProvider holds the parts of the OpenID Connect discovery document used by the server.
+type oidcProvider struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// jsonWebKey is a single (RSA) key of a JSON Web Key Set.
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// oidcSessionClaims are the claims of the session cookie.
+type oidcSessionClaims struct {
+	jg.StandardClaims
+	Role string `json:"role"`
+}
+
+// oidcClaims are the claims of the tokens signed by the server.
+type oidcClaims interface {
+	jg.Claims
+	VerifyAudience(cmp string, req bool) bool
+}
+
+// oidcStateClaims are the claims of the cookie protecting a running authorization code flow.
+type oidcStateClaims struct {
+	jg.StandardClaims
+	Nonce string `json:"nonce"`
+}
+
+var _ authentication = &oidcAuthentication{}
+
+// oidcAuthentication authenticates users with an OpenID Connect provider.
+// Logged in users get a session cookie signed by the server, so no token state is kept in memory.
+// The signing key is kept in a Secret, so sessions stay valid across restarts of the operator.
+// The role of a user (admin or read-only) is taken from the groups claim of the ID token.
+type oidcAuthentication struct {
+	log     zerolog.Logger
+	secrets typedv1.SecretInterface
+	cfg     OIDCConfig
+	client  *http.Client
+
+	lock         sync.Mutex
+	provider     *oidcProvider
+	keys         map[string]*rsa.PublicKey
+	clientSecret string
+	signingKey   []byte
+}
+
+// newOIDCAuthentication creates a new OpenID Connect authentication service
+// for the given arguments.
+func newOIDCAuthentication(log zerolog.Logger, secrets typedv1.SecretInterface, cfg OIDCConfig) (*oidcAuthentication, error) {
+	if cfg.IssuerURL == "" {
+		return nil, errors.WithStack(errors.Newf("No OIDC issuer URL specified"))
+	}
+	if cfg.ClientID == "" {
+		return nil, errors.WithStack(errors.Newf("No OIDC client ID specified"))
+	}
+	if cfg.RedirectURL == "" {
+		return nil, errors.WithStack(errors.Newf("No OIDC redirect URL specified"))
+	}
+	if len(cfg.AdminGroups) == 0 && len(cfg.ReadOnlyGroups) == 0 {
+		return nil, errors.WithStack(errors.Newf("No OIDC admin or read-only groups specified"))
+	}
+	if cfg.GroupsClaim == "" {
+		cfg.GroupsClaim = DefaultOIDCGroupsClaim
+	}
+	if cfg.SessionSecretName == "" {
+		cfg.SessionSecretName = DefaultOIDCSessionSecretName
+	}
+
+	return &oidcAuthentication{
+		log:     log,
+		secrets: secrets,
+		cfg:     cfg,
+		client:  &http.Client{Timeout: oidcTimeout},
+		keys:    map[string]*rsa.PublicKey{},
+	}, nil
+}
+
+// Handle the authentication check.
+// The session is taken from the session cookie or (for API clients) from the bearer token.
+func (o *oidcAuthentication) checkAuthentication(c *gin.Context) {
+	session, ok := o.getSession(c)
+	if !ok {
+		sendError(c, errors.WithStack(errors.Wrap(UnauthorizedError, "missing or expired session")))
+		c.Abort()
+		return
+	}
+	c.Set(usernameKey, session.Subject)
+
+	switch session.Role {
+	case roleAdmin:
+	case roleReadOnly:
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			sendError(c, errors.WithStack(errors.Wrap(ForbiddenError, "read-only access")))
+			c.Abort()
+			return
+		}
+	default:
+		sendError(c, errors.WithStack(errors.Wrapf(ForbiddenError, "unknown role '%s'", session.Role)))
+		c.Abort()
+		return
+	}
+}
+
+// Handle a POST /login request.
+// Password logins are not possible, but the token of an existing session is returned,
+// so the dashboard can use it as bearer token.
+func (o *oidcAuthentication) handleLogin(c *gin.Context) {
+	token, err := c.Cookie(oidcSessionCookie)
+	if err != nil || token == "" {
+		sendError(c, errors.WithStack(errors.Wrapf(UnauthorizedError, "login using %s", OIDCLoginPath)))
+		return
+	}
+	var session oidcSessionClaims
+	if err := o.parse(c.Request.Context(), token, oidcSessionAudience, &session); err != nil {
+		sendError(c, errors.WithStack(errors.Wrapf(UnauthorizedError, "session expired, login using %s", OIDCLoginPath)))
+		return
+	}
+	c.JSON(http.StatusOK, loginResponse{
+		Token: token,
+	})
+}
+
+// Handle a GET /login/oidc request by redirecting to the authorization endpoint of the provider.
+func (o *oidcAuthentication) handleAuthorize(c *gin.Context) {
+	config, err := o.oauth2Config(c.Request.Context())
+	if err != nil {
+		o.log.Warn().Err(err).Msg("Failed to prepare OIDC login")
+		sendError(c, errors.WithStack(errors.Wrap(UnauthorizedError, "identity provider is not available")))
+		return
+	}
+
+	state := uniuri.NewLen(32)
+	nonce := uniuri.NewLen(32)
+	signed, err := o.sign(c.Request.Context(), &oidcStateClaims{
+		StandardClaims: jg.StandardClaims{
+			Id:        state,
+			Audience:  oidcStateAudience,
+			ExpiresAt: time.Now().Add(oidcStateTime).Unix(),
+		},
+		Nonce: nonce,
+	})
+	if err != nil {
+		sendError(c, err)
+		return
+	}
+	o.setCookie(c, oidcStateCookie, signed, OIDCLoginPath, oidcStateTime)
+
+	c.Redirect(http.StatusFound, config.AuthCodeURL(state, oauth2.SetAuthURLParam("nonce", nonce)))
+}
+
+// Handle a GET /login/oidc/callback request.
+// The authorization code is exchanged for an ID token, which is verified and turned into a session.
+func (o *oidcAuthentication) handleCallback(c *gin.Context) {
+	if e := c.Query("error"); e != "" {
+		sendError(c, errors.WithStack(errors.Wrapf(UnauthorizedError, "login failed: %s %s", e, c.Query("error_description"))))
+		return
+	}
+
+	var state oidcStateClaims
+	if raw, err := c.Cookie(oidcStateCookie); err != nil {
+		sendError(c, errors.WithStack(errors.Wrap(BadRequestError, "missing login state")))
+		return
+	} else if err := o.parse(c.Request.Context(), raw, oidcStateAudience, &state); err != nil {
+		sendError(c, errors.WithStack(errors.Wrap(BadRequestError, "invalid login state")))
+		return
+	}
+	o.setCookie(c, oidcStateCookie, "", OIDCLoginPath, -1)
+	if subtle.ConstantTimeCompare([]byte(state.Id), []byte(c.Query("state"))) != 1 {
+		sendError(c, errors.WithStack(errors.Wrap(BadRequestError, "login state mismatch")))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), oidcTimeout)
+	defer cancel()
+
+	config, err := o.oauth2Config(ctx)
+	if err != nil {
+		o.log.Warn().Err(err).Msg("Failed to prepare OIDC login")
+		sendError(c, errors.WithStack(errors.Wrap(UnauthorizedError, "identity provider is not available")))
+		return
+	}
+	token, err := config.Exchange(context.WithValue(ctx, oauth2.HTTPClient, o.client), c.Query("code"))
+	if err != nil {
+		o.log.Warn().Err(err).Msg("Failed to exchange OIDC authorization code")
+		sendError(c, errors.WithStack(errors.Wrap(UnauthorizedError, "invalid authorization code")))
+		return
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		sendError(c, errors.WithStack(errors.Wrap(UnauthorizedError, "missing ID token")))
+		return
+	}
+	claims, err := o.verifyIDToken(ctx, rawIDToken, state.Nonce)
+	if err != nil {
+		o.log.Warn().Err(err).Msg("Invalid OIDC ID token")
+		sendError(c, errors.WithStack(errors.Wrap(UnauthorizedError, "invalid ID token")))
+		return
+	}
+
+	username := claimString(claims, "preferred_username", "email", "sub")
+	role := o.role(claimStrings(claims, o.cfg.GroupsClaim))
+	if role == "" {
+		o.log.Info().Str("user", username).Msg("OIDC user is not a member of any dashboard group")
+		sendError(c, errors.WithStack(errors.Wrap(ForbiddenError, "user is not a member of any dashboard group")))
+		return
+	}
+
+	session, err := o.sign(ctx, &oidcSessionClaims{
+		StandardClaims: jg.StandardClaims{
+			Subject:   username,
+			Audience:  oidcSessionAudience,
+			IssuedAt:  time.Now().Unix(),
+			ExpiresAt: time.Now().Add(oidcSessionTime).Unix(),
+		},
+		Role: role,
+	})
+	if err != nil {
+		sendError(c, err)
+		return
+	}
+	o.setCookie(c, oidcSessionCookie, session, "/", oidcSessionTime)
+	o.log.Info().Str("user", username).Str("role", role).Msg("OIDC user logged in")
+
+	c.Redirect(http.StatusFound, "/")
+}
+
+// Handle a POST /logout request by removing the session cookie.
+func (o *oidcAuthentication) handleLogout(c *gin.Context) {
+	o.setCookie(c, oidcSessionCookie, "", "/", -1)
+	c.Status(http.StatusOK)
+}
+
+// getSession returns the valid session of the request (if any).
+func (o *oidcAuthentication) getSession(c *gin.Context) (oidcSessionClaims, bool) {
+	raw, err := c.Cookie(oidcSessionCookie)
+	if err != nil {
+		if token, ok := getBearerToken(c); ok {
+			raw = token
+		}
+	}
+	if raw == "" {
+		return oidcSessionClaims{}, false
+	}
+	var session oidcSessionClaims
+	if err := o.parse(c.Request.Context(), raw, oidcSessionAudience, &session); err != nil {
+		o.log.Debug().Err(err).Msg("Invalid session")
+		return oidcSessionClaims{}, false
+	}
+	return session, true
+}
+
+// role returns the role granted to the given groups, admin taking precedence over read-only.
+func (o *oidcAuthentication) role(groups []string) string {
+	role := ""
+	for _, group := range groups {
+		for _, g := range o.cfg.AdminGroups {
+			if g == group {
+				return roleAdmin
+			}
+		}
+		for _, g := range o.cfg.ReadOnlyGroups {
+			if g == group {
+				role = roleReadOnly
+			}
+		}
+	}
+	return role
+}
+
+// verifyIDToken verifies the signature and the standard claims of the given ID token.
+func (o *oidcAuthentication) verifyIDToken(ctx context.Context, raw, nonce string) (jg.MapClaims, error) {
+	provider, err := o.getProvider(ctx)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	claims := jg.MapClaims{}
+	if _, err := jg.ParseWithClaims(raw, claims, func(token *jg.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jg.SigningMethodRSA); !ok {
+			return nil, errors.Newf("Unsupported signing method %v", token.Header["alg"])
+		}
+		kid, _ := token.Header["kid"].(string)
+		return o.getKey(ctx, kid)
+	}); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if !claims.VerifyIssuer(provider.Issuer, true) {
+		return nil, errors.WithStack(errors.Newf("Unexpected issuer %v", claims["iss"]))
+	}
+	if !claims.VerifyAudience(o.cfg.ClientID, true) {
+		return nil, errors.WithStack(errors.Newf("Unexpected audience %v", claims["aud"]))
+	}
+	if !claims.VerifyExpiresAt(time.Now().Unix(), true) {
+		return nil, errors.WithStack(errors.Newf("ID token expired"))
+	}
+	if n, _ := claims["nonce"].(string); subtle.ConstantTimeCompare([]byte(n), []byte(nonce)) != 1 {
+		return nil, errors.WithStack(errors.Newf("Nonce mismatch"))
+	}
+	return claims, nil
+}
+
+// oauth2Config returns the OAuth2 configuration of the provider.
+func (o *oidcAuthentication) oauth2Config(ctx context.Context) (*oauth2.Config, error) {
+	provider, err := o.getProvider(ctx)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	secret, err := o.getClientSecret(ctx)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &oauth2.Config{
+		ClientID:     o.cfg.ClientID,
+		ClientSecret: secret,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  provider.AuthorizationEndpoint,
+			TokenURL: provider.TokenEndpoint,
+		},
+		RedirectURL: o.cfg.RedirectURL,
+		Scopes:      append([]string{"openid"}, o.cfg.Scopes...),
+	}, nil
+}
+
+// getProvider returns the discovery document of the issuer, fetching it if needed.
+func (o *oidcAuthentication) getProvider(ctx context.Context) (*oidcProvider, error) {
+	o.lock.Lock()
+	provider := o.provider
+	o.lock.Unlock()
+	if provider != nil {
+		return provider, nil
+	}
+
+	var p oidcProvider
+	if err := o.getJSON(ctx, strings.TrimSuffix(o.cfg.IssuerURL, "/")+"/.well-known/openid-configuration", &p); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if strings.TrimSuffix(p.Issuer, "/") != strings.TrimSuffix(o.cfg.IssuerURL, "/") {
+		return nil, errors.WithStack(errors.Newf("Issuer '%s' does not match configured issuer '%s'", p.Issuer, o.cfg.IssuerURL))
+	}
+	if p.AuthorizationEndpoint == "" || p.TokenEndpoint == "" || p.JWKSURI == "" {
+		return nil, errors.WithStack(errors.Newf("Incomplete discovery document of issuer '%s'", p.Issuer))
+	}
+
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	o.provider = &p
+	return o.provider, nil
+}
+
+// getKey returns the RSA key with the given ID. The key set is refetched when the key is unknown.
+func (o *oidcAuthentication) getKey(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	o.lock.Lock()
+	key, ok := o.keys[kid]
+	o.lock.Unlock()
+	if ok {
+		return key, nil
+	}
+
+	provider, err := o.getProvider(ctx)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := o.getJSON(ctx, provider.JWKSURI, &set); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	keys := map[string]*rsa.PublicKey{}
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+		publicKey, err := k.rsaPublicKey()
+		if err != nil {
+			o.log.Warn().Err(err).Str("kid", k.Kid).Msg("Ignoring invalid key")
+			continue
+		}
+		keys[k.Kid] = publicKey
+	}
+
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	o.keys = keys
+	if key, ok := keys[kid]; ok {
+		return key, nil
+	}
+	return nil, errors.WithStack(errors.Newf("Unknown key '%s'", kid))
+}
+
+// getClientSecret returns the client secret, loading it from the configured Secret if needed.
+func (o *oidcAuthentication) getClientSecret(ctx context.Context) (string, error) {
+	if o.cfg.ClientSecretName == "" {
+		return "", nil
+	}
+	o.lock.Lock()
+	secret := o.clientSecret
+	o.lock.Unlock()
+	if secret != "" {
+		return secret, nil
+	}
+
+	s, err := o.secrets.Get(ctx, o.cfg.ClientSecretName, metav1.GetOptions{})
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	raw, found := s.Data[OIDCClientSecretKey]
+	if !found {
+		return "", errors.WithStack(errors.Newf("Secret '%s' contains no '%s' field", o.cfg.ClientSecretName, OIDCClientSecretKey))
+	}
+
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	o.clientSecret = string(raw)
+	return o.clientSecret, nil
+}
+
+// getSigningKey returns the key signing the sessions, loading it from the configured Secret if needed.
+// The Secret is created with a random key if it does not exist yet.
+func (o *oidcAuthentication) getSigningKey(ctx context.Context) ([]byte, error) {
+	o.lock.Lock()
+	key := o.signingKey
+	o.lock.Unlock()
+	if key != nil {
+		return key, nil
+	}
+
+	s, err := o.secrets.Get(ctx, o.cfg.SessionSecretName, metav1.GetOptions{})
+	if apiErrors.IsNotFound(err) {
+		random := make([]byte, 32)
+		if _, err := rand.Read(random); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		s, err = o.secrets.Create(ctx, &core.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: o.cfg.SessionSecretName},
+			Data:       map[string][]byte{OIDCSessionKeyKey: []byte(hex.EncodeToString(random))},
+		}, metav1.CreateOptions{})
+		if apiErrors.IsAlreadyExists(err) {
+			// Created by another operator in the meantime
+			s, err = o.secrets.Get(ctx, o.cfg.SessionSecretName, metav1.GetOptions{})
+		}
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	raw, found := s.Data[OIDCSessionKeyKey]
+	if !found || len(raw) == 0 {
+		return nil, errors.WithStack(errors.Newf("Secret '%s' contains no '%s' field", o.cfg.SessionSecretName, OIDCSessionKeyKey))
+	}
+
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	o.signingKey = raw
+	return o.signingKey, nil
+}
+
+func (o *oidcAuthentication) getJSON(ctx context.Context, url string, result interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.WithStack(errors.Newf("Unexpected status %d from %s", resp.StatusCode, url))
+	}
+	return errors.WithStack(json.NewDecoder(resp.Body).Decode(result))
+}
+
+// sign returns the given claims signed with the key of the server.
+func (o *oidcAuthentication) sign(ctx context.Context, claims oidcClaims) (string, error) {
+	key, err := o.getSigningKey(ctx)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	token, err := jg.NewWithClaims(jg.SigningMethodHS256, claims).SignedString(key)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return token, nil
+}
+
+// parse verifies the given token signed by the server and decodes its claims.
+// Tokens issued for another audience (e.g. a login state used as session) are rejected.
+func (o *oidcAuthentication) parse(ctx context.Context, raw, audience string, claims oidcClaims) error {
+	key, err := o.getSigningKey(ctx)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if _, err := jg.ParseWithClaims(raw, claims, func(token *jg.Token) (interface{}, error) {
+		if token.Method != jg.SigningMethodHS256 {
+			return nil, errors.Newf("Unexpected signing method %v", token.Header["alg"])
+		}
+		return key, nil
+	}); err != nil {
+		return errors.WithStack(err)
+	}
+	if !claims.VerifyAudience(audience, true) {
+		return errors.WithStack(errors.Newf("Unexpected audience"))
+	}
+	return nil
+}
+
+func (o *oidcAuthentication) setCookie(c *gin.Context, name, value, path string, maxAge time.Duration) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		MaxAge:   int(maxAge.Seconds()),
+		Secure:   true,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// rsaPublicKey decodes the modulus & exponent of the key.
+func (k jsonWebKey) rsaPublicKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	exponent := new(big.Int).SetBytes(e)
+	if !exponent.IsInt64() || exponent.Int64() < 3 {
+		return nil, errors.WithStack(errors.Newf("Invalid exponent"))
+	}
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(exponent.Int64()),
+	}, nil
+}
+
+// claimString returns the first non empty string claim of the given names.
+func claimString(claims jg.MapClaims, names ...string) string {
+	for _, name := range names {
+		if v, ok := claims[name].(string); ok && v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// claimStrings returns the given claim as list of strings.
+func claimStrings(claims jg.MapClaims, name string) []string {
+	switch v := claims[name].(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		result := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+	return nil
+}
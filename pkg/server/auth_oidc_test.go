@@ -0,0 +1,336 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	jg "github.com/golang-jwt/jwt"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// testOIDCProvider is a minimal OpenID Connect provider issuing ID tokens for a single authorization code.
+type testOIDCProvider struct {
+	*httptest.Server
+	key    *rsa.PrivateKey
+	nonce  string
+	groups []string
+}
+
+func newTestOIDCProvider(t *testing.T) *testOIDCProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	p := &testOIDCProvider{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(oidcProvider{
+			Issuer:                p.URL,
+			AuthorizationEndpoint: p.URL + "/auth",
+			TokenEndpoint:         p.URL + "/token",
+			JWKSURI:               p.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []jsonWebKey{{
+				Kty: "RSA",
+				Kid: "test",
+				Use: "sig",
+				N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		user, secret, _ := r.BasicAuth()
+		if r.FormValue("code") != "test-code" || user != "dashboard" || secret != "client-secret" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		token := jg.NewWithClaims(jg.SigningMethodRS256, jg.MapClaims{
+			"iss":                p.URL,
+			"aud":                "dashboard",
+			"sub":                "1234",
+			"preferred_username": "alice",
+			"exp":                time.Now().Add(time.Minute).Unix(),
+			"nonce":              p.nonce,
+			"groups":             p.groups,
+		})
+		token.Header["kid"] = "test"
+		idToken, err := token.SignedString(key)
+		require.NoError(t, err)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "access",
+			"token_type":   "Bearer",
+			"expires_in":   60,
+			"id_token":     idToken,
+		})
+	})
+	p.Server = httptest.NewServer(mux)
+	return p
+}
+
+func newTestOIDCAuthentication(t *testing.T, issuer string) *gin.Engine {
+	client := fake.NewSimpleClientset(&core.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "oidc", Namespace: "ns"},
+		Data:       map[string][]byte{OIDCClientSecretKey: []byte("client-secret")},
+	})
+	return newTestOIDCRouter(newTestOIDCAuth(t, client, issuer))
+}
+
+func newTestOIDCAuth(t *testing.T, client kubernetes.Interface, issuer string) *oidcAuthentication {
+	auth, err := newOIDCAuthentication(zerolog.Nop(), client.CoreV1().Secrets("ns"), OIDCConfig{
+		IssuerURL:        issuer,
+		ClientID:         "dashboard",
+		ClientSecretName: "oidc",
+		RedirectURL:      "https://operator" + OIDCCallbackPath,
+		AdminGroups:      []string{"admins"},
+		ReadOnlyGroups:   []string{"viewers"},
+	})
+	require.NoError(t, err)
+	return auth
+}
+
+func newTestOIDCRouter(auth *oidcAuthentication) *gin.Engine {
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.POST("/login", auth.handleLogin)
+	r.GET(OIDCLoginPath, auth.handleAuthorize)
+	r.GET(OIDCCallbackPath, auth.handleCallback)
+	api := r.Group("/api", auth.checkAuthentication)
+	ok := func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"user": getUsername(c)})
+	}
+	api.GET("/deployment/:name", ok)
+	api.POST("/deployment/:name/scale", ok)
+	return r
+}
+
+// oidcLogin runs the authorization code flow and returns the response of the callback.
+func oidcLogin(t *testing.T, r http.Handler, provider *testOIDCProvider, groups ...string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, OIDCLoginPath, nil))
+	require.Equal(t, http.StatusFound, w.Code)
+
+	location, err := url.Parse(w.Header().Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, provider.URL+"/auth", location.Scheme+"://"+location.Host+location.Path)
+	assert.Equal(t, "dashboard", location.Query().Get("client_id"))
+	assert.Equal(t, "https://operator"+OIDCCallbackPath, location.Query().Get("redirect_uri"))
+	provider.nonce = location.Query().Get("nonce")
+	provider.groups = groups
+
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.True(t, cookies[0].HttpOnly)
+	assert.True(t, cookies[0].Secure)
+
+	req := httptest.NewRequest(http.MethodGet, OIDCCallbackPath+"?code=test-code&state="+location.Query().Get("state"), nil)
+	req.AddCookie(cookies[0])
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func sessionCookie(w *httptest.ResponseRecorder) *http.Cookie {
+	for _, c := range w.Result().Cookies() {
+		if c.Name == oidcSessionCookie {
+			return c
+		}
+	}
+	return nil
+}
+
+func doSessionRequest(r http.Handler, method, path string, cookie *http.Cookie) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, nil)
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestOIDCAuthentication_Admin(t *testing.T) {
+	provider := newTestOIDCProvider(t)
+	defer provider.Close()
+	r := newTestOIDCAuthentication(t, provider.URL)
+
+	assert.Equal(t, http.StatusUnauthorized, doSessionRequest(r, http.MethodGet, "/api/deployment/test", nil).Code)
+
+	w := oidcLogin(t, r, provider, "dev", "admins")
+	require.Equal(t, http.StatusFound, w.Code)
+	assert.Equal(t, "/", w.Header().Get("Location"))
+	session := sessionCookie(w)
+	require.NotNil(t, session)
+
+	w = doSessionRequest(r, http.MethodGet, "/api/deployment/test", session)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"user":"alice"}`, w.Body.String())
+	assert.Equal(t, http.StatusOK, doSessionRequest(r, http.MethodPost, "/api/deployment/test/scale", session).Code)
+
+	// The session can be used as bearer token
+	w = doSessionRequest(r, http.MethodPost, "/login", session)
+	require.Equal(t, http.StatusOK, w.Code)
+	var login loginResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &login))
+	assert.Equal(t, http.StatusOK, doAuthRequest(r, http.MethodGet, "/api/deployment/test", login.Token))
+
+	// Tampered sessions are rejected
+	session.Value += "x"
+	assert.Equal(t, http.StatusUnauthorized, doSessionRequest(r, http.MethodGet, "/api/deployment/test", session).Code)
+}
+
+func TestOIDCAuthentication_Roles(t *testing.T) {
+	provider := newTestOIDCProvider(t)
+	defer provider.Close()
+	r := newTestOIDCAuthentication(t, provider.URL)
+
+	w := oidcLogin(t, r, provider, "viewers")
+	require.Equal(t, http.StatusFound, w.Code)
+	session := sessionCookie(w)
+	require.NotNil(t, session)
+	assert.Equal(t, http.StatusOK, doSessionRequest(r, http.MethodGet, "/api/deployment/test", session).Code)
+	assert.Equal(t, http.StatusForbidden, doSessionRequest(r, http.MethodPost, "/api/deployment/test/scale", session).Code)
+
+	w = oidcLogin(t, r, provider, "dev")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Nil(t, sessionCookie(w))
+}
+
+func TestOIDCAuthentication_InvalidCallback(t *testing.T) {
+	provider := newTestOIDCProvider(t)
+	defer provider.Close()
+	r := newTestOIDCAuthentication(t, provider.URL)
+
+	// Missing state cookie
+	w := doSessionRequest(r, http.MethodGet, OIDCCallbackPath+"?code=test-code&state=abc", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Mismatching state
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, OIDCLoginPath, nil))
+	require.Equal(t, http.StatusFound, w.Code)
+	w = doSessionRequest(r, http.MethodGet, OIDCCallbackPath+"?code=test-code&state=abc", w.Result().Cookies()[0])
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Nonce mismatch
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, OIDCLoginPath, nil))
+	location, err := url.Parse(w.Header().Get("Location"))
+	require.NoError(t, err)
+	provider.nonce = "other"
+	provider.groups = []string{"admins"}
+	w = doSessionRequest(r, http.MethodGet, OIDCCallbackPath+"?code=test-code&state="+location.Query().Get("state"), w.Result().Cookies()[0])
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Nil(t, sessionCookie(w))
+}
+
+func TestOIDCAuthentication_StateReplay(t *testing.T) {
+	provider := newTestOIDCProvider(t)
+	defer provider.Close()
+	r := newTestOIDCAuthentication(t, provider.URL)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, OIDCLoginPath, nil))
+	require.Equal(t, http.StatusFound, w.Code)
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+	require.Equal(t, oidcStateCookie, cookies[0].Name)
+	state := cookies[0].Value
+
+	// The login state is signed by the server, but it is not a session
+	assert.Equal(t, http.StatusUnauthorized, doAuthRequest(r, http.MethodGet, "/api/deployment/test", state))
+	assert.Equal(t, http.StatusUnauthorized, doAuthRequest(r, http.MethodPost, "/api/deployment/test/scale", state))
+	assert.Equal(t, http.StatusUnauthorized, doSessionRequest(r, http.MethodGet, "/api/deployment/test", &http.Cookie{Name: oidcSessionCookie, Value: state}).Code)
+	assert.Equal(t, http.StatusUnauthorized, doSessionRequest(r, http.MethodPost, "/login", &http.Cookie{Name: oidcSessionCookie, Value: state}).Code)
+}
+
+func TestOIDCAuthentication_UnknownRole(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	auth := newTestOIDCAuth(t, client, "https://issuer")
+	r := newTestOIDCRouter(auth)
+
+	newSession := func(role string) *http.Cookie {
+		token, err := auth.sign(context.Background(), &oidcSessionClaims{
+			StandardClaims: jg.StandardClaims{
+				Subject:   "alice",
+				Audience:  oidcSessionAudience,
+				ExpiresAt: time.Now().Add(time.Minute).Unix(),
+			},
+			Role: role,
+		})
+		require.NoError(t, err)
+		return &http.Cookie{Name: oidcSessionCookie, Value: token}
+	}
+
+	assert.Equal(t, http.StatusOK, doSessionRequest(r, http.MethodGet, "/api/deployment/test", newSession(roleReadOnly)).Code)
+	assert.Equal(t, http.StatusForbidden, doSessionRequest(r, http.MethodGet, "/api/deployment/test", newSession("")).Code)
+	assert.Equal(t, http.StatusForbidden, doSessionRequest(r, http.MethodGet, "/api/deployment/test", newSession("other")).Code)
+	assert.Equal(t, http.StatusForbidden, doSessionRequest(r, http.MethodPost, "/api/deployment/test/scale", newSession("other")).Code)
+}
+
+func TestOIDCAuthentication_SigningKeySecret(t *testing.T) {
+	provider := newTestOIDCProvider(t)
+	defer provider.Close()
+	client := fake.NewSimpleClientset(&core.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "oidc", Namespace: "ns"},
+		Data:       map[string][]byte{OIDCClientSecretKey: []byte("client-secret")},
+	})
+	r := newTestOIDCRouter(newTestOIDCAuth(t, client, provider.URL))
+
+	w := oidcLogin(t, r, provider, "admins")
+	require.Equal(t, http.StatusFound, w.Code)
+	session := sessionCookie(w)
+	require.NotNil(t, session)
+
+	secret, err := client.CoreV1().Secrets("ns").Get(context.Background(), DefaultOIDCSessionSecretName, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, secret.Data[OIDCSessionKeyKey])
+
+	// Sessions stay valid for a restarted operator
+	restarted := newTestOIDCRouter(newTestOIDCAuth(t, client, provider.URL))
+	assert.Equal(t, http.StatusOK, doSessionRequest(restarted, http.MethodGet, "/api/deployment/test", session).Code)
+
+	// But not for an operator using another key
+	other := newTestOIDCRouter(newTestOIDCAuth(t, fake.NewSimpleClientset(), provider.URL))
+	assert.Equal(t, http.StatusUnauthorized, doSessionRequest(other, http.MethodGet, "/api/deployment/test", session).Code)
+}
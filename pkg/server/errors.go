@@ -33,6 +33,9 @@ import (
 var (
 	NotFoundError     = errors.New("not found")
 	UnauthorizedError = errors.New("unauthorized")
+	BadRequestError   = errors.New("bad request")
+	ForbiddenError    = errors.New("forbidden")
+	UnavailableError  = errors.New("unavailable")
 )
 
 func isNotFound(err error) bool {
@@ -43,6 +46,18 @@ func isUnauthorized(err error) bool {
 	return err == UnauthorizedError || errors.Cause(err) == UnauthorizedError
 }
 
+func isForbidden(err error) bool {
+	return err == ForbiddenError || errors.Cause(err) == ForbiddenError
+}
+
+func isUnavailable(err error) bool {
+	return err == UnavailableError || errors.Cause(err) == UnavailableError
+}
+
+func isBadRequest(err error) bool {
+	return err == BadRequestError || errors.Cause(err) == BadRequestError
+}
+
 // sendError sends an error on the given context
 func sendError(c *gin.Context, err error) {
 	// TODO proper status handling
@@ -51,6 +66,12 @@ func sendError(c *gin.Context, err error) {
 		code = http.StatusNotFound
 	} else if isUnauthorized(err) {
 		code = http.StatusUnauthorized
+	} else if isForbidden(err) {
+		code = http.StatusForbidden
+	} else if isBadRequest(err) {
+		code = http.StatusBadRequest
+	} else if isUnavailable(err) {
+		code = http.StatusServiceUnavailable
 	}
 	c.JSON(code, gin.H{
 		"error": err.Error(),
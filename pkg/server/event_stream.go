@@ -0,0 +1,153 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultEventStreamHistory is the number of recent events kept by an EventStream
+	DefaultEventStreamHistory = 256
+	// eventStreamSubscriberBuffer is the number of pending events of a subscriber before it is dropped
+	eventStreamSubscriberBuffer = 64
+)
+
+// DeploymentEventType is a strongly typed type of a deployment stream event
+type DeploymentEventType string
+
+const (
+	// DeploymentEventPhase is sent when the phase of the deployment changes
+	DeploymentEventPhase DeploymentEventType = "phase"
+	// DeploymentEventMemberCondition is sent when a condition of a member changes its status
+	DeploymentEventMemberCondition DeploymentEventType = "member-condition"
+	// DeploymentEventActionStarted is sent when a plan action is started
+	DeploymentEventActionStarted DeploymentEventType = "action-started"
+	// DeploymentEventActionFinished is sent when a plan action is finished
+	DeploymentEventActionFinished DeploymentEventType = "action-finished"
+	// DeploymentEventKubernetes is sent for every Kubernetes event recorded for the deployment
+	DeploymentEventKubernetes DeploymentEventType = "kubernetes-event"
+)
+
+// DeploymentEvent is a single event of the live event stream of a deployment.
+type DeploymentEvent struct {
+	ID   uint64              `json:"id"`
+	Type DeploymentEventType `json:"type"`
+	Time time.Time           `json:"time"`
+
+	// Phase of the deployment (phase)
+	Phase string `json:"phase,omitempty"`
+	// Member involved in the event (member-condition, action-started, action-finished)
+	Group    string `json:"group,omitempty"`
+	MemberID string `json:"member_id,omitempty"`
+	// Condition which changed its status (member-condition)
+	Condition string `json:"condition,omitempty"`
+	Status    string `json:"status,omitempty"`
+	// Plan action (action-started, action-finished)
+	ActionID   string `json:"action_id,omitempty"`
+	ActionType string `json:"action_type,omitempty"`
+	Result     string `json:"result,omitempty"`
+	// Type of the Kubernetes event (Normal|Warning)
+	EventType string `json:"event_type,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+	Message   string `json:"message,omitempty"`
+}
+
+// EventStream keeps the recent events of a deployment and distributes new events to its subscribers.
+type EventStream struct {
+	lock        sync.Mutex
+	lastID      uint64
+	size        int
+	history     []DeploymentEvent
+	subscribers map[chan DeploymentEvent]struct{}
+}
+
+// NewEventStream creates a new event stream keeping the given number of recent events.
+func NewEventStream(size int) *EventStream {
+	return &EventStream{
+		size:        size,
+		subscribers: map[chan DeploymentEvent]struct{}{},
+	}
+}
+
+// Publish assigns an ID to the given event and sends it to all subscribers.
+// Subscribers which do not keep up are dropped (their channel is closed),
+// they can subscribe again with the ID of the last received event.
+func (s *EventStream) Publish(ev DeploymentEvent) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.lastID++
+	ev.ID = s.lastID
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+
+	s.history = append(s.history, ev)
+	if len(s.history) > s.size {
+		s.history = s.history[len(s.history)-s.size:]
+	}
+
+	for ch := range s.subscribers {
+		select {
+		case ch <- ev:
+		default:
+			delete(s.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// Subscribe returns a channel receiving all events with an ID higher than the given one,
+// starting with the recent events still kept by the stream.
+// The channel is closed when the given context is done or the subscriber is dropped.
+func (s *EventStream) Subscribe(ctx context.Context, lastID uint64) <-chan DeploymentEvent {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var replay []DeploymentEvent
+	for _, ev := range s.history {
+		if ev.ID > lastID {
+			replay = append(replay, ev)
+		}
+	}
+
+	ch := make(chan DeploymentEvent, len(replay)+eventStreamSubscriberBuffer)
+	for _, ev := range replay {
+		ch <- ev
+	}
+	s.subscribers[ch] = struct{}{}
+
+	go func() {
+		<-ctx.Done()
+
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		if _, ok := s.subscribers[ch]; ok {
+			delete(s.subscribers, ch)
+			close(ch)
+		}
+	}()
+
+	return ch
+}
@@ -0,0 +1,125 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func receiveEvents(ch <-chan DeploymentEvent) []uint64 {
+	var ids []uint64
+	for {
+		select {
+		case ev, ok := <-ch:
+			if !ok {
+				return ids
+			}
+			ids = append(ids, ev.ID)
+		default:
+			return ids
+		}
+	}
+}
+
+func TestEventStream(t *testing.T) {
+	s := NewEventStream(3)
+	for i := 0; i < 5; i++ {
+		s.Publish(DeploymentEvent{Type: DeploymentEventPhase})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Only the recent events are replayed
+	ch := s.Subscribe(ctx, 0)
+	assert.Equal(t, []uint64{3, 4, 5}, receiveEvents(ch))
+
+	s.Publish(DeploymentEvent{Type: DeploymentEventPhase})
+	assert.Equal(t, []uint64{6}, receiveEvents(ch))
+
+	// Resume after the last received event
+	resumed := s.Subscribe(ctx, 5)
+	assert.Equal(t, []uint64{6}, receiveEvents(resumed))
+
+	cancel()
+	require.Eventually(t, func() bool {
+		_, ok := <-ch
+		return !ok
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestEventStream_SlowSubscriber(t *testing.T) {
+	s := NewEventStream(DefaultEventStreamHistory)
+	ch := s.Subscribe(context.Background(), 0)
+
+	for i := 0; i <= eventStreamSubscriberBuffer; i++ {
+		s.Publish(DeploymentEvent{Type: DeploymentEventKubernetes})
+	}
+
+	// The subscriber is dropped once its buffer is full
+	ids := receiveEvents(ch)
+	assert.Len(t, ids, eventStreamSubscriberBuffer)
+	_, ok := <-ch
+	assert.False(t, ok)
+
+	// and can continue where it stopped
+	assert.Equal(t, []uint64{eventStreamSubscriberBuffer + 1}, receiveEvents(s.Subscribe(context.Background(), ids[len(ids)-1])))
+}
+
+func TestHandleGetDeploymentEvents(t *testing.T) {
+	d := &testDeployment{events: NewEventStream(DefaultEventStreamHistory)}
+	d.events.Publish(DeploymentEvent{Type: DeploymentEventPhase, Phase: "Running"})
+	d.events.Publish(DeploymentEvent{Type: DeploymentEventActionStarted, ActionType: "RotateMember", MemberID: "PRMR-1"})
+
+	s := &Server{
+		deps: Dependencies{
+			Log:       zerolog.Nop(),
+			Operators: testOperators{deployment: d},
+		},
+	}
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.GET("/api/deployment/:name/events", s.handleGetDeploymentEvents)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/deployment/test/events", nil).WithContext(ctx)
+	req.Header.Set(lastEventIDHeader, "1")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+	assert.NotContains(t, w.Body.String(), "id: 1\n")
+	assert.Contains(t, w.Body.String(), "id: 2\nevent: action-started\ndata: {\"id\":2,\"type\":\"action-started\"")
+	assert.Contains(t, w.Body.String(), `"member_id":"PRMR-1"`)
+
+	assert.Equal(t, http.StatusBadRequest, doRequest(r, http.MethodGet, "/api/deployment/test/events?last_event_id=abc", "").Code)
+	assert.Equal(t, http.StatusNotFound, doRequest(r, http.MethodGet, "/api/deployment/other/events", "").Code)
+}
@@ -0,0 +1,350 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package server
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	backupApi "github.com/arangodb/kube-arangodb/pkg/apis/backup/v1"
+	"github.com/arangodb/kube-arangodb/pkg/util/errors"
+)
+
+// BackupOperator is the API implemented by the backup operator.
+type BackupOperator interface {
+	// GetBackups returns all ArangoBackups managed by the operator
+	GetBackups() ([]*backupApi.ArangoBackup, error)
+	// GetBackup returns the ArangoBackup, managed by the operator, with given name
+	GetBackup(name string) (*backupApi.ArangoBackup, error)
+	// GetBackupPolicies returns all ArangoBackupPolicies managed by the operator
+	GetBackupPolicies() ([]*backupApi.ArangoBackupPolicy, error)
+	// GetBackupPolicy returns the ArangoBackupPolicy, managed by the operator, with given name
+	GetBackupPolicy(name string) (*backupApi.ArangoBackupPolicy, error)
+}
+
+// BackupOperationStatus is a strongly typed status of an upload or download of a backup
+type BackupOperationStatus string
+
+const (
+	BackupOperationPending BackupOperationStatus = "pending"
+	BackupOperationRunning BackupOperationStatus = "running"
+	BackupOperationFailed  BackupOperationStatus = "failed"
+	BackupOperationDone    BackupOperationStatus = "done"
+)
+
+// BackupInfo is the information returned per ArangoBackup.
+type BackupInfo struct {
+	Name           string                `json:"name"`
+	Namespace      string                `json:"namespace"`
+	Deployment     string                `json:"deployment"`
+	Policy         string                `json:"policy,omitempty"`
+	State          string                `json:"state"`
+	Message        string                `json:"message,omitempty"`
+	Progress       string                `json:"progress,omitempty"`
+	Available      bool                  `json:"available"`
+	BackupID       string                `json:"backup_id,omitempty"`
+	Version        string                `json:"version,omitempty"`
+	Size           uint64                `json:"size,omitempty"`
+	Uploaded       bool                  `json:"uploaded"`
+	UploadStatus   BackupOperationStatus `json:"upload_status,omitempty"`
+	DownloadStatus BackupOperationStatus `json:"download_status,omitempty"`
+	CreatedAt      *time.Time            `json:"created_at,omitempty"`
+}
+
+// newBackupInfo initializes a BackupInfo for the given ArangoBackup.
+func newBackupInfo(b *backupApi.ArangoBackup) BackupInfo {
+	result := BackupInfo{
+		Name:       b.GetName(),
+		Namespace:  b.GetNamespace(),
+		Deployment: b.Spec.Deployment.Name,
+		State:      string(b.Status.State),
+		Message:    b.Status.Message,
+		Available:  b.Status.Available,
+	}
+	if b.Spec.PolicyName != nil {
+		result.Policy = *b.Spec.PolicyName
+	}
+	if p := b.Status.Progress; p != nil {
+		result.Progress = p.Progress
+	}
+	if d := b.Status.Backup; d != nil {
+		result.BackupID = d.ID
+		result.Version = d.Version
+		result.Size = d.SizeInBytes
+		result.Uploaded = d.Uploaded != nil && *d.Uploaded
+		t := d.CreationTimestamp.Time
+		result.CreatedAt = &t
+	}
+	result.UploadStatus = backupUploadStatus(b)
+	result.DownloadStatus = backupDownloadStatus(b)
+	return result
+}
+
+// backupUploadStatus returns the status of the upload of the given backup (if any).
+func backupUploadStatus(b *backupApi.ArangoBackup) BackupOperationStatus {
+	switch b.Status.State {
+	case backupApi.ArangoBackupStateUpload:
+		return BackupOperationPending
+	case backupApi.ArangoBackupStateUploading:
+		return BackupOperationRunning
+	case backupApi.ArangoBackupStateUploadError:
+		return BackupOperationFailed
+	}
+	if d := b.Status.Backup; d != nil && d.Uploaded != nil && *d.Uploaded {
+		return BackupOperationDone
+	}
+	if b.Spec.Upload != nil {
+		return BackupOperationPending
+	}
+	return ""
+}
+
+// backupDownloadStatus returns the status of the download of the given backup (if any).
+func backupDownloadStatus(b *backupApi.ArangoBackup) BackupOperationStatus {
+	switch b.Status.State {
+	case backupApi.ArangoBackupStateDownload:
+		return BackupOperationPending
+	case backupApi.ArangoBackupStateDownloading:
+		return BackupOperationRunning
+	case backupApi.ArangoBackupStateDownloadError:
+		return BackupOperationFailed
+	}
+	if d := b.Status.Backup; d != nil && d.Downloaded != nil && *d.Downloaded {
+		return BackupOperationDone
+	}
+	if b.Spec.Download != nil {
+		return BackupOperationPending
+	}
+	return ""
+}
+
+// BackupRepositoryInfo describes the remote repository of an upload or download.
+type BackupRepositoryInfo struct {
+	RepositoryURL         string `json:"repository_url"`
+	CredentialsSecretName string `json:"credentials_secret_name,omitempty"`
+	BackupID              string `json:"backup_id,omitempty"`
+}
+
+// BackupInfoDetails contains detailed info about an ArangoBackup.
+type BackupInfoDetails struct {
+	BackupInfo
+	StateTime               *time.Time            `json:"state_time,omitempty"`
+	JobID                   string                `json:"job_id,omitempty"`
+	Upload                  *BackupRepositoryInfo `json:"upload,omitempty"`
+	Download                *BackupRepositoryInfo `json:"download,omitempty"`
+	Imported                bool                  `json:"imported"`
+	NumberOfDBServers       uint                  `json:"number_of_dbservers,omitempty"`
+	PotentiallyInconsistent bool                  `json:"potentially_inconsistent"`
+}
+
+// newBackupInfoDetails initializes a BackupInfoDetails for the given ArangoBackup.
+func newBackupInfoDetails(b *backupApi.ArangoBackup) BackupInfoDetails {
+	result := BackupInfoDetails{
+		BackupInfo: newBackupInfo(b),
+	}
+	if !b.Status.Time.IsZero() {
+		t := b.Status.Time.Time
+		result.StateTime = &t
+	}
+	if p := b.Status.Progress; p != nil {
+		result.JobID = p.JobID
+	}
+	if u := b.Spec.Upload; u != nil {
+		result.Upload = &BackupRepositoryInfo{
+			RepositoryURL:         u.RepositoryURL,
+			CredentialsSecretName: u.CredentialsSecretName,
+		}
+	}
+	if d := b.Spec.Download; d != nil {
+		result.Download = &BackupRepositoryInfo{
+			RepositoryURL:         d.RepositoryURL,
+			CredentialsSecretName: d.CredentialsSecretName,
+			BackupID:              d.ID,
+		}
+	}
+	if d := b.Status.Backup; d != nil {
+		result.Imported = d.Imported != nil && *d.Imported
+		result.NumberOfDBServers = d.NumberOfDBServers
+		result.PotentiallyInconsistent = d.PotentiallyInconsistent != nil && *d.PotentiallyInconsistent
+	}
+	return result
+}
+
+// BackupPolicyInfo is the information returned per ArangoBackupPolicy.
+type BackupPolicyInfo struct {
+	Name               string     `json:"name"`
+	Namespace          string     `json:"namespace"`
+	Schedule           string     `json:"schedule"`
+	NextSchedule       *time.Time `json:"next_schedule,omitempty"`
+	Message            string     `json:"message,omitempty"`
+	DeploymentSelector string     `json:"deployment_selector,omitempty"`
+	UploadRepository   string     `json:"upload_repository,omitempty"`
+	BackupCount        int        `json:"backup_count"`
+}
+
+// newBackupPolicyInfo initializes a BackupPolicyInfo for the given ArangoBackupPolicy
+// and the ArangoBackups created by it.
+func newBackupPolicyInfo(p *backupApi.ArangoBackupPolicy, backups []*backupApi.ArangoBackup) BackupPolicyInfo {
+	result := BackupPolicyInfo{
+		Name:        p.GetName(),
+		Namespace:   p.GetNamespace(),
+		Schedule:    p.Spec.Schedule,
+		Message:     p.Status.Message,
+		BackupCount: len(policyBackups(p, backups)),
+	}
+	if !p.Status.Scheduled.IsZero() {
+		t := p.Status.Scheduled.Time
+		result.NextSchedule = &t
+	}
+	if p.Spec.DeploymentSelector != nil {
+		result.DeploymentSelector = meta.FormatLabelSelector(p.Spec.DeploymentSelector)
+	}
+	if u := p.Spec.BackupTemplate.Upload; u != nil {
+		result.UploadRepository = u.RepositoryURL
+	}
+	return result
+}
+
+// BackupPolicyInfoDetails contains detailed info about an ArangoBackupPolicy.
+type BackupPolicyInfoDetails struct {
+	BackupPolicyInfo
+	Backups []BackupInfo `json:"backups"`
+}
+
+// newBackupPolicyInfoDetails initializes a BackupPolicyInfoDetails for the given ArangoBackupPolicy
+// and the ArangoBackups created by it.
+func newBackupPolicyInfoDetails(p *backupApi.ArangoBackupPolicy, backups []*backupApi.ArangoBackup) BackupPolicyInfoDetails {
+	owned := policyBackups(p, backups)
+	result := BackupPolicyInfoDetails{
+		BackupPolicyInfo: newBackupPolicyInfo(p, backups),
+		Backups:          make([]BackupInfo, len(owned)),
+	}
+	for i, b := range owned {
+		result.Backups[i] = newBackupInfo(b)
+	}
+	return result
+}
+
+// policyBackups returns the backups created by the given policy, newest first.
+func policyBackups(p *backupApi.ArangoBackupPolicy, backups []*backupApi.ArangoBackup) []*backupApi.ArangoBackup {
+	var result []*backupApi.ArangoBackup
+	for _, b := range backups {
+		if b.GetNamespace() == p.GetNamespace() && b.Spec.PolicyName != nil && *b.Spec.PolicyName == p.GetName() {
+			result = append(result, b)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[j].CreationTimestamp.Before(&result[i].CreationTimestamp)
+	})
+	return result
+}
+
+// getBackupOperator returns the backup operator or sends an error when it is not running.
+func (s *Server) getBackupOperator(c *gin.Context) (BackupOperator, bool) {
+	o := s.deps.Operators.BackupOperator()
+	if o == nil {
+		sendError(c, errors.WithStack(errors.Wrap(NotFoundError, "backup operator is not running")))
+		return nil, false
+	}
+	return o, true
+}
+
+// Handle a GET /api/backup request
+func (s *Server) handleGetBackups(c *gin.Context) {
+	o, ok := s.getBackupOperator(c)
+	if !ok {
+		return
+	}
+	backups, err := o.GetBackups()
+	if err != nil {
+		sendError(c, err)
+		return
+	}
+	result := make([]BackupInfo, len(backups))
+	for i, b := range backups {
+		result[i] = newBackupInfo(b)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"backups": result,
+	})
+}
+
+// Handle a GET /api/backup/:name request
+func (s *Server) handleGetBackupDetails(c *gin.Context) {
+	o, ok := s.getBackupOperator(c)
+	if !ok {
+		return
+	}
+	b, err := o.GetBackup(c.Params.ByName("name"))
+	if err != nil {
+		sendError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, newBackupInfoDetails(b))
+}
+
+// Handle a GET /api/backup-policy request
+func (s *Server) handleGetBackupPolicies(c *gin.Context) {
+	o, ok := s.getBackupOperator(c)
+	if !ok {
+		return
+	}
+	policies, err := o.GetBackupPolicies()
+	if err != nil {
+		sendError(c, err)
+		return
+	}
+	backups, err := o.GetBackups()
+	if err != nil {
+		sendError(c, err)
+		return
+	}
+	result := make([]BackupPolicyInfo, len(policies))
+	for i, p := range policies {
+		result[i] = newBackupPolicyInfo(p, backups)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"policies": result,
+	})
+}
+
+// Handle a GET /api/backup-policy/:name request
+func (s *Server) handleGetBackupPolicyDetails(c *gin.Context) {
+	o, ok := s.getBackupOperator(c)
+	if !ok {
+		return
+	}
+	p, err := o.GetBackupPolicy(c.Params.ByName("name"))
+	if err != nil {
+		sendError(c, err)
+		return
+	}
+	backups, err := o.GetBackups()
+	if err != nil {
+		sendError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, newBackupPolicyInfoDetails(p, backups))
+}
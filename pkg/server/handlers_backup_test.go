@@ -0,0 +1,209 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	backupApi "github.com/arangodb/kube-arangodb/pkg/apis/backup/v1"
+	"github.com/arangodb/kube-arangodb/pkg/util"
+	"github.com/arangodb/kube-arangodb/pkg/util/errors"
+)
+
+type testBackupOperator struct {
+	backups  []*backupApi.ArangoBackup
+	policies []*backupApi.ArangoBackupPolicy
+}
+
+func (o *testBackupOperator) GetBackups() ([]*backupApi.ArangoBackup, error) {
+	return o.backups, nil
+}
+
+func (o *testBackupOperator) GetBackup(name string) (*backupApi.ArangoBackup, error) {
+	for _, b := range o.backups {
+		if b.GetName() == name {
+			return b, nil
+		}
+	}
+	return nil, errors.WithStack(NotFoundError)
+}
+
+func (o *testBackupOperator) GetBackupPolicies() ([]*backupApi.ArangoBackupPolicy, error) {
+	return o.policies, nil
+}
+
+func (o *testBackupOperator) GetBackupPolicy(name string) (*backupApi.ArangoBackupPolicy, error) {
+	for _, p := range o.policies {
+		if p.GetName() == name {
+			return p, nil
+		}
+	}
+	return nil, errors.WithStack(NotFoundError)
+}
+
+func newTestBackupServer(o *testBackupOperator) *gin.Engine {
+	ops := testOperators{deployment: &testDeployment{}}
+	if o != nil {
+		ops.backups = o
+	}
+	s := &Server{
+		deps: Dependencies{
+			Log:       zerolog.Nop(),
+			Operators: ops,
+		},
+	}
+
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.GET("/api/backup", s.handleGetBackups)
+	r.GET("/api/backup/:name", s.handleGetBackupDetails)
+	r.GET("/api/backup-policy", s.handleGetBackupPolicies)
+	r.GET("/api/backup-policy/:name", s.handleGetBackupPolicyDetails)
+	return r
+}
+
+func TestBackupHandlers(t *testing.T) {
+	now := time.Now()
+	next := meta.NewTime(now.Add(time.Hour).Truncate(time.Second))
+
+	o := &testBackupOperator{
+		policies: []*backupApi.ArangoBackupPolicy{
+			{
+				ObjectMeta: meta.ObjectMeta{Name: "daily", Namespace: "ns"},
+				Spec: backupApi.ArangoBackupPolicySpec{
+					Schedule: "0 0 * * *",
+					BackupTemplate: backupApi.ArangoBackupTemplate{
+						Upload: &backupApi.ArangoBackupSpecOperation{RepositoryURL: "s3://bucket"},
+					},
+				},
+				Status: backupApi.ArangoBackupPolicyStatus{Scheduled: next},
+			},
+		},
+		backups: []*backupApi.ArangoBackup{
+			{
+				ObjectMeta: meta.ObjectMeta{Name: "old", Namespace: "ns", CreationTimestamp: meta.NewTime(now.Add(-time.Hour))},
+				Spec: backupApi.ArangoBackupSpec{
+					Deployment: backupApi.ArangoBackupSpecDeployment{Name: "test"},
+					PolicyName: util.NewString("daily"),
+					Upload:     &backupApi.ArangoBackupSpecOperation{RepositoryURL: "s3://bucket"},
+				},
+				Status: backupApi.ArangoBackupStatus{
+					ArangoBackupState: backupApi.ArangoBackupState{State: backupApi.ArangoBackupStateReady},
+					Available:         true,
+					Backup: &backupApi.ArangoBackupDetails{
+						ID:          "backup-1",
+						SizeInBytes: 1024,
+						Uploaded:    util.NewBool(true),
+					},
+				},
+			},
+			{
+				ObjectMeta: meta.ObjectMeta{Name: "new", Namespace: "ns", CreationTimestamp: meta.NewTime(now)},
+				Spec: backupApi.ArangoBackupSpec{
+					Deployment: backupApi.ArangoBackupSpecDeployment{Name: "test"},
+					PolicyName: util.NewString("daily"),
+					Upload:     &backupApi.ArangoBackupSpecOperation{RepositoryURL: "s3://bucket"},
+				},
+				Status: backupApi.ArangoBackupStatus{
+					ArangoBackupState: backupApi.ArangoBackupState{
+						State:    backupApi.ArangoBackupStateUploading,
+						Progress: &backupApi.ArangoBackupProgress{JobID: "job", Progress: "42%"},
+					},
+				},
+			},
+			{
+				ObjectMeta: meta.ObjectMeta{Name: "manual", Namespace: "ns"},
+				Spec: backupApi.ArangoBackupSpec{
+					Download: &backupApi.ArangoBackupSpecDownload{
+						ArangoBackupSpecOperation: backupApi.ArangoBackupSpecOperation{RepositoryURL: "s3://bucket"},
+						ID:                        "backup-0",
+					},
+				},
+				Status: backupApi.ArangoBackupStatus{
+					ArangoBackupState: backupApi.ArangoBackupState{State: backupApi.ArangoBackupStateDownloadError},
+				},
+			},
+		},
+	}
+	r := newTestBackupServer(o)
+
+	w := doRequest(r, http.MethodGet, "/api/backup", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var backups struct {
+		Backups []BackupInfo `json:"backups"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &backups))
+	require.Len(t, backups.Backups, 3)
+	assert.Equal(t, BackupOperationDone, backups.Backups[0].UploadStatus)
+	assert.EqualValues(t, 1024, backups.Backups[0].Size)
+	assert.Equal(t, BackupOperationRunning, backups.Backups[1].UploadStatus)
+	assert.Equal(t, "42%", backups.Backups[1].Progress)
+	assert.Equal(t, BackupOperationFailed, backups.Backups[2].DownloadStatus)
+
+	w = doRequest(r, http.MethodGet, "/api/backup/manual", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var details BackupInfoDetails
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &details))
+	require.NotNil(t, details.Download)
+	assert.Equal(t, "backup-0", details.Download.BackupID)
+	assert.Nil(t, details.Upload)
+
+	assert.Equal(t, http.StatusNotFound, doRequest(r, http.MethodGet, "/api/backup/missing", "").Code)
+
+	w = doRequest(r, http.MethodGet, "/api/backup-policy", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var policies struct {
+		Policies []BackupPolicyInfo `json:"policies"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &policies))
+	require.Len(t, policies.Policies, 1)
+	assert.Equal(t, 2, policies.Policies[0].BackupCount)
+	require.NotNil(t, policies.Policies[0].NextSchedule)
+	assert.True(t, next.Time.Equal(*policies.Policies[0].NextSchedule))
+	assert.Equal(t, "s3://bucket", policies.Policies[0].UploadRepository)
+
+	w = doRequest(r, http.MethodGet, "/api/backup-policy/daily", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var policy BackupPolicyInfoDetails
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &policy))
+	require.Len(t, policy.Backups, 2)
+	// Newest first
+	assert.Equal(t, "new", policy.Backups[0].Name)
+	assert.Equal(t, "old", policy.Backups[1].Name)
+
+	assert.Equal(t, http.StatusNotFound, doRequest(r, http.MethodGet, "/api/backup-policy/missing", "").Code)
+}
+
+func TestBackupHandlers_NoOperator(t *testing.T) {
+	r := newTestBackupServer(nil)
+
+	assert.Equal(t, http.StatusNotFound, doRequest(r, http.MethodGet, "/api/backup", "").Code)
+	assert.Equal(t, http.StatusNotFound, doRequest(r, http.MethodGet, "/api/backup-policy", "").Code)
+}
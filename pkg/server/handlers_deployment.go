@@ -23,6 +23,7 @@
 package server
 
 import (
+	"context"
 	"net/http"
 	"sort"
 	"strings"
@@ -30,6 +31,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	api "github.com/arangodb/kube-arangodb/pkg/apis/deployment/v1"
+	"github.com/arangodb/kube-arangodb/pkg/deployment/agency"
 )
 
 // Deployment is the API implemented by an ArangoDeployment.
@@ -47,6 +49,14 @@ type Deployment interface {
 	DatabaseURL() string
 	DatabaseVersion() (string, string)
 	Members() map[api.ServerGroup][]Member
+	MetricsTargets() ([]MetricsTarget, error)
+	// Events returns a channel receiving the events of the deployment with an ID higher than the given one.
+	// The channel is closed when the given context is done.
+	Events(ctx context.Context, lastEventID uint64) <-chan DeploymentEvent
+	// GetAgencyCache returns the agency state cached by the operator and false when it is not loaded.
+	GetAgencyCache() (agency.State, bool)
+
+	DeploymentActions
 }
 
 // Member is the API implemented by a member of an ArangoDeployment.
@@ -0,0 +1,276 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package server
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	backupApi "github.com/arangodb/kube-arangodb/pkg/apis/backup/v1"
+	api "github.com/arangodb/kube-arangodb/pkg/apis/deployment/v1"
+	"github.com/arangodb/kube-arangodb/pkg/util/errors"
+)
+
+const (
+	// actionTimeout is the maximum time a single write request is allowed to take
+	actionTimeout = 30 * time.Second
+)
+
+// DeploymentActions is the API implemented by an ArangoDeployment to modify it.
+// All modifications go through the ArangoDeployment resource (or the resources owned by it),
+// so they are executed by the operator in the same way as changes made with kubectl.
+type DeploymentActions interface {
+	// ScaleGroup sets the number of members of the given server group
+	ScaleGroup(ctx context.Context, group api.ServerGroup, count int) error
+	// RotateMember requests a rotation of the pod of the member with given ID
+	RotateMember(ctx context.Context, id string) error
+	// ReplaceMember requests a replacement of the member with given ID
+	ReplaceMember(ctx context.Context, id string) error
+	// SetMaintenance enables or disables the cluster maintenance mode
+	SetMaintenance(ctx context.Context, enabled bool) error
+	// Plans returns the current high priority and normal plan
+	Plans() (api.Plan, api.Plan)
+	// Backups returns all ArangoBackups of the deployment
+	Backups(ctx context.Context) ([]backupApi.ArangoBackup, error)
+	// CreateBackup creates an ArangoBackup of the deployment
+	CreateBackup(ctx context.Context, name string, spec backupApi.ArangoBackupSpec) (*backupApi.ArangoBackup, error)
+	// DeleteBackup removes the ArangoBackup of the deployment with given name
+	DeleteBackup(ctx context.Context, name string) error
+}
+
+// scaleRequest is the JSON structure POSTed to `/api/deployment/:name/scale`.
+type scaleRequest struct {
+	Group string `json:"group"`
+	Count int    `json:"count"`
+}
+
+// maintenanceRequest is the JSON structure PUT to `/api/deployment/:name/maintenance`.
+type maintenanceRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+// createBackupRequest is the JSON structure POSTed to `/api/deployment/:name/backup`.
+type createBackupRequest struct {
+	Name    string                               `json:"name"`
+	Options *backupApi.ArangoBackupSpecOptions   `json:"options,omitempty"`
+	Upload  *backupApi.ArangoBackupSpecOperation `json:"upload,omitempty"`
+}
+
+// PlanActionInfo contains the information of a single plan action
+type PlanActionInfo struct {
+	ID           string     `json:"id"`
+	Type         string     `json:"type"`
+	Group        string     `json:"group"`
+	MemberID     string     `json:"member_id"`
+	CreationTime time.Time  `json:"creation_time"`
+	StartTime    *time.Time `json:"start_time,omitempty"`
+	Reason       string     `json:"reason,omitempty"`
+}
+
+// PlanInfo contains the plans of a deployment
+type PlanInfo struct {
+	HighPriority []PlanActionInfo `json:"high_priority"`
+	Normal       []PlanActionInfo `json:"normal"`
+}
+
+// newPlanActionInfos creates PlanActionInfos for the given plan
+func newPlanActionInfos(plan api.Plan) []PlanActionInfo {
+	result := make([]PlanActionInfo, len(plan))
+	for i, a := range plan {
+		result[i] = PlanActionInfo{
+			ID:           a.ID,
+			Type:         a.Type.String(),
+			Group:        a.Group.AsRole(),
+			MemberID:     a.MemberID,
+			CreationTime: a.CreationTime.Time,
+			Reason:       a.Reason,
+		}
+		if a.StartTime != nil {
+			t := a.StartTime.Time
+			result[i].StartTime = &t
+		}
+	}
+	return result
+}
+
+// getDeployment returns the deployment with name given in the request.
+// On failure the error is already sent.
+func (s *Server) getDeployment(c *gin.Context) (Deployment, bool) {
+	do := s.deps.Operators.DeploymentOperator()
+	if do == nil {
+		sendError(c, errors.WithStack(NotFoundError))
+		return nil, false
+	}
+	depl, err := do.GetDeployment(c.Params.ByName("name"))
+	if err != nil {
+		sendError(c, err)
+		return nil, false
+	}
+	return depl, true
+}
+
+// runAction runs the given action on the deployment with name given in the request,
+// limited by actionTimeout.
+func (s *Server) runAction(c *gin.Context, action func(ctx context.Context, d Deployment) error) {
+	depl, ok := s.getDeployment(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), actionTimeout)
+	defer cancel()
+
+	if err := action(ctx, depl); err != nil {
+		sendError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+// Handle a POST /api/deployment/:name/scale request
+func (s *Server) handleScaleDeployment(c *gin.Context) {
+	var req scaleRequest
+	if err := c.BindJSON(&req); err != nil {
+		sendError(c, errors.WithStack(errors.Wrap(BadRequestError, err.Error())))
+		return
+	}
+
+	group := api.ServerGroupFromRole(req.Group)
+	switch group {
+	case api.ServerGroupDBServers, api.ServerGroupCoordinators, api.ServerGroupSyncMasters, api.ServerGroupSyncWorkers:
+	default:
+		sendError(c, errors.WithStack(errors.Wrapf(BadRequestError, "group '%s' cannot be scaled", req.Group)))
+		return
+	}
+	if req.Count < 0 {
+		sendError(c, errors.WithStack(errors.Wrap(BadRequestError, "count must not be negative")))
+		return
+	}
+
+	s.runAction(c, func(ctx context.Context, d Deployment) error {
+		return d.ScaleGroup(ctx, group, req.Count)
+	})
+}
+
+// Handle a POST /api/deployment/:name/member/:id/rotate request
+func (s *Server) handleRotateMember(c *gin.Context) {
+	s.runAction(c, func(ctx context.Context, d Deployment) error {
+		return d.RotateMember(ctx, c.Params.ByName("id"))
+	})
+}
+
+// Handle a POST /api/deployment/:name/member/:id/replace request
+func (s *Server) handleReplaceMember(c *gin.Context) {
+	s.runAction(c, func(ctx context.Context, d Deployment) error {
+		return d.ReplaceMember(ctx, c.Params.ByName("id"))
+	})
+}
+
+// Handle a PUT /api/deployment/:name/maintenance request
+func (s *Server) handleSetMaintenance(c *gin.Context) {
+	var req maintenanceRequest
+	if err := c.BindJSON(&req); err != nil {
+		sendError(c, errors.WithStack(errors.Wrap(BadRequestError, err.Error())))
+		return
+	}
+
+	s.runAction(c, func(ctx context.Context, d Deployment) error {
+		return d.SetMaintenance(ctx, req.Enabled)
+	})
+}
+
+// Handle a GET /api/deployment/:name/plan request
+func (s *Server) handleGetDeploymentPlan(c *gin.Context) {
+	depl, ok := s.getDeployment(c)
+	if !ok {
+		return
+	}
+
+	high, normal := depl.Plans()
+	c.JSON(http.StatusOK, PlanInfo{
+		HighPriority: newPlanActionInfos(high),
+		Normal:       newPlanActionInfos(normal),
+	})
+}
+
+// Handle a GET /api/deployment/:name/backup request
+func (s *Server) handleGetDeploymentBackups(c *gin.Context) {
+	depl, ok := s.getDeployment(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), actionTimeout)
+	defer cancel()
+
+	backups, err := depl.Backups(ctx)
+	if err != nil {
+		sendError(c, err)
+		return
+	}
+
+	result := make([]BackupInfo, len(backups))
+	for i := range backups {
+		result[i] = newBackupInfo(&backups[i])
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"backups": result,
+	})
+}
+
+// Handle a POST /api/deployment/:name/backup request
+func (s *Server) handleCreateDeploymentBackup(c *gin.Context) {
+	var req createBackupRequest
+	if err := c.BindJSON(&req); err != nil {
+		sendError(c, errors.WithStack(errors.Wrap(BadRequestError, err.Error())))
+		return
+	}
+
+	depl, ok := s.getDeployment(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), actionTimeout)
+	defer cancel()
+
+	backup, err := depl.CreateBackup(ctx, req.Name, backupApi.ArangoBackupSpec{
+		Options: req.Options,
+		Upload:  req.Upload,
+	})
+	if err != nil {
+		sendError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, newBackupInfo(backup))
+}
+
+// Handle a DELETE /api/deployment/:name/backup/:backup request
+func (s *Server) handleDeleteDeploymentBackup(c *gin.Context) {
+	s.runAction(c, func(ctx context.Context, d Deployment) error {
+		return d.DeleteBackup(ctx, c.Params.ByName("backup"))
+	})
+}
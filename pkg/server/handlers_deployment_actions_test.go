@@ -0,0 +1,265 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	api "github.com/arangodb/kube-arangodb/pkg/apis/deployment/v1"
+	"github.com/arangodb/kube-arangodb/pkg/deployment/agency"
+	"github.com/arangodb/kube-arangodb/pkg/util/errors"
+)
+
+type testOperators struct {
+	Operators
+	deployment *testDeployment
+	backups    *testBackupOperator
+}
+
+func (o testOperators) BackupOperator() BackupOperator {
+	if o.backups == nil {
+		return nil
+	}
+	return o.backups
+}
+
+func (o testOperators) DeploymentOperator() DeploymentOperator {
+	return o
+}
+
+func (o testOperators) GetDeployments() ([]Deployment, error) {
+	return []Deployment{o.deployment}, nil
+}
+
+func (o testOperators) GetDeployment(name string) (Deployment, error) {
+	if name != o.deployment.Name() {
+		return nil, errors.WithStack(NotFoundError)
+	}
+	return o.deployment, nil
+}
+
+type testDeployment struct {
+	Deployment
+
+	scaled      map[api.ServerGroup]int
+	rotated     []string
+	maintenance *bool
+	plan        api.Plan
+	events      *EventStream
+	agencyState *agency.State
+}
+
+func (d *testDeployment) Name() string {
+	return "test"
+}
+
+func (d *testDeployment) ScaleGroup(_ context.Context, group api.ServerGroup, count int) error {
+	if d.scaled == nil {
+		d.scaled = map[api.ServerGroup]int{}
+	}
+	d.scaled[group] = count
+	return nil
+}
+
+func (d *testDeployment) RotateMember(_ context.Context, id string) error {
+	if id != "PRMR-1" {
+		return errors.WithStack(NotFoundError)
+	}
+	d.rotated = append(d.rotated, id)
+	return nil
+}
+
+func (d *testDeployment) SetMaintenance(_ context.Context, enabled bool) error {
+	d.maintenance = &enabled
+	return nil
+}
+
+func (d *testDeployment) Plans() (api.Plan, api.Plan) {
+	return nil, d.plan
+}
+
+func (d *testDeployment) Events(ctx context.Context, lastEventID uint64) <-chan DeploymentEvent {
+	return d.events.Subscribe(ctx, lastEventID)
+}
+
+func (d *testDeployment) GetAgencyCache() (agency.State, bool) {
+	if d.agencyState == nil {
+		return agency.State{}, false
+	}
+	return *d.agencyState, true
+}
+
+func newTestActionsServer(d *testDeployment, audit *bytes.Buffer) *gin.Engine {
+	s := &Server{
+		deps: Dependencies{
+			Log:       zerolog.Nop(),
+			AuditLog:  zerolog.New(audit),
+			Operators: testOperators{deployment: d},
+		},
+	}
+
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.GET("/api/deployment/:name/plan", s.handleGetDeploymentPlan)
+	write := r.Group("/api/deployment/:name", func(c *gin.Context) {
+		c.Set(usernameKey, "admin")
+	}, s.audit)
+	write.POST("/scale", s.handleScaleDeployment)
+	write.POST("/member/:id/rotate", s.handleRotateMember)
+	write.PUT("/maintenance", s.handleSetMaintenance)
+	return r
+}
+
+func doRequest(r http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestDeploymentActions_Scale(t *testing.T) {
+	var audit bytes.Buffer
+	d := &testDeployment{}
+	r := newTestActionsServer(d, &audit)
+
+	w := doRequest(r, http.MethodPost, "/api/deployment/test/scale", `{"group":"dbserver","count":5}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 5, d.scaled[api.ServerGroupDBServers])
+
+	w = doRequest(r, http.MethodPost, "/api/deployment/test/scale", `{"group":"agent","count":5}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.NotContains(t, d.scaled, api.ServerGroupAgents)
+
+	w = doRequest(r, http.MethodPost, "/api/deployment/other/scale", `{"group":"dbserver","count":5}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	lines := strings.Split(strings.TrimSpace(audit.String()), "\n")
+	require.Len(t, lines, 3)
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &entry))
+	assert.Equal(t, "admin", entry["user"])
+	assert.Equal(t, http.MethodPost, entry["method"])
+	assert.Equal(t, "test", entry["name"])
+	assert.EqualValues(t, http.StatusOK, entry["status"])
+
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &entry))
+	assert.EqualValues(t, http.StatusBadRequest, entry["status"])
+}
+
+func TestDeploymentActions_RequireAuthentication(t *testing.T) {
+	var audit bytes.Buffer
+	d := &testDeployment{}
+	auth := newServerAuthentication(zerolog.Nop(), nil, "", true)
+	auth.tokens.tokens["token"] = &tokenEntry{Token: "token", Username: "admin", ExpiresAt: time.Now().Add(time.Hour)}
+	s := &Server{
+		deps: Dependencies{
+			Log:       zerolog.Nop(),
+			AuditLog:  zerolog.New(&audit),
+			Operators: testOperators{deployment: d},
+		},
+		auth: auth,
+	}
+
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	s.writeGroup(r).POST("/scale", s.handleScaleDeployment)
+
+	// Anonymous access does not cover write requests
+	w := doRequest(r, http.MethodPost, "/api/deployment/x/scale", `{"group":"dbserver","count":5}`)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Nil(t, d.scaled)
+
+	w = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/deployment/test/scale", strings.NewReader(`{"group":"dbserver","count":5}`))
+	req.Header.Set("Authorization", "bearer token")
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 5, d.scaled[api.ServerGroupDBServers])
+
+	// Denied requests are audited as well
+	lines := strings.Split(strings.TrimSpace(audit.String()), "\n")
+	require.Len(t, lines, 2)
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &entry))
+	assert.Equal(t, "anonymous", entry["user"])
+	assert.Equal(t, "x", entry["name"])
+	assert.EqualValues(t, http.StatusUnauthorized, entry["status"])
+
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &entry))
+	assert.Equal(t, "admin", entry["user"])
+	assert.EqualValues(t, http.StatusOK, entry["status"])
+}
+
+func TestDeploymentActions_MemberAndMaintenance(t *testing.T) {
+	var audit bytes.Buffer
+	d := &testDeployment{}
+	r := newTestActionsServer(d, &audit)
+
+	w := doRequest(r, http.MethodPost, "/api/deployment/test/member/PRMR-1/rotate", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"PRMR-1"}, d.rotated)
+
+	w = doRequest(r, http.MethodPost, "/api/deployment/test/member/PRMR-2/rotate", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = doRequest(r, http.MethodPut, "/api/deployment/test/maintenance", `{"enabled":true}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotNil(t, d.maintenance)
+	assert.True(t, *d.maintenance)
+}
+
+func TestDeploymentActions_Plan(t *testing.T) {
+	var audit bytes.Buffer
+	d := &testDeployment{
+		plan: api.Plan{
+			api.NewAction(api.ActionTypeRotateMember, api.ServerGroupDBServers, "PRMR-1", "Pod changed"),
+		},
+	}
+	r := newTestActionsServer(d, &audit)
+
+	w := doRequest(r, http.MethodGet, "/api/deployment/test/plan", "")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var plan PlanInfo
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &plan))
+	assert.Len(t, plan.HighPriority, 0)
+	require.Len(t, plan.Normal, 1)
+	assert.Equal(t, "RotateMember", plan.Normal[0].Type)
+	assert.Equal(t, "dbserver", plan.Normal[0].Group)
+	assert.Equal(t, "Pod changed", plan.Normal[0].Reason)
+
+	// Read requests are not audited
+	assert.Empty(t, audit.String())
+}
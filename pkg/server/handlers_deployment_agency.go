@@ -0,0 +1,143 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package server
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/arangodb/kube-arangodb/pkg/deployment/agency"
+	"github.com/arangodb/kube-arangodb/pkg/util/errors"
+)
+
+// ServerShardsInfo contains the number of shards planned on a single DBServer
+type ServerShardsInfo struct {
+	Server    string `json:"server"`
+	Leaders   int    `json:"leaders"`
+	Followers int    `json:"followers"`
+	OutOfSync int    `json:"out_of_sync"`
+}
+
+// ShardInfo contains the placement of a single shard
+type ShardInfo struct {
+	Database           string   `json:"database"`
+	Collection         string   `json:"collection"`
+	Shard              string   `json:"shard"`
+	Leader             string   `json:"leader"`
+	LeaderInSync       bool     `json:"leader_in_sync"`
+	InSyncFollowers    []string `json:"in_sync_followers"`
+	OutOfSyncFollowers []string `json:"out_of_sync_followers"`
+	InSync             bool     `json:"in_sync"`
+}
+
+// ShardsInfo contains the shard distribution of a deployment
+type ShardsInfo struct {
+	Servers         []ServerShardsInfo `json:"servers"`
+	Shards          []ShardInfo        `json:"shards"`
+	OutOfSyncShards int                `json:"out_of_sync_shards"`
+}
+
+// SupervisionInfo contains the state of the agency supervision of a deployment
+type SupervisionInfo struct {
+	Maintenance bool `json:"maintenance"`
+}
+
+// newShardsInfo creates a ShardsInfo from the cached agency state
+func newShardsInfo(state agency.State, database string) ShardsInfo {
+	result := ShardsInfo{
+		Servers: []ServerShardsInfo{},
+		Shards:  []ShardInfo{},
+	}
+
+	for _, s := range state.GetShardDistribution() {
+		result.Servers = append(result.Servers, ServerShardsInfo{
+			Server:    s.Server,
+			Leaders:   s.Leaders,
+			Followers: s.Followers,
+			OutOfSync: s.OutOfSync,
+		})
+	}
+
+	for _, s := range state.GetShards(database) {
+		info := ShardInfo{
+			Database:           s.Database,
+			Collection:         s.Collection,
+			Shard:              s.Shard,
+			Leader:             s.Leader,
+			LeaderInSync:       s.LeaderInSync,
+			InSyncFollowers:    s.InSyncFollowers,
+			OutOfSyncFollowers: s.OutOfSyncFollowers,
+			InSync:             s.IsInSync(),
+		}
+		if info.InSyncFollowers == nil {
+			info.InSyncFollowers = []string{}
+		}
+		if info.OutOfSyncFollowers == nil {
+			info.OutOfSyncFollowers = []string{}
+		}
+		if !info.InSync {
+			result.OutOfSyncShards++
+		}
+		result.Shards = append(result.Shards, info)
+	}
+
+	return result
+}
+
+// getAgencyState returns the cached agency state of the deployment with name given in the request.
+// On failure the error is already sent.
+func (s *Server) getAgencyState(c *gin.Context) (agency.State, bool) {
+	depl, ok := s.getDeployment(c)
+	if !ok {
+		return agency.State{}, false
+	}
+
+	state, ok := depl.GetAgencyCache()
+	if !ok {
+		sendError(c, errors.Wrapf(UnavailableError, "agency state of deployment %s is not loaded", depl.Name()))
+		return agency.State{}, false
+	}
+
+	return state, true
+}
+
+// Handle a GET /api/deployment/:name/shards request
+func (s *Server) handleGetDeploymentShards(c *gin.Context) {
+	state, ok := s.getAgencyState(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, newShardsInfo(state, c.Query("database")))
+}
+
+// Handle a GET /api/deployment/:name/supervision request
+func (s *Server) handleGetDeploymentSupervision(c *gin.Context) {
+	state, ok := s.getAgencyState(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, SupervisionInfo{
+		Maintenance: state.Supervision.Maintenance.Exists(),
+	})
+}
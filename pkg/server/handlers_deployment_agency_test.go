@@ -0,0 +1,120 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/arangodb/kube-arangodb/pkg/deployment/agency"
+)
+
+func newTestAgencyServer(d *testDeployment) *gin.Engine {
+	s := &Server{
+		deps: Dependencies{
+			Log:       zerolog.Nop(),
+			Operators: testOperators{deployment: d},
+		},
+	}
+
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.GET("/api/deployment/:name/shards", s.handleGetDeploymentShards)
+	r.GET("/api/deployment/:name/supervision", s.handleGetDeploymentSupervision)
+	return r
+}
+
+func newTestAgencyState(t *testing.T) *agency.State {
+	data := `{
+"Supervision":{"Maintenance":"2021-01-01T00:00:00Z"},
+"Plan":{"Collections":{
+	"_system":{"1":{"name":"users","shards":{"s1":["A","B"],"s2":["B","C"]}}},
+	"db":{"2":{"name":"orders","shards":{"s3":["C"]}}}
+}},
+"Current":{"Collections":{
+	"_system":{"1":{"s1":{"servers":["A","B"]},"s2":{"servers":["B"]}}},
+	"db":{"2":{"s3":{"servers":["C"]}}}
+}}
+}`
+	var s agency.State
+	require.NoError(t, json.Unmarshal([]byte(data), &s))
+	return &s
+}
+
+func TestDeploymentAgency_Shards(t *testing.T) {
+	r := newTestAgencyServer(&testDeployment{agencyState: newTestAgencyState(t)})
+
+	t.Run("All databases", func(t *testing.T) {
+		w := doRequest(r, http.MethodGet, "/api/deployment/test/shards", "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var info ShardsInfo
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &info))
+		require.Len(t, info.Servers, 3)
+		require.Len(t, info.Shards, 3)
+		assert.Equal(t, 1, info.OutOfSyncShards)
+
+		assert.Equal(t, ShardInfo{
+			Database:           "_system",
+			Collection:         "users",
+			Shard:              "s2",
+			Leader:             "B",
+			LeaderInSync:       true,
+			InSyncFollowers:    []string{},
+			OutOfSyncFollowers: []string{"C"},
+		}, info.Shards[1])
+		assert.True(t, info.Shards[2].InSync)
+	})
+
+	t.Run("Single database", func(t *testing.T) {
+		w := doRequest(r, http.MethodGet, "/api/deployment/test/shards?database=db", "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var info ShardsInfo
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &info))
+		require.Len(t, info.Shards, 1)
+		assert.Equal(t, "orders", info.Shards[0].Collection)
+	})
+
+	t.Run("Unknown deployment", func(t *testing.T) {
+		w := doRequest(r, http.MethodGet, "/api/deployment/other/shards", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestDeploymentAgency_Supervision(t *testing.T) {
+	d := &testDeployment{}
+	r := newTestAgencyServer(d)
+
+	w := doRequest(r, http.MethodGet, "/api/deployment/test/supervision", "")
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	d.agencyState = newTestAgencyState(t)
+	w = doRequest(r, http.MethodGet, "/api/deployment/test/supervision", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"maintenance":true}`, w.Body.String())
+}
@@ -0,0 +1,115 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/arangodb/kube-arangodb/pkg/util/errors"
+)
+
+const (
+	// eventStreamDuration is the maximum lifetime of a single event stream response.
+	// It has to be shorter than the write timeout of the server, clients reconnect afterwards.
+	eventStreamDuration = 25 * time.Second
+	// eventStreamRetry is the reconnect delay advised to clients
+	eventStreamRetry = time.Second
+	// eventStreamKeepAlive is the interval of comments sent to keep idle connections open
+	eventStreamKeepAlive = 10 * time.Second
+
+	lastEventIDHeader = "Last-Event-ID"
+)
+
+// Handle a GET /api/deployment/:name/events request.
+// The events of the deployment are streamed as server-sent events (text/event-stream).
+// Every response ends after eventStreamDuration, clients continue the stream by reconnecting
+// with the ID of the last received event in the Last-Event-ID header (or last_event_id query parameter).
+// EventSource clients do this automatically.
+func (s *Server) handleGetDeploymentEvents(c *gin.Context) {
+	var lastEventID uint64
+	if id := c.GetHeader(lastEventIDHeader); id != "" || c.Query("last_event_id") != "" {
+		if id == "" {
+			id = c.Query("last_event_id")
+		}
+		parsed, err := strconv.ParseUint(id, 10, 64)
+		if err != nil {
+			sendError(c, errors.WithStack(errors.Wrapf(BadRequestError, "invalid last event ID '%s'", id)))
+			return
+		}
+		lastEventID = parsed
+	}
+
+	depl, ok := s.getDeployment(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), eventStreamDuration)
+	defer cancel()
+
+	events := depl.Events(ctx, lastEventID)
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	if _, err := fmt.Fprintf(c.Writer, "retry: %d\n\n", eventStreamRetry.Milliseconds()); err != nil {
+		return
+	}
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(eventStreamKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(ev)
+			if err != nil {
+				s.deps.Log.Warn().Err(err).Msg("Failed to encode deployment event")
+				return
+			}
+			if _, err := fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", ev.ID, ev.Type, data); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(c.Writer, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+		c.Writer.Flush()
+	}
+}
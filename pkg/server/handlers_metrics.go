@@ -0,0 +1,303 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package server
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+
+	"github.com/arangodb/kube-arangodb/pkg/util/errors"
+)
+
+const (
+	// DefaultMetricsScrapeTimeout is the default timeout of a single member scrape
+	DefaultMetricsScrapeTimeout = 5 * time.Second
+
+	metricsLabelDeployment = "deployment"
+	metricsLabelGroup      = "group"
+	metricsLabelMember     = "member"
+	metricsLabelPod        = "pod"
+)
+
+// MetricsTarget describes a metrics endpoint of a single member of an ArangoDeployment.
+type MetricsTarget struct {
+	Group         string
+	ID            string
+	PodName       string
+	URL           string
+	Authorization string
+	Transport     http.RoundTripper
+}
+
+var (
+	memberScrapeSuccessDesc = prometheus.NewDesc("arangodb_operator_deployment_member_scrape_success",
+		"Result of the last metrics scrape of the member (1 if successful)",
+		[]string{metricsLabelDeployment, metricsLabelGroup, metricsLabelMember, metricsLabelPod}, nil)
+	memberScrapeDurationDesc = prometheus.NewDesc("arangodb_operator_deployment_member_scrape_duration_seconds",
+		"Duration of the last metrics scrape of the member",
+		[]string{metricsLabelDeployment, metricsLabelGroup, metricsLabelMember, metricsLabelPod}, nil)
+)
+
+// memberScrapeResult holds the outcome of a single member scrape.
+type memberScrapeResult struct {
+	target   MetricsTarget
+	families map[string]*dto.MetricFamily
+	duration time.Duration
+	err      error
+}
+
+// Handle a GET /metrics/deployment/:name request
+func (s *Server) handleGetDeploymentMetrics(c *gin.Context) {
+	do := s.deps.Operators.DeploymentOperator()
+	if do == nil {
+		sendError(c, errors.WithStack(NotFoundError))
+		return
+	}
+
+	depl, err := do.GetDeployment(c.Params.ByName("name"))
+	if err != nil {
+		sendError(c, err)
+		return
+	}
+
+	targets, err := depl.MetricsTargets()
+	if err != nil {
+		sendError(c, err)
+		return
+	}
+
+	timeout := s.cfg.MetricsScrapeTimeout
+	if timeout <= 0 {
+		timeout = DefaultMetricsScrapeTimeout
+	}
+
+	results := scrapeMetricsTargets(c.Request.Context(), targets, timeout)
+
+	families, err := mergeMemberMetrics(depl.Name(), results)
+	if err != nil {
+		sendError(c, err)
+		return
+	}
+
+	for _, r := range results {
+		if r.err != nil {
+			s.deps.Log.Debug().Err(r.err).Str("deployment", depl.Name()).Str("member", r.target.ID).Msg("Unable to scrape member metrics")
+		}
+	}
+
+	format := expfmt.NegotiateIncludingOpenMetrics(c.Request.Header)
+	c.Header("Content-Type", string(format))
+	c.Status(http.StatusOK)
+
+	enc := expfmt.NewEncoder(c.Writer, format)
+	for _, family := range families {
+		if err := enc.Encode(family); err != nil {
+			s.deps.Log.Debug().Err(err).Msg("Unable to encode metrics")
+			return
+		}
+	}
+	if closer, ok := enc.(expfmt.Closer); ok {
+		closer.Close()
+	}
+}
+
+// scrapeMetricsTargets scrapes all given targets concurrently.
+// Every target is limited by the given timeout, so a single slow member does not block the whole scrape.
+func scrapeMetricsTargets(ctx context.Context, targets []MetricsTarget, timeout time.Duration) []memberScrapeResult {
+	results := make([]memberScrapeResult, len(targets))
+
+	var wg sync.WaitGroup
+	for i := range targets {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			start := time.Now()
+			families, err := scrapeMetricsTarget(ctx, targets[i], timeout)
+			results[i] = memberScrapeResult{
+				target:   targets[i],
+				families: families,
+				duration: time.Since(start),
+				err:      err,
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// scrapeMetricsTarget fetches and parses the metrics of a single member.
+func scrapeMetricsTarget(ctx context.Context, target MetricsTarget, timeout time.Duration) (map[string]*dto.MetricFamily, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.URL, nil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if target.Authorization != "" {
+		req.Header.Set("Authorization", target.Authorization)
+	}
+
+	client := &http.Client{
+		Transport: target.Transport,
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return nil, errors.Newf("Unexpected status code %d: %s", resp.StatusCode, string(body))
+	}
+
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(resp.Body)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return families, nil
+}
+
+// mergeMemberMetrics merges the metrics of all members into a single set of metric families.
+// Every series is labeled with the deployment, group, member ID and pod name.
+func mergeMemberMetrics(deployment string, results []memberScrapeResult) ([]*dto.MetricFamily, error) {
+	merged := map[string]*dto.MetricFamily{}
+
+	var status []prometheus.Metric
+	for _, r := range results {
+		labels := []*dto.LabelPair{
+			newLabelPair(metricsLabelDeployment, deployment),
+			newLabelPair(metricsLabelGroup, r.target.Group),
+			newLabelPair(metricsLabelMember, r.target.ID),
+			newLabelPair(metricsLabelPod, r.target.PodName),
+		}
+
+		success := 1.0
+		if r.err != nil {
+			success = 0
+		}
+		labelValues := []string{deployment, r.target.Group, r.target.ID, r.target.PodName}
+		status = append(status,
+			prometheus.MustNewConstMetric(memberScrapeSuccessDesc, prometheus.GaugeValue, success, labelValues...),
+			prometheus.MustNewConstMetric(memberScrapeDurationDesc, prometheus.GaugeValue, r.duration.Seconds(), labelValues...))
+
+		for name, family := range r.families {
+			target, ok := merged[name]
+			if !ok {
+				target = &dto.MetricFamily{
+					Name: family.Name,
+					Help: family.Help,
+					Type: family.Type,
+				}
+				merged[name] = target
+			} else if target.GetType() != family.GetType() {
+				// Skip series which do not match the type reported by other members
+				continue
+			}
+
+			for _, m := range family.Metric {
+				m.Label = withLabels(m.Label, labels)
+				target.Metric = append(target.Metric, m)
+			}
+		}
+	}
+
+	gatherers := prometheus.Gatherers{
+		prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+			result := make([]*dto.MetricFamily, 0, len(merged))
+			for _, family := range merged {
+				result = append(result, family)
+			}
+			return result, nil
+		}),
+		prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+			registry := prometheus.NewRegistry()
+			if err := registry.Register(constCollector(status)); err != nil {
+				return nil, err
+			}
+			return registry.Gather()
+		}),
+	}
+
+	families, err := gatherers.Gather()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return families, nil
+}
+
+// withLabels returns the given labels extended (or overridden) by the additional labels, sorted by name.
+func withLabels(labels []*dto.LabelPair, additional []*dto.LabelPair) []*dto.LabelPair {
+	result := make([]*dto.LabelPair, 0, len(labels)+len(additional))
+	result = append(result, additional...)
+	for _, l := range labels {
+		overridden := false
+		for _, a := range additional {
+			if l.GetName() == a.GetName() {
+				overridden = true
+				break
+			}
+		}
+		if !overridden {
+			result = append(result, l)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].GetName() < result[j].GetName()
+	})
+	return result
+}
+
+func newLabelPair(name, value string) *dto.LabelPair {
+	return &dto.LabelPair{
+		Name:  &name,
+		Value: &value,
+	}
+}
+
+// constCollector exposes a fixed set of metrics.
+type constCollector []prometheus.Metric
+
+func (c constCollector) Describe(descs chan<- *prometheus.Desc) {
+	descs <- memberScrapeSuccessDesc
+	descs <- memberScrapeDurationDesc
+}
+
+func (c constCollector) Collect(out chan<- prometheus.Metric) {
+	for _, m := range c {
+		out <- m
+	}
+}
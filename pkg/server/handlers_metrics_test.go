@@ -0,0 +1,104 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testMemberMetrics = `# HELP arangodb_client_connection_statistics_total Total number of connections
+# TYPE arangodb_client_connection_statistics_total counter
+arangodb_client_connection_statistics_total{role="SINGLE"} 12
+`
+
+func TestScrapeMetricsTargets(t *testing.T) {
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "bearer token", r.Header.Get("Authorization"))
+		w.Write([]byte(testMemberMetrics))
+	}))
+	defer fast.Close()
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer slow.Close()
+
+	targets := []MetricsTarget{
+		{Group: "dbserver", ID: "PRMR-1", PodName: "pod-prmr-1", URL: fast.URL, Authorization: "bearer token"},
+		{Group: "dbserver", ID: "PRMR-2", PodName: "pod-prmr-2", URL: fast.URL, Authorization: "bearer token"},
+		{Group: "coordinator", ID: "CRDN-1", PodName: "pod-crdn-1", URL: slow.URL},
+	}
+
+	start := time.Now()
+	results := scrapeMetricsTargets(context.Background(), targets, 100*time.Millisecond)
+	assert.True(t, time.Since(start) < 2*time.Second)
+
+	require.Len(t, results, 3)
+	require.NoError(t, results[0].err)
+	require.NoError(t, results[1].err)
+	require.Error(t, results[2].err)
+
+	families, err := mergeMemberMetrics("test", results)
+	require.NoError(t, err)
+
+	byName := map[string]int{}
+	for i, f := range families {
+		byName[f.GetName()] = i
+	}
+
+	require.Contains(t, byName, "arangodb_client_connection_statistics_total")
+	connections := families[byName["arangodb_client_connection_statistics_total"]]
+	require.Len(t, connections.GetMetric(), 2)
+
+	labels := map[string]string{}
+	for _, l := range connections.GetMetric()[0].GetLabel() {
+		labels[l.GetName()] = l.GetValue()
+	}
+	assert.Equal(t, "test", labels["deployment"])
+	assert.Equal(t, "dbserver", labels["group"])
+	assert.Equal(t, "SINGLE", labels["role"])
+	assert.Contains(t, []string{"PRMR-1", "PRMR-2"}, labels["member"])
+
+	require.Contains(t, byName, "arangodb_operator_deployment_member_scrape_success")
+	success := families[byName["arangodb_operator_deployment_member_scrape_success"]]
+	require.Len(t, success.GetMetric(), 3)
+	for _, m := range success.GetMetric() {
+		for _, l := range m.GetLabel() {
+			if l.GetName() == "member" {
+				if l.GetValue() == "CRDN-1" {
+					assert.EqualValues(t, 0, m.GetGauge().GetValue())
+				} else {
+					assert.EqualValues(t, 1, m.GetGauge().GetValue())
+				}
+			}
+		}
+	}
+}
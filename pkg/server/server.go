@@ -41,6 +41,8 @@ import (
 	"github.com/rs/zerolog"
 	core "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	authenticationv1 "k8s.io/client-go/kubernetes/typed/authentication/v1"
+	authorizationv1 "k8s.io/client-go/kubernetes/typed/authorization/v1"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 
 	"github.com/arangodb/kube-arangodb/dashboard"
@@ -57,6 +59,11 @@ type Config struct {
 	PodIP              string // IP address of the Pod we're running in
 	AdminSecretName    string // Name of basic authentication secret containing the admin username+password of the dashboard
 	AllowAnonymous     bool   // If set, anonymous access to dashboard is allowed
+	AuthMode           string // Authentication mode of the dashboard (basic|kubernetes|oidc)
+
+	OIDC OIDCConfig // Settings of the oidc authentication mode
+
+	MetricsScrapeTimeout time.Duration // Timeout of a single member scrape of the aggregated deployment metrics
 }
 
 type OperatorDependency struct {
@@ -67,6 +74,7 @@ type OperatorDependency struct {
 // Dependencies of the Server
 type Dependencies struct {
 	Log                   zerolog.Logger
+	AuditLog              zerolog.Logger
 	LivenessProbe         *probe.LivenessProbe
 	Deployment            OperatorDependency
 	DeploymentReplication OperatorDependency
@@ -74,6 +82,8 @@ type Dependencies struct {
 	Backup                OperatorDependency
 	Operators             Operators
 	Secrets               corev1.SecretInterface
+	TokenReviews          authenticationv1.TokenReviewInterface
+	SubjectAccessReviews  authorizationv1.SubjectAccessReviewInterface
 }
 
 // Operators is the API provided to the server for accessing the various operators.
@@ -84,6 +94,8 @@ type Operators interface {
 	DeploymentReplicationOperator() DeploymentReplicationOperator
 	// Return the local storage operator (if any)
 	StorageOperator() StorageOperator
+	// Return the backup operator (if any)
+	BackupOperator() BackupOperator
 	// FindOtherOperators looks up references to other operators in the same Kubernetes cluster.
 	FindOtherOperators() []OperatorReference
 }
@@ -93,7 +105,7 @@ type Server struct {
 	cfg        Config
 	deps       Dependencies
 	httpServer *http.Server
-	auth       *serverAuthentication
+	auth       authentication
 }
 
 // NewServer creates a new server, fetching/preparing a TLS certificate.
@@ -151,7 +163,19 @@ func NewServer(cli corev1.CoreV1Interface, cfg Config, deps Dependencies) (*Serv
 		cfg:        cfg,
 		deps:       deps,
 		httpServer: httpServer,
-		auth:       newServerAuthentication(deps.Log, deps.Secrets, cfg.AdminSecretName, cfg.AllowAnonymous),
+	}
+
+	switch cfg.AuthMode {
+	case AuthModeBasic, "":
+		s.auth = newServerAuthentication(deps.Log, deps.Secrets, cfg.AdminSecretName, cfg.AllowAnonymous)
+	case AuthModeKubernetes:
+		s.auth = newKubernetesAuthentication(deps.Log, deps.TokenReviews, deps.SubjectAccessReviews, cfg.Namespace)
+	case AuthModeOIDC:
+		if s.auth, err = newOIDCAuthentication(deps.Log, deps.Secrets, cfg.OIDC); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	default:
+		return nil, errors.WithStack(errors.Newf("Unknown authentication mode '%s'", cfg.AuthMode))
 	}
 
 	// Build router
@@ -182,7 +206,16 @@ func NewServer(cli corev1.CoreV1Interface, cfg Config, deps Dependencies) (*Serv
 	}
 	r.GET("/ready", gin.WrapF(ready(readyProbes...)))
 	r.GET("/metrics", gin.WrapH(prometheus.Handler()))
+	if deps.Deployment.Enabled {
+		// Member metrics are fetched with the credentials of the deployment, so they require the same authentication as the API
+		r.GET("/metrics/deployment/:name", s.auth.checkAuthentication, s.handleGetDeploymentMetrics)
+	}
 	r.POST("/login", s.auth.handleLogin)
+	if oidc, ok := s.auth.(*oidcAuthentication); ok {
+		r.GET(OIDCLoginPath, oidc.handleAuthorize)
+		r.GET(OIDCCallbackPath, oidc.handleCallback)
+		r.POST("/logout", oidc.handleLogout)
+	}
 	api := r.Group("/api", s.auth.checkAuthentication)
 	{
 		api.GET("/operators", s.handleGetOperators)
@@ -190,6 +223,20 @@ func NewServer(cli corev1.CoreV1Interface, cfg Config, deps Dependencies) (*Serv
 		// Deployment operator
 		api.GET("/deployment", s.handleGetDeployments)
 		api.GET("/deployment/:name", s.handleGetDeploymentDetails)
+		api.GET("/deployment/:name/plan", s.handleGetDeploymentPlan)
+		api.GET("/deployment/:name/backup", s.handleGetDeploymentBackups)
+		api.GET("/deployment/:name/events", s.handleGetDeploymentEvents)
+		api.GET("/deployment/:name/shards", s.handleGetDeploymentShards)
+		api.GET("/deployment/:name/supervision", s.handleGetDeploymentSupervision)
+
+		// Deployment operator (write)
+		write := s.writeGroup(r)
+		write.POST("/scale", s.handleScaleDeployment)
+		write.POST("/member/:id/rotate", s.handleRotateMember)
+		write.POST("/member/:id/replace", s.handleReplaceMember)
+		write.PUT("/maintenance", s.handleSetMaintenance)
+		write.POST("/backup", s.handleCreateDeploymentBackup)
+		write.DELETE("/backup/:backup", s.handleDeleteDeploymentBackup)
 
 		// Deployment replication operator
 		api.GET("/deployment-replication", s.handleGetDeploymentReplications)
@@ -198,6 +245,12 @@ func NewServer(cli corev1.CoreV1Interface, cfg Config, deps Dependencies) (*Serv
 		// Local storage operator
 		api.GET("/storage", s.handleGetLocalStorages)
 		api.GET("/storage/:name", s.handleGetLocalStorageDetails)
+
+		// Backup operator
+		api.GET("/backup", s.handleGetBackups)
+		api.GET("/backup/:name", s.handleGetBackupDetails)
+		api.GET("/backup-policy", s.handleGetBackupPolicies)
+		api.GET("/backup-policy/:name", s.handleGetBackupPolicyDetails)
 	}
 	// Dashboard
 	r.GET("/", createAssetFileHandler(dashboard.Assets.Files["index.html"]))
@@ -210,6 +263,13 @@ func NewServer(cli corev1.CoreV1Interface, cfg Config, deps Dependencies) (*Serv
 	return s, nil
 }
 
+// writeGroup creates the group of API routes changing a deployment.
+// Authentication is always required, even when anonymous access is allowed,
+// and every request is audited, including the ones which are denied.
+func (s *Server) writeGroup(r *gin.Engine) *gin.RouterGroup {
+	return r.Group("/api/deployment/:name", s.audit, s.auth.checkAuthentication, requireAuthenticated)
+}
+
 // createAssetFileHandler creates a gin handler to serve the content
 // of the given asset file.
 func createAssetFileHandler(file *assets.File) func(c *gin.Context) {
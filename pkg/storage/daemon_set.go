@@ -76,6 +76,10 @@ func (ls *LocalStorage) ensureDaemonSet(apiObject *api.ArangoLocalStorage) error
 		},
 	}
 
+	if apiObject.Spec.GetQuota() {
+		c.Args = append(c.Args, "--enable-quota")
+	}
+
 	if apiObject.Spec.GetPrivileged() {
 		c.SecurityContext = &core.SecurityContext{
 			Privileged: util.NewBool(true),
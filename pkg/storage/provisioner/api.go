@@ -35,8 +35,9 @@ type API interface {
 	// GetInfo fetches information from the filesystem containing
 	// the given local path on the current node.
 	GetInfo(ctx context.Context, localPath string) (Info, error)
-	// Prepare a volume at the given local path
-	Prepare(ctx context.Context, localPath string) error
+	// Prepare a volume at the given local path.
+	// When quota is enabled, capacity (in bytes) is enforced on the volume.
+	Prepare(ctx context.Context, localPath string, capacity int64) error
 	// Remove a volume with the given local path
 	Remove(ctx context.Context, localPath string) error
 }
@@ -49,11 +50,20 @@ type NodeInfo struct {
 // Info holds information of a filesystem on a node.
 type Info struct {
 	NodeInfo
-	Available int64 `json:"available"`
-	Capacity  int64 `json:"capacity"`
+	Available int64      `json:"available"`
+	Capacity  int64      `json:"capacity"`
+	Quota     *QuotaInfo `json:"quota,omitempty"`
+}
+
+// QuotaInfo holds project quota usage of a local path.
+// For a local path root it holds the sum over all volumes below it.
+type QuotaInfo struct {
+	Used  int64 `json:"used"`
+	Limit int64 `json:"limit"`
 }
 
 // Request body for API HTTP requests.
 type Request struct {
 	LocalPath string `json:"localPath"`
+	Capacity  int64  `json:"capacity,omitempty"`
 }
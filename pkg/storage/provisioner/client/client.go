@@ -112,9 +112,10 @@ func (c *client) GetInfo(ctx context.Context, localPath string) (provisioner.Inf
 }
 
 // Prepare a volume at the given local path
-func (c *client) Prepare(ctx context.Context, localPath string) error {
+func (c *client) Prepare(ctx context.Context, localPath string, capacity int64) error {
 	input := provisioner.Request{
 		LocalPath: localPath,
+		Capacity:  capacity,
 	}
 	req, err := c.newRequest("POST", "/prepare", input)
 	if err != nil {
@@ -78,7 +78,7 @@ func (m *provisionerMock) GetInfo(ctx context.Context, localPath string) (provis
 }
 
 // Prepare a volume at the given local path
-func (m *provisionerMock) Prepare(ctx context.Context, localPath string) error {
+func (m *provisionerMock) Prepare(ctx context.Context, localPath string, capacity int64) error {
 	if _, found := m.localPaths[localPath]; found {
 		return errors.Newf("Path already exists: %s", localPath)
 	}
@@ -24,7 +24,10 @@ package service
 
 import (
 	"context"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"sync"
 
 	"github.com/arangodb/kube-arangodb/pkg/util/errors"
 
@@ -36,8 +39,9 @@ import (
 
 // Config for the storage provisioner
 type Config struct {
-	Address  string // Server address to listen on
-	NodeName string // Name of the run I'm running now
+	Address     string // Server address to listen on
+	NodeName    string // Name of the run I'm running now
+	EnableQuota bool   // If set, volumes are limited to their capacity using project quotas
 }
 
 // Dependencies for the storage provisioner
@@ -49,6 +53,8 @@ type Dependencies struct {
 type Provisioner struct {
 	Config
 	Dependencies
+
+	quotaLock sync.Mutex
 }
 
 // New creates a new local storage provisioner
@@ -89,6 +95,21 @@ func (p *Provisioner) GetInfo(ctx context.Context, localPath string) (provisione
 	// Capacity is total block count * fragment size
 	capacity := int64(statfs.Blocks) * statfs.Bsize // nolint:typecheck
 
+	quota, err := p.getQuotaInfo(localPath)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get quota")
+		return provisioner.Info{}, errors.WithStack(err)
+	}
+	if quota != nil {
+		// Space promised to volumes is not available, even if not used yet
+		if reserved := quota.Limit - quota.Used; reserved > 0 {
+			available -= reserved
+		}
+		if available < 0 {
+			available = 0
+		}
+	}
+
 	log.Debug().
 		Str("node-name", p.NodeName).
 		Int64("capacity", capacity).
@@ -100,15 +121,70 @@ func (p *Provisioner) GetInfo(ctx context.Context, localPath string) (provisione
 		},
 		Available: available,
 		Capacity:  capacity,
+		Quota:     quota,
 	}, nil
 }
 
+// getQuotaInfo returns the project quota of the given local path.
+// If the local path is not a volume, the quotas of all volumes directly below it are summed up.
+// Returns nil if quota is disabled or not supported by the filesystem.
+func (p *Provisioner) getQuotaInfo(localPath string) (*provisioner.QuotaInfo, error) {
+	if !p.EnableQuota {
+		return nil, nil
+	}
+	device, ok, err := quotaDevice(localPath)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	projectID, err := getProjectID(localPath)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if projectID >= quotaProjectIDBase {
+		q, err := getQuota(device, projectID)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return &q, nil
+	}
+
+	entries, err := ioutil.ReadDir(localPath)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var result provisioner.QuotaInfo
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		projectID, err := getProjectID(filepath.Join(localPath, e.Name()))
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if projectID < quotaProjectIDBase {
+			continue
+		}
+		q, err := getQuota(device, projectID)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		result.Used += q.Used
+		result.Limit += q.Limit
+	}
+	return &result, nil
+}
+
 // Prepare a volume at the given local path
-func (p *Provisioner) Prepare(ctx context.Context, localPath string) error {
-	log := p.Log.With().Str("local-path", localPath).Logger()
+func (p *Provisioner) Prepare(ctx context.Context, localPath string, capacity int64) error {
+	log := p.Log.With().Str("local-path", localPath).Int64("capacity", capacity).Logger()
 	log.Debug().Msg("preparing local path")
 
 	// Make sure directory is empty
+	if err := p.releaseQuota(localPath); err != nil {
+		log.Error().Err(err).Msg("Failed to release quota of existing directory")
+		return errors.WithStack(err)
+	}
 	if err := os.RemoveAll(localPath); err != nil && !os.IsNotExist(err) {
 		log.Error().Err(err).Msg("Failed to clean existing directory")
 		return errors.WithStack(err)
@@ -123,6 +199,73 @@ func (p *Provisioner) Prepare(ctx context.Context, localPath string) error {
 		log.Error().Err(err).Msg("Failed to set directory access")
 		return errors.WithStack(err)
 	}
+	// Limit directory size
+	if err := p.assignQuota(localPath, capacity); err != nil {
+		log.Error().Err(err).Msg("Failed to assign quota")
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// assignQuota limits the size of the given local path to the given capacity using a new project quota.
+// It does nothing if quota is disabled or not supported by the filesystem.
+func (p *Provisioner) assignQuota(localPath string, capacity int64) error {
+	if !p.EnableQuota || capacity <= 0 {
+		return nil
+	}
+	device, ok, err := quotaDevice(localPath)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if !ok {
+		p.Log.Warn().Str("local-path", localPath).Msg("Filesystem does not support or enforce project quota (prjquota), volume size is not enforced")
+		return nil
+	}
+
+	p.quotaLock.Lock()
+	defer p.quotaLock.Unlock()
+
+	projectID, err := nextProjectID(device)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err := setProjectID(localPath, projectID); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := setQuota(device, projectID, capacity); err != nil {
+		return errors.WithStack(err)
+	}
+	p.Log.Debug().Str("local-path", localPath).Uint32("project-id", projectID).Msg("Assigned quota")
+	return nil
+}
+
+// releaseQuota removes the project quota limit of the given local path (if any).
+func (p *Provisioner) releaseQuota(localPath string) error {
+	if !p.EnableQuota {
+		return nil
+	}
+	if _, err := os.Stat(localPath); os.IsNotExist(err) {
+		return nil
+	}
+	device, ok, err := quotaDevice(localPath)
+	if err != nil || !ok {
+		return err
+	}
+
+	p.quotaLock.Lock()
+	defer p.quotaLock.Unlock()
+
+	projectID, err := getProjectID(localPath)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if projectID < quotaProjectIDBase {
+		return nil
+	}
+	if err := setQuota(device, projectID, 0); err != nil {
+		return errors.WithStack(err)
+	}
+	p.Log.Debug().Str("local-path", localPath).Uint32("project-id", projectID).Msg("Released quota")
 	return nil
 }
 
@@ -131,6 +274,11 @@ func (p *Provisioner) Remove(ctx context.Context, localPath string) error {
 	log := p.Log.With().Str("local-path", localPath).Logger()
 	log.Debug().Msg("cleanup local path")
 
+	// Release quota before the project ID is lost with the directory
+	if err := p.releaseQuota(localPath); err != nil {
+		log.Error().Err(err).Msg("Failed to release quota")
+		return errors.WithStack(err)
+	}
 	// Make sure directory is empty
 	if err := os.RemoveAll(localPath); err != nil && !os.IsNotExist(err) {
 		log.Error().Err(err).Msg("Failed to clean directory")
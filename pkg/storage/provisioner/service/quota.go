@@ -0,0 +1,129 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package service
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/arangodb/kube-arangodb/pkg/util/errors"
+)
+
+const (
+	// quotaProjectIDBase is the first project ID assigned to a volume.
+	// IDs below it are left to the administrator of the node.
+	quotaProjectIDBase uint32 = 1000
+	// quotaProjectIDMax is the last project ID assigned to a volume.
+	quotaProjectIDMax uint32 = quotaProjectIDBase + 1<<20
+
+	mountInfoPath = "/proc/self/mountinfo"
+)
+
+// quotaFilesystems lists filesystems supporting project quotas via the generic quotactl interface.
+var quotaFilesystems = map[string]bool{
+	"xfs":  true,
+	"ext4": true,
+}
+
+// quotaMountOptions lists mount options enabling project quota enforcement.
+var quotaMountOptions = map[string]bool{
+	"prjquota": true,
+	"pquota":   true,
+}
+
+// mountInfo holds the parts of a /proc/self/mountinfo entry needed for quota handling.
+type mountInfo struct {
+	MountPoint string
+	FSType     string
+	Source     string
+	Options    []string
+}
+
+// parseMountInfo parses the content of /proc/self/mountinfo.
+func parseMountInfo(r io.Reader) ([]mountInfo, error) {
+	var result []mountInfo
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		// Optional fields are terminated by a single "-"
+		sep := -1
+		for i := 6; i < len(fields); i++ {
+			if fields[i] == "-" {
+				sep = i
+				break
+			}
+		}
+		if len(fields) < 5 || sep < 0 || sep+2 >= len(fields) {
+			return nil, errors.Newf("Invalid mountinfo line: %s", scanner.Text())
+		}
+		m := mountInfo{
+			MountPoint: unescapeMountInfo(fields[4]),
+			FSType:     fields[sep+1],
+			Source:     unescapeMountInfo(fields[sep+2]),
+		}
+		// Quota options are reported as super options, but keep the per mount ones as well
+		m.Options = append(m.Options, strings.Split(fields[5], ",")...)
+		if sep+3 < len(fields) {
+			m.Options = append(m.Options, strings.Split(fields[sep+3], ",")...)
+		}
+		result = append(result, m)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return result, nil
+}
+
+// unescapeMountInfo replaces the octal escapes the kernel uses for whitespace and backslashes.
+func unescapeMountInfo(s string) string {
+	return strings.NewReplacer(`\040`, " ", `\011`, "\t", `\012`, "\n", `\134`, `\`).Replace(s)
+}
+
+// findMount returns the mount containing the given (absolute, symlink free) path.
+func findMount(mounts []mountInfo, path string) (mountInfo, bool) {
+	var result mountInfo
+	found := false
+	for _, m := range mounts {
+		if m.MountPoint != "/" && path != m.MountPoint && !strings.HasPrefix(path, m.MountPoint+"/") {
+			continue
+		}
+		// Later entries overmount earlier ones, so prefer them on equal length
+		if !found || len(m.MountPoint) >= len(result.MountPoint) {
+			result = m
+			found = true
+		}
+	}
+	return result, found
+}
+
+// hasProjectQuota returns true when the mount supports and enforces project quotas.
+func (m mountInfo) hasProjectQuota() bool {
+	if !quotaFilesystems[m.FSType] || !strings.HasPrefix(m.Source, "/dev/") {
+		return false
+	}
+	for _, o := range m.Options {
+		if quotaMountOptions[o] {
+			return true
+		}
+	}
+	return false
+}
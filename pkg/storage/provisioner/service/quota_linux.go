@@ -0,0 +1,218 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+//go:build linux
+// +build linux
+
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/arangodb/kube-arangodb/pkg/storage/provisioner"
+	"github.com/arangodb/kube-arangodb/pkg/util/errors"
+)
+
+const (
+	// quotaBlockSize is the unit of the block limits in if_dqblk (QIF_DQBLKSIZE).
+	quotaBlockSize = 1024
+)
+
+// Linux ioctl & quotactl constants (see linux/fs.h and linux/quota.h).
+const (
+	fsIocFsGetXAttr = 0x801c581f
+	fsIocFsSetXAttr = 0x401c5820
+
+	fsXFlagProjInherit = 0x00000200
+
+	qGetQuota = 0x800007
+	qSetQuota = 0x800008
+
+	prjQuota = 2
+
+	qifBLimits = 1
+	qifILimits = 4
+	qifLimits  = qifBLimits | qifILimits
+)
+
+// fsXAttr mirrors struct fsxattr.
+type fsXAttr struct {
+	XFlags     uint32
+	ExtSize    uint32
+	NExtents   uint32
+	ProjID     uint32
+	CowExtSize uint32
+	Pad        [8]byte
+}
+
+// diskQuota mirrors struct if_dqblk.
+type diskQuota struct {
+	BHardLimit uint64
+	BSoftLimit uint64
+	CurSpace   uint64
+	IHardLimit uint64
+	ISoftLimit uint64
+	CurInodes  uint64
+	BTime      uint64
+	ITime      uint64
+	Valid      uint32
+}
+
+// quotaDevice returns the block device backing the given path,
+// or false when the filesystem does not support project quotas
+// or is mounted without project quota enforcement.
+func quotaDevice(localPath string) (string, bool, error) {
+	path, err := filepath.EvalSymlinks(localPath)
+	if err != nil {
+		return "", false, errors.WithStack(err)
+	}
+	f, err := os.Open(mountInfoPath)
+	if err != nil {
+		return "", false, errors.WithStack(err)
+	}
+	defer f.Close()
+	mounts, err := parseMountInfo(f)
+	if err != nil {
+		return "", false, errors.WithStack(err)
+	}
+	m, found := findMount(mounts, path)
+	if !found || !m.hasProjectQuota() {
+		return "", false, nil
+	}
+	return m.Source, true, nil
+}
+
+// getProjectID returns the project ID of the given directory.
+func getProjectID(dir string) (uint32, error) {
+	attr, err := getFSXAttr(dir)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	return attr.ProjID, nil
+}
+
+// setProjectID assigns the given project ID to the given directory
+// and makes new entries inside of it inherit the ID.
+func setProjectID(dir string, projectID uint32) error {
+	attr, err := getFSXAttr(dir)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	attr.ProjID = projectID
+	attr.XFlags |= fsXFlagProjInherit
+	return withDir(dir, func(fd uintptr) error {
+		return ioctl(fd, fsIocFsSetXAttr, uintptr(unsafe.Pointer(&attr)))
+	})
+}
+
+func getFSXAttr(dir string) (fsXAttr, error) {
+	var attr fsXAttr
+	err := withDir(dir, func(fd uintptr) error {
+		return ioctl(fd, fsIocFsGetXAttr, uintptr(unsafe.Pointer(&attr)))
+	})
+	return attr, err
+}
+
+func withDir(dir string, f func(fd uintptr) error) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer d.Close()
+	return f(d.Fd())
+}
+
+func ioctl(fd, req, arg uintptr) error {
+	if _, _, errno := unix.Syscall(unix.SYS_IOCTL, fd, req, arg); errno != 0 {
+		return errors.WithStack(errno)
+	}
+	return nil
+}
+
+// nextProjectID returns a project ID not used on the filesystem of the given device.
+// Project IDs are global per filesystem, so the quota subsystem is asked instead of
+// looking at neighbouring directories only.
+func nextProjectID(device string) (uint32, error) {
+	for id := quotaProjectIDBase; id <= quotaProjectIDMax; id++ {
+		used, err := isProjectIDUsed(device, id)
+		if err != nil {
+			return 0, errors.WithStack(err)
+		}
+		if !used {
+			return id, nil
+		}
+	}
+	return 0, errors.Newf("No free project ID on %s", device)
+}
+
+// isProjectIDUsed returns true when the given project ID has a limit set
+// or owns any files on the filesystem of the given device.
+func isProjectIDUsed(device string, projectID uint32) (bool, error) {
+	var dq diskQuota
+	if err := quotactl(qGetQuota, device, projectID, &dq); err != nil {
+		if cause := errors.Cause(err); cause == unix.ENOENT || cause == unix.ESRCH {
+			// No quota structure exists for the ID
+			return false, nil
+		}
+		return false, errors.WithStack(err)
+	}
+	return dq.BHardLimit != 0 || dq.BSoftLimit != 0 || dq.IHardLimit != 0 || dq.ISoftLimit != 0 ||
+		dq.CurSpace != 0 || dq.CurInodes != 0, nil
+}
+
+// getQuota returns the project quota of the given project ID.
+func getQuota(device string, projectID uint32) (provisioner.QuotaInfo, error) {
+	var dq diskQuota
+	if err := quotactl(qGetQuota, device, projectID, &dq); err != nil {
+		return provisioner.QuotaInfo{}, errors.WithStack(err)
+	}
+	return provisioner.QuotaInfo{
+		Used:  int64(dq.CurSpace),
+		Limit: int64(dq.BHardLimit) * quotaBlockSize,
+	}, nil
+}
+
+// setQuota sets the hard block limit (in bytes) of the given project ID.
+// A limit of 0 removes the limit.
+func setQuota(device string, projectID uint32, limit int64) error {
+	blocks := uint64((limit + quotaBlockSize - 1) / quotaBlockSize)
+	dq := diskQuota{
+		BHardLimit: blocks,
+		BSoftLimit: blocks,
+		Valid:      qifLimits,
+	}
+	return quotactl(qSetQuota, device, projectID, &dq)
+}
+
+func quotactl(cmd int, device string, id uint32, dq *diskQuota) error {
+	dev, err := unix.BytePtrFromString(device)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	qcmd := uintptr(cmd<<8 | prjQuota&0xff)
+	if _, _, errno := unix.Syscall6(unix.SYS_QUOTACTL, qcmd, uintptr(unsafe.Pointer(dev)), uintptr(id), uintptr(unsafe.Pointer(dq)), 0, 0); errno != 0 {
+		return errors.WithStack(errno)
+	}
+	return nil
+}
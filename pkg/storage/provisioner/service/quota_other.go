@@ -0,0 +1,56 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+//go:build !linux
+// +build !linux
+
+package service
+
+import (
+	"github.com/arangodb/kube-arangodb/pkg/storage/provisioner"
+	"github.com/arangodb/kube-arangodb/pkg/util/errors"
+)
+
+var errQuotaNotSupported = errors.New("Project quotas are only supported on linux")
+
+// quotaDevice always reports project quotas as unsupported.
+func quotaDevice(localPath string) (string, bool, error) {
+	return "", false, nil
+}
+
+func getProjectID(dir string) (uint32, error) {
+	return 0, errQuotaNotSupported
+}
+
+func setProjectID(dir string, projectID uint32) error {
+	return errQuotaNotSupported
+}
+
+func nextProjectID(device string) (uint32, error) {
+	return 0, errQuotaNotSupported
+}
+
+func getQuota(device string, projectID uint32) (provisioner.QuotaInfo, error) {
+	return provisioner.QuotaInfo{}, errQuotaNotSupported
+}
+
+func setQuota(device string, projectID uint32, limit int64) error {
+	return errQuotaNotSupported
+}
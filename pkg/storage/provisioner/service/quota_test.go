@@ -0,0 +1,125 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testMountInfo = `22 1 8:1 / / rw,relatime shared:1 - ext4 /dev/sda1 rw
+25 22 0:5 / /dev rw,nosuid master:2 - devtmpfs udev rw
+40 22 7:0 / /mnt/local rw,relatime shared:20 - xfs /dev/loop0 rw,prjquota
+41 40 0:40 / /mnt/local/with\040space rw - tmpfs tmpfs rw
+`
+
+func TestParseMountInfo(t *testing.T) {
+	mounts, err := parseMountInfo(strings.NewReader(testMountInfo))
+	require.NoError(t, err)
+	require.Len(t, mounts, 4)
+
+	assert.Equal(t, mountInfo{MountPoint: "/", FSType: "ext4", Source: "/dev/sda1", Options: []string{"rw", "relatime", "rw"}}, mounts[0])
+	assert.Equal(t, mountInfo{MountPoint: "/mnt/local", FSType: "xfs", Source: "/dev/loop0", Options: []string{"rw", "relatime", "rw", "prjquota"}}, mounts[2])
+	assert.Equal(t, "/mnt/local/with space", mounts[3].MountPoint)
+
+	_, err = parseMountInfo(strings.NewReader("invalid line\n"))
+	assert.Error(t, err)
+}
+
+func TestFindMount(t *testing.T) {
+	mounts, err := parseMountInfo(strings.NewReader(testMountInfo))
+	require.NoError(t, err)
+
+	m, ok := findMount(mounts, "/mnt/local/abc")
+	require.True(t, ok)
+	assert.Equal(t, "/dev/loop0", m.Source)
+
+	m, ok = findMount(mounts, "/mnt/localx")
+	require.True(t, ok)
+	assert.Equal(t, "/", m.MountPoint)
+
+	m, ok = findMount(mounts, "/mnt/local/with space/abc")
+	require.True(t, ok)
+	assert.Equal(t, "tmpfs", m.FSType)
+}
+
+func TestMountHasProjectQuota(t *testing.T) {
+	mounts, err := parseMountInfo(strings.NewReader(testMountInfo))
+	require.NoError(t, err)
+
+	assert.False(t, mounts[0].hasProjectQuota(), "ext4 without prjquota")
+	assert.False(t, mounts[1].hasProjectQuota(), "devtmpfs")
+	assert.True(t, mounts[2].hasProjectQuota(), "xfs with prjquota")
+	assert.False(t, mounts[3].hasProjectQuota(), "tmpfs")
+
+	assert.True(t, mountInfo{FSType: "xfs", Source: "/dev/sdb", Options: []string{"rw", "pquota"}}.hasProjectQuota())
+	assert.False(t, mountInfo{FSType: "xfs", Source: "/dev/sdb", Options: []string{"rw", "usrquota"}}.hasProjectQuota())
+}
+
+// TestQuota runs against a filesystem mounted with project quotas, e.g.
+//   truncate -s 512M /tmp/xfs.img && mkfs.xfs /tmp/xfs.img
+//   mount -o loop,prjquota /tmp/xfs.img /mnt/quota
+//   TEST_QUOTA_PATH=/mnt/quota go test ./pkg/storage/provisioner/service/
+func TestQuota(t *testing.T) {
+	root := os.Getenv("TEST_QUOTA_PATH")
+	if root == "" {
+		t.Skip("TEST_QUOTA_PATH not set")
+	}
+
+	ctx := context.Background()
+	p, err := New(Config{NodeName: "test", EnableQuota: true}, Dependencies{Log: zerolog.Nop()})
+	require.NoError(t, err)
+
+	localPath := filepath.Join(root, "vol")
+	const capacity = 16 * 1024 * 1024
+	require.NoError(t, p.Prepare(ctx, localPath, capacity))
+
+	info, err := p.GetInfo(ctx, localPath)
+	require.NoError(t, err)
+	require.NotNil(t, info.Quota)
+	assert.EqualValues(t, capacity, info.Quota.Limit)
+
+	rootInfo, err := p.GetInfo(ctx, root)
+	require.NoError(t, err)
+	require.NotNil(t, rootInfo.Quota)
+	assert.EqualValues(t, capacity, rootInfo.Quota.Limit)
+
+	// Writing beyond the capacity must fail
+	f, err := os.Create(filepath.Join(localPath, "data"))
+	require.NoError(t, err)
+	_, err = f.Write(make([]byte, 2*capacity))
+	f.Close()
+	assert.Error(t, err)
+
+	require.NoError(t, p.Remove(ctx, localPath))
+
+	rootInfo, err = p.GetInfo(ctx, root)
+	require.NoError(t, err)
+	require.NotNil(t, rootInfo.Quota)
+	assert.EqualValues(t, 0, rootInfo.Quota.Limit)
+}
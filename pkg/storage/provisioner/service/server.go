@@ -110,7 +110,7 @@ func getPrepareHandler(api provisioner.API) func(w http.ResponseWriter, r *http.
 		if err := parseBody(r, &input); err != nil {
 			handleError(w, err)
 		} else {
-			if err := api.Prepare(ctx, input.LocalPath); err != nil {
+			if err := api.Prepare(ctx, input.LocalPath, input.Capacity); err != nil {
 				handleError(w, err)
 			} else {
 				sendJSON(w, struct{}{})
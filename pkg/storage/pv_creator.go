@@ -139,7 +139,7 @@ func (ls *LocalStorage) createPV(ctx context.Context, apiObject *api.ArangoLocal
 			name := strings.ToLower(uniuri.New())
 			localPath := filepath.Join(localPathRoot, name)
 			log = log.With().Str("local-path", localPath).Logger()
-			if err := client.Prepare(ctx, localPath); err != nil {
+			if err := client.Prepare(ctx, localPath, volSize); err != nil {
 				log.Error().Err(err).Msg("Failed to prepare local path")
 				continue
 			}
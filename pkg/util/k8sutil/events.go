@@ -258,6 +258,24 @@ func NewUpgradeNotAllowedEvent(apiObject APIObject,
 	return event
 }
 
+// NewReplicationActionStartedEvent creates an event indicating that a replication action (switchover/failover) has started.
+func NewReplicationActionStartedEvent(apiObject APIObject, action string) *Event {
+	event := newDeploymentEvent(apiObject)
+	event.Type = v1.EventTypeNormal
+	event.Reason = fmt.Sprintf("%s Started", action)
+	event.Message = fmt.Sprintf("%s of deployment replication %s has started", action, apiObject.GetName())
+	return event
+}
+
+// NewReplicationActionFinishedEvent creates an event indicating that a replication action (switchover/failover) has finished.
+func NewReplicationActionFinishedEvent(apiObject APIObject, action string) *Event {
+	event := newDeploymentEvent(apiObject)
+	event.Type = v1.EventTypeNormal
+	event.Reason = fmt.Sprintf("%s Finished", action)
+	event.Message = fmt.Sprintf("%s of deployment replication %s has finished, source and destination are swapped", action, apiObject.GetName())
+	return event
+}
+
 // NewErrorEvent creates an even of type error.
 func NewErrorEvent(reason string, err error, apiObject APIObject) *Event {
 	event := newDeploymentEvent(apiObject)
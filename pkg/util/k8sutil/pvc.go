@@ -52,6 +52,27 @@ func IsPersistentVolumeClaimFileSystemResizePending(pvc *v1.PersistentVolumeClai
 	return false
 }
 
+// IsPersistentVolumeNodeLocal returns true if the volume is stored on a single node,
+// so pods using it cannot be started on another node.
+func IsPersistentVolumeNodeLocal(pv *v1.PersistentVolume) bool {
+	if pv.Spec.Local != nil || pv.Spec.HostPath != nil {
+		return true
+	}
+
+	if pv.Spec.NodeAffinity == nil || pv.Spec.NodeAffinity.Required == nil {
+		return false
+	}
+
+	for _, term := range pv.Spec.NodeAffinity.Required.NodeSelectorTerms {
+		for _, e := range term.MatchExpressions {
+			if e.Key == TopologyKeyHostname {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // CreatePersistentVolumeClaimName returns the name of the persistent volume claim for a member with
 // a given id in a deployment with a given name.
 func CreatePersistentVolumeClaimName(deploymentName, role, id string) string {
@@ -0,0 +1,372 @@
+//
+// DISCLAIMER
+//
+// Copyright 2016-2021 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	yamlutil "k8s.io/apimachinery/pkg/util/yaml"
+
+	v12 "github.com/arangodb/kube-arangodb/pkg/apis/deployment/v1"
+	extclient "github.com/arangodb/kube-arangodb/pkg/client"
+	"github.com/arangodb/kube-arangodb/pkg/deployment"
+	"github.com/arangodb/kube-arangodb/pkg/deployment/agency"
+	"github.com/arangodb/kube-arangodb/pkg/util/constants"
+	"github.com/arangodb/kube-arangodb/pkg/util/k8sutil"
+)
+
+const (
+	ArgPlanFile          = "file"
+	ArgPlanSpec          = "spec"
+	ArgPlanAgencyState   = "agency-state"
+	ArgPlanShardsInSync  = "shards-in-sync"
+	ArgPlanOperatorImage = "operator-image"
+)
+
+func init() {
+	cmdMain.AddCommand(cmdPlan)
+	cmdPlan.AddCommand(cmdPlanSimulate)
+
+	f := cmdPlanSimulate.Flags()
+	f.StringP(ArgDeploymentName, "d", "",
+		"necessary when more than one deployment exist within on namespace")
+	f.StringSliceP(ArgPlanFile, "f", nil,
+		"YAML or JSON files with the ArangoDeployment, ArangoMembers, Pods, PersistentVolumeClaims, Secrets and Services. "+
+			"When not set, the objects are read from the cluster")
+	f.String(ArgPlanSpec, "", "YAML or JSON file with the ArangoDeployment holding the proposed spec")
+	f.String(ArgPlanAgencyState, "", "JSON file with the agency state, e.g. the output of \"admin agency state\"")
+	f.Bool(ArgPlanShardsInSync, true, "assume that all shards are in sync")
+	f.String(ArgPlanOperatorImage, "", "operator image used to render pods (default image of the operator pod)")
+	f.StringP(ArgOutput, "o", outputTable, "output format, one of: table, json")
+}
+
+var cmdPlan = &cobra.Command{
+	Use:   "plan",
+	Short: "Plan operations",
+	Run:   planShowUsage,
+}
+
+var cmdPlanSimulate = &cobra.Command{
+	Use:   "simulate",
+	Short: "Simulate plan",
+	Long: "It prints the high priority plan and the plan which the operator would create for the proposed spec. " +
+		"Nothing is written to the cluster and no connections to ArangoDB servers are made",
+	Run: cmdPlanSimulateRun,
+}
+
+// simulationOutput is the JSON output of the plan simulation.
+type simulationOutput struct {
+	ResetFields []string         `json:"resetFields,omitempty"`
+	Actions     []planActionInfo `json:"actions"`
+}
+
+func planShowUsage(cmd *cobra.Command, _ []string) {
+	cmd.Usage()
+}
+
+func cmdPlanSimulateRun(cmd *cobra.Command, _ []string) {
+	output := getOutputFormat(cmd)
+	files, _ := cmd.Flags().GetStringSlice(ArgPlanFile)
+	specFile, _ := cmd.Flags().GetString(ArgPlanSpec)
+	agencyFile, _ := cmd.Flags().GetString(ArgPlanAgencyState)
+	shardsInSync, _ := cmd.Flags().GetBool(ArgPlanShardsInSync)
+	operatorImage, _ := cmd.Flags().GetString(ArgPlanOperatorImage)
+	ctx := getInterruptionContext()
+
+	input := deployment.SimulationInput{ShardsInSync: shardsInSync}
+
+	if len(files) > 0 {
+		for _, fileName := range files {
+			if err := loadSimulationFile(fileName, &input); err != nil {
+				cliLog.Fatal().Err(err).Str("file", fileName).Msg("failed to load objects")
+			}
+		}
+		if input.Deployment == nil {
+			cliLog.Fatal().Msg("no ArangoDeployment found in the given files")
+		}
+	} else {
+		deploymentName, _ := cmd.Flags().GetString(ArgDeploymentName)
+		if err := loadSimulationCluster(ctx, deploymentName, &input); err != nil {
+			cliLog.Fatal().Err(err).Msg("failed to load objects from the cluster")
+		}
+	}
+
+	if specFile != "" {
+		var proposed deployment.SimulationInput
+		if err := loadSimulationFile(specFile, &proposed); err != nil {
+			cliLog.Fatal().Err(err).Str("file", specFile).Msg("failed to load proposed spec")
+		}
+		if proposed.Deployment == nil {
+			cliLog.Fatal().Str("file", specFile).Msg("no ArangoDeployment found in the proposed spec file")
+		}
+		input.Spec = &proposed.Deployment.Spec
+	}
+
+	if agencyFile != "" {
+		state, err := loadAgencyState(agencyFile)
+		if err != nil {
+			cliLog.Fatal().Err(err).Str("file", agencyFile).Msg("failed to load agency state")
+		}
+		input.AgencyState = &state
+	}
+
+	if operatorImage == "" {
+		operatorImage = getOperatorImage()
+	}
+	input.Config = deployment.Config{
+		OperatorImage: operatorImage,
+		ArangoImage:   input.Deployment.Spec.GetImage(),
+	}
+
+	result, err := deployment.SimulatePlan(ctx, zerolog.New(ioutil.Discard), input)
+	if err != nil {
+		cliLog.Fatal().Err(err).Msg("failed to simulate plan")
+	}
+
+	actions := getPlanActions(result.HighPriorityPlan, result.Plan)
+	if output == outputJSON {
+		err = printOutput(output, simulationOutput{ResetFields: result.ResetFields, Actions: actions}, nil, nil)
+	} else {
+		for _, field := range result.ResetFields {
+			cliLog.Warn().Str("field", field).Msg("immutable field would be reset")
+		}
+		err = printPlanActions(output, actions)
+	}
+	if err != nil {
+		cliLog.Fatal().Err(err).Msg("failed to print plan")
+	}
+}
+
+// getOperatorImage returns the image of the operator pod in which the command is running, if any.
+func getOperatorImage() string {
+	namespace := os.Getenv(constants.EnvOperatorPodNamespace)
+	podName := os.Getenv(constants.EnvOperatorPodName)
+	if namespace == "" || podName == "" {
+		return ""
+	}
+
+	kubeCli, err := k8sutil.NewKubeClient()
+	if err != nil {
+		return ""
+	}
+
+	image, err := getMyImage(kubeCli, namespace, podName)
+	if err != nil {
+		cliLog.Warn().Err(err).Msg("failed to get operator image")
+		return ""
+	}
+
+	return image
+}
+
+// loadSimulationCluster reads the deployment and its objects from the cluster. Nothing is modified.
+func loadSimulationCluster(ctx context.Context, deploymentName string, input *deployment.SimulationInput) error {
+	namespace, err := getNamespace()
+	if err != nil {
+		return err
+	}
+
+	d, err := getDeployment(ctx, namespace, deploymentName)
+	if err != nil {
+		return errors.WithMessage(err, "failed to get deployment")
+	}
+	input.Deployment = &d
+
+	kubeCli, err := k8sutil.NewKubeClient()
+	if err != nil {
+		return errors.WithMessage(err, "failed to create Kubernetes client")
+	}
+
+	extCli, err := extclient.NewClient()
+	if err != nil {
+		return errors.WithMessage(err, "failed to create Arango extension client")
+	}
+
+	members, err := extCli.DatabaseV1().ArangoMembers(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return errors.WithMessage(err, "failed to list members")
+	}
+	for _, m := range members.Items {
+		if m.Spec.DeploymentUID == "" || m.Spec.DeploymentUID == d.GetUID() {
+			input.Members = append(input.Members, m)
+		}
+	}
+
+	selector := metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", k8sutil.LabelKeyArangoDeployment, d.GetName()),
+	}
+
+	pods, err := kubeCli.CoreV1().Pods(namespace).List(ctx, selector)
+	if err != nil {
+		return errors.WithMessage(err, "failed to list pods")
+	}
+	input.Pods = pods.Items
+
+	pvcs, err := kubeCli.CoreV1().PersistentVolumeClaims(namespace).List(ctx, selector)
+	if err != nil {
+		return errors.WithMessage(err, "failed to list persistent volume claims")
+	}
+	input.PersistentVolumeClaims = pvcs.Items
+
+	services, err := kubeCli.CoreV1().Services(namespace).List(ctx, selector)
+	if err != nil {
+		return errors.WithMessage(err, "failed to list services")
+	}
+	input.Services = services.Items
+
+	// Secrets are needed to render pods (e.g. checksums of the JWT and TLS secrets)
+	secrets, err := kubeCli.CoreV1().Secrets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return errors.WithMessage(err, "failed to list secrets")
+	}
+	input.Secrets = secrets.Items
+
+	return nil
+}
+
+// loadSimulationFile reads the objects from the given YAML or JSON file.
+// The file may contain multiple documents and lists of objects.
+func loadSimulationFile(fileName string, input *deployment.SimulationInput) error {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer f.Close()
+
+	decoder := yamlutil.NewYAMLOrJSONDecoder(f, 4096)
+	for {
+		var data json.RawMessage
+		if err := decoder.Decode(&data); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return errors.WithStack(err)
+		}
+
+		if len(data) == 0 || string(data) == "null" {
+			continue
+		}
+
+		if err := addSimulationObject(data, input); err != nil {
+			return err
+		}
+	}
+}
+
+// addSimulationObject adds the object to the simulation input based on its kind.
+func addSimulationObject(data []byte, input *deployment.SimulationInput) error {
+	var meta struct {
+		Kind  string            `json:"kind"`
+		Items []json.RawMessage `json:"items"`
+	}
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return errors.WithStack(err)
+	}
+
+	switch meta.Kind {
+	case "ArangoDeployment":
+		var d v12.ArangoDeployment
+		if err := json.Unmarshal(data, &d); err != nil {
+			return errors.WithStack(err)
+		}
+		if input.Deployment != nil {
+			return errors.Errorf("more than one ArangoDeployment given: %s, %s", input.Deployment.GetName(), d.GetName())
+		}
+		input.Deployment = &d
+	case "ArangoMember":
+		var m v12.ArangoMember
+		if err := json.Unmarshal(data, &m); err != nil {
+			return errors.WithStack(err)
+		}
+		input.Members = append(input.Members, m)
+	case "Pod":
+		var p core.Pod
+		if err := json.Unmarshal(data, &p); err != nil {
+			return errors.WithStack(err)
+		}
+		input.Pods = append(input.Pods, p)
+	case "PersistentVolumeClaim":
+		var p core.PersistentVolumeClaim
+		if err := json.Unmarshal(data, &p); err != nil {
+			return errors.WithStack(err)
+		}
+		input.PersistentVolumeClaims = append(input.PersistentVolumeClaims, p)
+	case "Secret":
+		var s core.Secret
+		if err := json.Unmarshal(data, &s); err != nil {
+			return errors.WithStack(err)
+		}
+		input.Secrets = append(input.Secrets, s)
+	case "Service":
+		var s core.Service
+		if err := json.Unmarshal(data, &s); err != nil {
+			return errors.WithStack(err)
+		}
+		input.Services = append(input.Services, s)
+	default:
+		if strings.HasSuffix(meta.Kind, "List") {
+			for _, item := range meta.Items {
+				if err := addSimulationObject(item, input); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		cliLog.Warn().Str("kind", meta.Kind).Msg("ignoring object of unsupported kind")
+	}
+
+	return nil
+}
+
+// loadAgencyState reads the agency state from the given JSON file.
+// Both the agency read response and the plain state of the "arango" key are accepted.
+func loadAgencyState(fileName string) (agency.State, error) {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return agency.State{}, errors.WithStack(err)
+	}
+
+	var roots agency.StateRoots
+	if err := json.Unmarshal(data, &roots); err == nil {
+		if len(roots) != 1 {
+			return agency.State{}, errors.New("unexpected agency read response")
+		}
+		return roots[0].Arango, nil
+	}
+
+	var root agency.StateRoot
+	if err := json.Unmarshal(data, &root); err != nil {
+		return agency.State{}, errors.WithStack(err)
+	}
+
+	return root.Arango, nil
+}
@@ -28,11 +28,16 @@ import (
 	"net/http"
 	"os"
 	"path"
+	"sort"
 	"strings"
 	"sync"
+	"text/tabwriter"
+
+	"github.com/ghodss/yaml"
 
 	"k8s.io/apimachinery/pkg/util/intstr"
 
+	"github.com/arangodb/kube-arangodb/pkg/apis/deployment"
 	deplv1 "github.com/arangodb/kube-arangodb/pkg/apis/deployment/v1"
 	extclient "github.com/arangodb/kube-arangodb/pkg/client"
 	acli "github.com/arangodb/kube-arangodb/pkg/generated/clientset/versioned"
@@ -60,6 +65,11 @@ var (
 		ImageName         string
 		LicenseSecretName string
 		Coordinators      int
+		Agents            int
+		DryRun            bool
+		Output            string
+		FromDeployment    string
+		Template          string
 	}
 
 	cmdRebootInspect = &cobra.Command{
@@ -80,6 +90,11 @@ func init() {
 	cmdReboot.Flags().StringVar(&rebootOptions.ImageName, "image-name", "arangodb/arangodb:latest", "Image used for the deployment")
 	cmdReboot.Flags().StringVar(&rebootOptions.LicenseSecretName, "license-secret-name", "", "Name of secret for license key")
 	cmdReboot.Flags().IntVar(&rebootOptions.Coordinators, "coordinators", 1, "Initial number of coordinators")
+	cmdReboot.Flags().IntVar(&rebootOptions.Agents, "agents", 3, "Expected number of agents of the agency")
+	cmdReboot.Flags().BoolVar(&rebootOptions.DryRun, "dry-run", false, "Inspect local volumes without binding them and print the generated ArangoDeployment without creating it")
+	cmdReboot.Flags().StringVar(&rebootOptions.Output, "output", "", "Write the generated ArangoDeployment YAML to the given file")
+	cmdReboot.Flags().StringVar(&rebootOptions.FromDeployment, "from-deployment", "", "Discover volumes by the labels of the given (lost) deployment")
+	cmdReboot.Flags().StringVar(&rebootOptions.Template, "template", "", "ArangoDeployment YAML file whose spec is merged into the generated deployment")
 
 	cmdRebootInspect.Flags().StringVar(&rebootInspectOptions.TargetDir, "target-dir", "/data", "Path to mounted database directory")
 }
@@ -94,45 +109,86 @@ type inspectResponse struct {
 }
 
 type VolumeInspectResult struct {
-	UUID  string
-	Claim string
-	Error error
+	Volume string
+	UUID   string
+	Claim  string
+	Error  error
+	// LabelGroup is the server group from the role label of a discovered volume
+	LabelGroup deplv1.ServerGroup
 }
 
-func runVolumeInspector(ctx context.Context, kube kubernetes.Interface, ns, name, image, storageClassName string) (string, string, error) {
+// Role returns the server group of the member which owns the volume, based on the prefix of its UUID.
+func (v VolumeInspectResult) Role() (deplv1.ServerGroup, bool) {
+	switch {
+	case strings.HasPrefix(v.UUID, "PRMR"):
+		return deplv1.ServerGroupDBServers, true
+	case strings.HasPrefix(v.UUID, "AGNT"):
+		return deplv1.ServerGroupAgents, true
+	case strings.HasPrefix(v.UUID, "SNGL"):
+		return deplv1.ServerGroupSingle, true
+	default:
+		return deplv1.ServerGroupUnknown, false
+	}
+}
 
-	deletePVC := true
-	claimname := "arangodb-reboot-pvc-" + name
-	pvcspec := corev1.PersistentVolumeClaim{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: claimname,
-			Labels: map[string]string{
-				"app":      "arangodb",
-				"rebooted": "yes",
+// runVolumeInspector inspects the volume with a temporary pod and returns the UUID of the member and the claim
+// bound to the volume. In dry-run mode no claim is bound, the volume is mounted read-only from the node
+// instead, which is possible for local volumes only.
+func runVolumeInspector(ctx context.Context, kube kubernetes.Interface, ns, name, image string, vinfo VolumeInfo, dryRun bool) (string, string, error) {
+	var volume corev1.Volume
+	var affinity *corev1.Affinity
+	// In dry-run mode the claim is not created, but its name is reported as it would be bound by the real run
+	claimname := rebootClaimName(name)
+	deletePVC := false
+
+	if dryRun {
+		if vinfo.HostPath == "" {
+			return "", "", fmt.Errorf("volume %s is not a local volume and can not be inspected without binding it", name)
+		}
+		volume = corev1.Volume{
+			Name: "data",
+			VolumeSource: corev1.VolumeSource{
+				HostPath: &corev1.HostPathVolumeSource{Path: vinfo.HostPath},
 			},
-		},
-		Spec: corev1.PersistentVolumeClaimSpec{
-			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
-			VolumeName:  name,
-			Resources: corev1.ResourceRequirements{
-				Requests: corev1.ResourceList{
-					corev1.ResourceStorage: *resource.NewQuantity(1024*1024*1024, resource.DecimalSI),
+		}
+		affinity = vinfo.Affinity
+	} else {
+		pvcspec := corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: claimname,
+				Labels: map[string]string{
+					"app":      "arangodb",
+					"rebooted": "yes",
 				},
 			},
-			StorageClassName: util.NewString(storageClassName),
-		},
-	}
+			Spec: corev1.PersistentVolumeClaimSpec{
+				AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+				VolumeName:  name,
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceStorage: *resource.NewQuantity(1024*1024*1024, resource.DecimalSI),
+					},
+				},
+				StorageClassName: util.NewString(vinfo.StorageClassName),
+			},
+		}
 
-	_, err := kube.CoreV1().PersistentVolumeClaims(ns).Create(context.Background(), &pvcspec, metav1.CreateOptions{})
-	if err != nil {
-		return "", "", errors.Wrap(err, "failed to create pvc")
-	}
-	defer func() {
-		if deletePVC {
-			cliLog.Debug().Str("pvc-name", claimname).Msg("deleting pvc")
-			kube.CoreV1().PersistentVolumeClaims(ns).Delete(context.Background(), claimname, metav1.DeleteOptions{})
+		_, err := kube.CoreV1().PersistentVolumeClaims(ns).Create(context.Background(), &pvcspec, metav1.CreateOptions{})
+		if err != nil {
+			return "", "", errors.Wrap(err, "failed to create pvc")
 		}
-	}()
+
+		// The claim is kept for the deployment once the volume has been inspected
+		deletePVC = true
+		defer func() {
+			if deletePVC {
+				cliLog.Debug().Str("pvc-name", claimname).Msg("deleting pvc")
+				kube.CoreV1().PersistentVolumeClaims(ns).Delete(context.Background(), claimname, metav1.DeleteOptions{})
+			}
+		}()
+
+		volume = k8sutil.CreateVolumeWithPersitantVolumeClaim("data", claimname)
+	}
 
 	podname := "arangodb-reboot-pod-" + name
 	podspec := corev1.Pod{
@@ -158,6 +214,7 @@ func runVolumeInspector(ctx context.Context, kube kubernetes.Interface, ns, name
 						corev1.VolumeMount{
 							MountPath: "/data",
 							Name:      "data",
+							ReadOnly:  dryRun,
 						},
 					},
 					Ports: []corev1.ContainerPort{
@@ -176,12 +233,13 @@ func runVolumeInspector(ctx context.Context, kube kubernetes.Interface, ns, name
 				},
 			},
 			Volumes: []corev1.Volume{
-				k8sutil.CreateVolumeWithPersitantVolumeClaim("data", claimname),
+				volume,
 			},
+			Affinity: affinity,
 		},
 	}
 
-	_, err = kube.CoreV1().Pods(ns).Create(context.Background(), &podspec, metav1.CreateOptions{})
+	_, err := kube.CoreV1().Pods(ns).Create(context.Background(), &podspec, metav1.CreateOptions{})
 	if err != nil {
 		return "", "", errors.Wrap(err, "failed to create pod")
 	}
@@ -242,6 +300,9 @@ func runVolumeInspector(ctx context.Context, kube kubernetes.Interface, ns, name
 				if info.Error != nil {
 					return "", "", fmt.Errorf("pod returned error: %s", *info.Error)
 				}
+				if info.Result == nil {
+					return "", "", fmt.Errorf("pod returned no result")
+				}
 				deletePVC = false
 
 				return info.Result.UUID, claimname, nil
@@ -250,20 +311,26 @@ func runVolumeInspector(ctx context.Context, kube kubernetes.Interface, ns, name
 	}
 }
 
-func doVolumeInspection(ctx context.Context, kube kubernetes.Interface, ns, name, storageClassName string, resultChan chan<- VolumeInspectResult, image string) {
+// rebootClaimName returns the name of the claim binding the given volume.
+func rebootClaimName(volume string) string {
+	return "arangodb-reboot-pvc-" + volume
+}
+
+func doVolumeInspection(ctx context.Context, kube kubernetes.Interface, ns, name string, vinfo VolumeInfo, resultChan chan<- VolumeInspectResult, image string, dryRun bool) {
 	// Create Volume Claim
 	// Create Pod mounting this volume
 	// Wait for pod to be completed
 	// Read logs - parse json
 	// Delete pod
-	uuid, claim, err := runVolumeInspector(ctx, kube, ns, name, image, storageClassName)
+	uuid, claim, err := runVolumeInspector(ctx, kube, ns, name, image, vinfo, dryRun)
 	if err != nil {
-		resultChan <- VolumeInspectResult{Error: err}
+		resultChan <- VolumeInspectResult{Volume: name, Error: err}
+		return
 	}
-	resultChan <- VolumeInspectResult{UUID: uuid, Claim: claim}
+	resultChan <- VolumeInspectResult{Volume: name, UUID: uuid, Claim: claim}
 }
 
-func checkVolumeAvailable(kube kubernetes.Interface, vname string) (VolumeInfo, error) {
+func checkVolumeAvailable(kube kubernetes.Interface, vname string, dryRun bool) (VolumeInfo, error) {
 	volume, err := kube.CoreV1().PersistentVolumes().Get(context.Background(), vname, metav1.GetOptions{})
 	if err != nil {
 		return VolumeInfo{}, errors.Wrapf(err, "failed to GET volume %s", vname)
@@ -273,6 +340,11 @@ func checkVolumeAvailable(kube kubernetes.Interface, vname string) (VolumeInfo,
 	case corev1.VolumeAvailable:
 		break
 	case corev1.VolumeReleased:
+		if dryRun {
+			// The volume is inspected without binding it, if possible
+			cliLog.Info().Str("volume", vname).Msg("released volume is left unchanged, its claim reference is removed without dry-run")
+			break
+		}
 		// we have to remove the claim reference
 		volume.Spec.ClaimRef = nil
 		if _, err := kube.CoreV1().PersistentVolumes().Update(context.Background(), volume, metav1.UpdateOptions{}); err != nil {
@@ -282,20 +354,39 @@ func checkVolumeAvailable(kube kubernetes.Interface, vname string) (VolumeInfo,
 		return VolumeInfo{}, fmt.Errorf("Volume %s phase is %s, expected %s", vname, volume.Status.Phase, corev1.VolumeAvailable)
 	}
 
-	return VolumeInfo{StorageClassName: volume.Spec.StorageClassName}, nil
+	info := VolumeInfo{StorageClassName: volume.Spec.StorageClassName}
+	switch {
+	case volume.Spec.Local != nil:
+		info.HostPath = volume.Spec.Local.Path
+	case volume.Spec.HostPath != nil:
+		info.HostPath = volume.Spec.HostPath.Path
+	}
+	if info.HostPath != "" && volume.Spec.NodeAffinity != nil && volume.Spec.NodeAffinity.Required != nil {
+		info.Affinity = &corev1.Affinity{
+			NodeAffinity: &corev1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: volume.Spec.NodeAffinity.Required.DeepCopy(),
+			},
+		}
+	}
+
+	return info, nil
 }
 
 type VolumeInfo struct {
 	StorageClassName string
+	// HostPath is the path of a local volume on the node, which allows to inspect it without binding it
+	HostPath string
+	// Affinity pins the inspector pod to the node of a local volume
+	Affinity *corev1.Affinity
 }
 
 type VolumeListInfo map[string]VolumeInfo
 
-func preflightChecks(kube kubernetes.Interface, volumes []string) (VolumeListInfo, error) {
+func preflightChecks(kube kubernetes.Interface, volumes []string, dryRun bool) (VolumeListInfo, error) {
 	info := make(VolumeListInfo)
 	// Check if all values are released
 	for _, vname := range volumes {
-		vi, err := checkVolumeAvailable(kube, vname)
+		vi, err := checkVolumeAvailable(kube, vname, dryRun)
 		if err != nil {
 			return nil, errors.Wrap(err, "preflight checks failed")
 		}
@@ -305,6 +396,62 @@ func preflightChecks(kube kubernetes.Interface, volumes []string) (VolumeListInf
 	return info, nil
 }
 
+// checkMembers verifies that the inspected volumes contain exactly one agency quorum of the given size
+// (unless the deployment runs without agency) and that every member is found only once.
+// All detected problems are reported.
+func checkMembers(results []VolumeInspectResult, agents int) error {
+	var problems []string
+	uuids := map[string]string{}
+	found := 0
+
+	for _, res := range results {
+		if res.Error != nil {
+			problems = append(problems, fmt.Sprintf("volume %s could not be inspected: %s", res.Volume, res.Error))
+			continue
+		}
+
+		group, ok := res.Role()
+		if !ok {
+			problems = append(problems, fmt.Sprintf("volume %s contains unknown server type by uuid: %s", res.Volume, res.UUID))
+			continue
+		}
+
+		if res.LabelGroup != deplv1.ServerGroupUnknown && res.LabelGroup != group {
+			problems = append(problems, fmt.Sprintf("volume %s is labeled as %s but contains %s %s", res.Volume,
+				res.LabelGroup.AsRole(), group.AsRole(), res.UUID))
+		}
+
+		if other, ok := uuids[res.UUID]; ok {
+			problems = append(problems, fmt.Sprintf("volumes %s and %s contain the same member %s", other, res.Volume, res.UUID))
+			continue
+		}
+		uuids[res.UUID] = res.Volume
+
+		if group == deplv1.ServerGroupAgents {
+			found++
+		}
+	}
+
+	if mode, err := getRebootMode(results); err != nil {
+		problems = append(problems, err.Error())
+	} else if mode == deplv1.DeploymentModeSingle {
+		agents = 0
+	}
+
+	switch {
+	case found > agents:
+		problems = append(problems, fmt.Sprintf("found %d agents, expected one agency quorum of %d agents", found, agents))
+	case found < agents:
+		problems = append(problems, fmt.Sprintf("missing %d of %d agents", agents-found, agents))
+	}
+
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
 func getMyImage(kube kubernetes.Interface, ns, name string) (string, error) {
 	pod, err := kube.CoreV1().Pods(ns).Get(context.Background(), name, metav1.GetOptions{})
 	if err != nil {
@@ -314,59 +461,171 @@ func getMyImage(kube kubernetes.Interface, ns, name string) (string, error) {
 	return pod.Spec.Containers[0].Image, nil
 }
 
-func createArangoDeployment(cli acli.Interface, ns, deplname, arangoimage string, results map[string]VolumeInspectResult) error {
+// buildArangoDeployment returns the ArangoDeployment which adopts the members found on the inspected volumes.
+// Fields which are not set by the reboot options are taken from the spec of the template, if provided.
+func buildArangoDeployment(deplname, arangoimage string, coordinators int, licenseSecretName string,
+	results []VolumeInspectResult, template *deplv1.ArangoDeployment) (deplv1.ArangoDeployment, error) {
 
-	prmr := make(map[string]VolumeInspectResult)
-	agnt := make(map[string]VolumeInspectResult)
+	mode, err := getRebootMode(results)
+	if err != nil {
+		return deplv1.ArangoDeployment{}, err
+	}
 
-	for vname, info := range results {
-		if strings.HasPrefix(info.UUID, "PRMR") {
-			prmr[vname] = info
-		} else if strings.HasPrefix(info.UUID, "AGNT") {
-			agnt[vname] = info
-		} else {
-			return fmt.Errorf("unknown server type by uuid: %s", info.UUID)
+	members := make(map[deplv1.ServerGroup][]VolumeInspectResult)
+	for _, info := range results {
+		if info.Error != nil {
+			// Volumes which could not be inspected are reported by the preflight checks
+			continue
 		}
+
+		group, _ := info.Role()
+		members[group] = append(members[group], info)
 	}
 
 	depl := deplv1.ArangoDeployment{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: deplv1.SchemeGroupVersion.String(),
+			Kind:       deployment.ArangoDeploymentResourceKind,
+		},
 		ObjectMeta: metav1.ObjectMeta{
 			Name: deplname,
 		},
 		Spec: deplv1.DeploymentSpec{
-			Image: util.NewString(arangoimage),
-			Coordinators: deplv1.ServerGroupSpec{
-				Count: util.NewInt(rebootOptions.Coordinators),
-			},
-			Agents: deplv1.ServerGroupSpec{
-				Count: util.NewInt(len(agnt)),
-			},
-			DBServers: deplv1.ServerGroupSpec{
-				Count: util.NewInt(len(prmr)),
-			},
+			Mode: deplv1.NewMode(mode),
 		},
 	}
 
-	if rebootOptions.LicenseSecretName != "" {
-		depl.Spec.License.SecretName = util.NewString(rebootOptions.LicenseSecretName)
+	if arangoimage != "" {
+		depl.Spec.Image = util.NewString(arangoimage)
+	}
+
+	switch mode {
+	case deplv1.DeploymentModeCluster:
+		depl.Spec.Agents.Count = util.NewInt(len(members[deplv1.ServerGroupAgents]))
+		depl.Spec.DBServers.Count = util.NewInt(len(members[deplv1.ServerGroupDBServers]))
+		if coordinators > 0 {
+			depl.Spec.Coordinators.Count = util.NewInt(coordinators)
+		}
+	case deplv1.DeploymentModeActiveFailover:
+		depl.Spec.Agents.Count = util.NewInt(len(members[deplv1.ServerGroupAgents]))
+		depl.Spec.Single.Count = util.NewInt(len(members[deplv1.ServerGroupSingle]))
+	}
+
+	if licenseSecretName != "" {
+		depl.Spec.License.SecretName = util.NewString(licenseSecretName)
+	}
+
+	if template != nil {
+		depl.Labels = template.GetLabels()
+		depl.Annotations = template.GetAnnotations()
+		depl.Spec.SetDefaultsFrom(template.Spec)
 	}
 
-	for _, info := range agnt {
-		depl.Status.Members.Agents = append(depl.Status.Members.Agents, deplv1.MemberStatus{
-			ID:                        info.UUID,
-			PersistentVolumeClaimName: info.Claim,
-			PodName:                   k8sutil.CreatePodName(deplname, deplv1.ServerGroupAgents.AsRole(), info.UUID, "-rbt"),
-		})
+	for _, group := range []deplv1.ServerGroup{deplv1.ServerGroupAgents, deplv1.ServerGroupDBServers, deplv1.ServerGroupSingle} {
+		for _, info := range members[group] {
+			if err := depl.Status.Members.Add(deplv1.MemberStatus{
+				ID:                        info.UUID,
+				PersistentVolumeClaimName: info.Claim,
+				PodName:                   k8sutil.CreatePodName(deplname, group.AsRole(), info.UUID, "-rbt"),
+			}, group); err != nil {
+				return deplv1.ArangoDeployment{}, err
+			}
+		}
 	}
 
-	for _, info := range prmr {
-		depl.Status.Members.DBServers = append(depl.Status.Members.DBServers, deplv1.MemberStatus{
-			ID:                        info.UUID,
-			PersistentVolumeClaimName: info.Claim,
-			PodName:                   k8sutil.CreatePodName(deplname, deplv1.ServerGroupDBServers.AsRole(), info.UUID, "-rbt"),
-		})
+	spec := depl.Spec.DeepCopy()
+	spec.SetDefaults(deplname)
+	if err := spec.Validate(); err != nil {
+		return deplv1.ArangoDeployment{}, errors.Wrap(err, "invalid deployment spec")
 	}
 
+	return depl, nil
+}
+
+// getRebootMode returns the mode of the deployment based on the members found on the inspected volumes.
+func getRebootMode(results []VolumeInspectResult) (deplv1.DeploymentMode, error) {
+	groups := make(map[deplv1.ServerGroup]int)
+	for _, info := range results {
+		if info.Error != nil {
+			continue
+		}
+
+		group, ok := info.Role()
+		if !ok {
+			return "", fmt.Errorf("unknown server type by uuid: %s", info.UUID)
+		}
+		groups[group]++
+	}
+
+	switch {
+	case groups[deplv1.ServerGroupSingle] > 0 && groups[deplv1.ServerGroupDBServers] > 0:
+		return "", fmt.Errorf("volumes contain both single servers and dbservers")
+	case groups[deplv1.ServerGroupSingle] > 0 && groups[deplv1.ServerGroupAgents] > 0:
+		return deplv1.DeploymentModeActiveFailover, nil
+	case groups[deplv1.ServerGroupSingle] > 0:
+		return deplv1.DeploymentModeSingle, nil
+	default:
+		return deplv1.DeploymentModeCluster, nil
+	}
+}
+
+// rebootImageAndCoordinators returns the image and coordinator count passed to buildArangoDeployment.
+// Values from the template take precedence over the defaults of the reboot options, but not over
+// options set explicitly. An empty image or zero coordinators leave the value of the template in place.
+func rebootImageAndCoordinators(template *deplv1.ArangoDeployment, image string, imageSet bool,
+	coordinators int, coordinatorsSet bool) (string, int) {
+	if template == nil {
+		return image, coordinators
+	}
+	if template.Spec.Image != nil && !imageSet {
+		image = ""
+	}
+	if template.Spec.Coordinators.Count != nil && !coordinatorsSet {
+		coordinators = 0
+	}
+	return image, coordinators
+}
+
+// loadDeploymentTemplate reads the ArangoDeployment used as template from the given YAML file.
+func loadDeploymentTemplate(fileName string) (*deplv1.ArangoDeployment, error) {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read template")
+	}
+
+	var template deplv1.ArangoDeployment
+	if err := yaml.Unmarshal(data, &template); err != nil {
+		return nil, errors.Wrap(err, "failed to parse template")
+	}
+
+	return &template, nil
+}
+
+// discoverVolumes returns the persistent volumes labeled by the operator for the given deployment
+// together with the server group from the role label.
+func discoverVolumes(kube kubernetes.Interface, deplname string) (map[string]deplv1.ServerGroup, error) {
+	list, err := kube.CoreV1().PersistentVolumes().List(context.Background(), metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", k8sutil.LabelKeyArangoDeployment, deplname),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list volumes")
+	}
+
+	volumes := make(map[string]deplv1.ServerGroup, len(list.Items))
+	for _, pv := range list.Items {
+		group := deplv1.ServerGroupFromRole(pv.GetLabels()[k8sutil.LabelKeyRole])
+		switch group {
+		case deplv1.ServerGroupAgents, deplv1.ServerGroupDBServers, deplv1.ServerGroupSingle:
+			volumes[pv.GetName()] = group
+		default:
+			cliLog.Warn().Str("volume", pv.GetName()).Msg("volume has no known role label, skipping")
+		}
+	}
+
+	return volumes, nil
+}
+
+func createArangoDeployment(cli acli.Interface, ns string, depl deplv1.ArangoDeployment) error {
 	if _, err := cli.DatabaseV1().ArangoDeployments(ns).Create(context.Background(), &depl, metav1.CreateOptions{}); err != nil {
 		return errors.Wrap(err, "failed to create ArangoDeployment")
 	}
@@ -374,9 +633,28 @@ func createArangoDeployment(cli acli.Interface, ns, deplname, arangoimage string
 	return nil
 }
 
+// printInspectionResults prints the detected role and UUID per volume.
+func printInspectionResults(results []VolumeInspectResult) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "VOLUME\tCLAIM\tROLE\tUUID\tERROR")
+	for _, res := range results {
+		role := ""
+		if group, ok := res.Role(); ok {
+			role = group.AsRole()
+		}
+		inspectErr := ""
+		if res.Error != nil {
+			inspectErr = res.Error.Error()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", res.Volume, res.Claim, role, res.UUID, inspectErr)
+	}
+	w.Flush()
+}
+
 func cmdRebootRun(cmd *cobra.Command, args []string) {
 
 	volumes := args
+	labels := make(map[string]deplv1.ServerGroup)
 	namespace := os.Getenv(constants.EnvOperatorPodNamespace)
 	podname := os.Getenv(constants.EnvOperatorPodName)
 
@@ -391,12 +669,41 @@ func cmdRebootRun(cmd *cobra.Command, args []string) {
 		cliLog.Fatal().Err(err).Msg("failed to create arango extension client")
 	}
 
+	if rebootOptions.FromDeployment != "" {
+		labels, err = discoverVolumes(kubecli, rebootOptions.FromDeployment)
+		if err != nil {
+			cliLog.Fatal().Err(err).Msg("failed to discover volumes")
+		}
+		given := make(map[string]bool, len(volumes))
+		for _, vname := range volumes {
+			given[vname] = true
+		}
+		for vname := range labels {
+			if !given[vname] {
+				volumes = append(volumes, vname)
+			}
+		}
+		sort.Strings(volumes)
+	}
+
+	if len(volumes) == 0 {
+		cliLog.Fatal().Msg("no volumes given or discovered")
+	}
+
+	var template *deplv1.ArangoDeployment
+	if rebootOptions.Template != "" {
+		template, err = loadDeploymentTemplate(rebootOptions.Template)
+		if err != nil {
+			cliLog.Fatal().Err(err).Msg("failed to load template")
+		}
+	}
+
 	image, err := getMyImage(kubecli, namespace, podname)
 	if err != nil {
 		cliLog.Fatal().Err(err).Msg("failed to get my image")
 	}
 
-	vinfo, err := preflightChecks(kubecli, volumes)
+	vinfo, err := preflightChecks(kubecli, volumes, rebootOptions.DryRun)
 	if err != nil {
 		cliLog.Fatal().Err(err).Msg("preflight checks failed")
 	}
@@ -411,11 +718,11 @@ func cmdRebootRun(cmd *cobra.Command, args []string) {
 		wg.Add(1)
 		go func(vn string) {
 			defer wg.Done()
-			doVolumeInspection(ctx, kubecli, namespace, vn, vinfo[vn].StorageClassName, resultChan, image)
+			doVolumeInspection(ctx, kubecli, namespace, vn, vinfo[vn], resultChan, image, rebootOptions.DryRun)
 		}(volumeName)
 	}
 
-	members := make(map[string]VolumeInspectResult)
+	members := make(map[string]VolumeInspectResult, len(volumes))
 
 	for {
 		if received == len(volumes) {
@@ -429,23 +736,65 @@ func cmdRebootRun(cmd *cobra.Command, args []string) {
 			} else {
 				cliLog.Info().Str("claim", res.Claim).Str("uuid", res.UUID).Msg("Inspection completed")
 			}
-			members[res.UUID] = res
+			members[res.Volume] = res
 			received++
 		case <-ctx.Done():
 			panic(ctx.Err())
 		}
 	}
 
+	// Wait for everyone to be completed
+	wg.Wait()
+
+	results := make([]VolumeInspectResult, 0, len(volumes))
+	for _, volumeName := range volumes {
+		res := members[volumeName]
+		res.LabelGroup = labels[volumeName]
+		results = append(results, res)
+	}
+
+	printInspectionResults(results)
+
+	if err := checkMembers(results, rebootOptions.Agents); err != nil {
+		if !rebootOptions.DryRun {
+			cliLog.Fatal().Err(err).Msg("preflight checks failed")
+		}
+		cliLog.Warn().Err(err).Msg("preflight checks failed")
+	}
+
 	cliLog.Debug().Msg("results complete - generating ArangoDeployment resource")
 
-	if err := createArangoDeployment(extcli, namespace, rebootOptions.DeploymentName, rebootOptions.ImageName, members); err != nil {
-		cliLog.Error().Err(err).Msg("failed to create deployment")
+	arangoimage, coordinators := rebootImageAndCoordinators(template, rebootOptions.ImageName, cmd.Flags().Changed("image-name"),
+		rebootOptions.Coordinators, cmd.Flags().Changed("coordinators"))
+
+	depl, err := buildArangoDeployment(rebootOptions.DeploymentName, arangoimage, coordinators, rebootOptions.LicenseSecretName,
+		results, template)
+	if err != nil {
+		cliLog.Fatal().Err(err).Msg("failed to generate deployment")
 	}
 
-	cliLog.Info().Msg("ArangoDeployment created.")
+	data, err := yaml.Marshal(depl)
+	if err != nil {
+		cliLog.Fatal().Err(err).Msg("failed to marshal deployment")
+	}
 
-	// Wait for everyone to be completed
-	wg.Wait()
+	if rebootOptions.Output != "" {
+		if err := ioutil.WriteFile(rebootOptions.Output, data, 0644); err != nil {
+			cliLog.Fatal().Err(err).Msg("failed to write deployment")
+		}
+		cliLog.Info().Str("file", rebootOptions.Output).Msg("ArangoDeployment written.")
+	}
+
+	if rebootOptions.DryRun {
+		os.Stdout.Write(data)
+		return
+	}
+
+	if err := createArangoDeployment(extcli, namespace, depl); err != nil {
+		cliLog.Fatal().Err(err).Msg("failed to create deployment")
+	}
+
+	cliLog.Info().Msg("ArangoDeployment created.")
 }
 
 // inspectDatabaseDirectory inspects the given directory and returns the inspection result or an error
@@ -0,0 +1,491 @@
+//
+// DISCLAIMER
+//
+// Copyright 2020 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package main
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	deplv1 "github.com/arangodb/kube-arangodb/pkg/apis/deployment/v1"
+	"github.com/arangodb/kube-arangodb/pkg/util"
+	"github.com/arangodb/kube-arangodb/pkg/util/k8sutil"
+)
+
+func TestCheckMembers(t *testing.T) {
+	testCases := []struct {
+		name     string
+		results  []VolumeInspectResult
+		agents   int
+		problems []string
+	}{
+		{
+			name: "Cluster",
+			results: []VolumeInspectResult{
+				{Volume: "v1", UUID: "AGNT-1"},
+				{Volume: "v2", UUID: "AGNT-2"},
+				{Volume: "v3", UUID: "AGNT-3"},
+				{Volume: "v4", UUID: "PRMR-1"},
+			},
+			agents: 3,
+		},
+		{
+			name: "Single",
+			results: []VolumeInspectResult{
+				{Volume: "v1", UUID: "SNGL-1"},
+			},
+			agents: 3,
+		},
+		{
+			name: "Missing agents",
+			results: []VolumeInspectResult{
+				{Volume: "v1", UUID: "AGNT-1"},
+				{Volume: "v2", UUID: "PRMR-1"},
+			},
+			agents:   3,
+			problems: []string{"missing 2 of 3 agents"},
+		},
+		{
+			name: "Too many agents",
+			results: []VolumeInspectResult{
+				{Volume: "v1", UUID: "AGNT-1"},
+				{Volume: "v2", UUID: "AGNT-2"},
+			},
+			agents:   1,
+			problems: []string{"found 2 agents, expected one agency quorum of 1 agents"},
+		},
+		{
+			name: "Duplicated member",
+			results: []VolumeInspectResult{
+				{Volume: "v1", UUID: "AGNT-1"},
+				{Volume: "v2", UUID: "PRMR-1"},
+				{Volume: "v3", UUID: "PRMR-1"},
+			},
+			agents:   1,
+			problems: []string{"volumes v2 and v3 contain the same member PRMR-1"},
+		},
+		{
+			name: "Label mismatch",
+			results: []VolumeInspectResult{
+				{Volume: "v1", UUID: "AGNT-1"},
+				{Volume: "v2", UUID: "PRMR-1", LabelGroup: deplv1.ServerGroupAgents},
+			},
+			agents:   1,
+			problems: []string{"volume v2 is labeled as agent but contains dbserver PRMR-1"},
+		},
+		{
+			name: "Inspection failed",
+			results: []VolumeInspectResult{
+				{Volume: "v1", UUID: "AGNT-1"},
+				{Volume: "v2", Error: errors.New("not a local volume")},
+			},
+			agents:   1,
+			problems: []string{"volume v2 could not be inspected: not a local volume"},
+		},
+		{
+			name: "Unknown member",
+			results: []VolumeInspectResult{
+				{Volume: "v1", UUID: "AGNT-1"},
+				{Volume: "v2", UUID: "CRDN-1"},
+			},
+			agents: 1,
+			problems: []string{
+				"volume v2 contains unknown server type by uuid: CRDN-1",
+				"unknown server type by uuid: CRDN-1",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := checkMembers(testCase.results, testCase.agents)
+			if len(testCase.problems) == 0 {
+				require.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+			for _, problem := range testCase.problems {
+				assert.Contains(t, err.Error(), problem)
+			}
+		})
+	}
+}
+
+func TestBuildArangoDeployment(t *testing.T) {
+	testCases := []struct {
+		name         string
+		coordinators int
+		license      string
+		results      []VolumeInspectResult
+		template     *deplv1.ArangoDeployment
+		expectError  bool
+		check        func(t *testing.T, depl deplv1.ArangoDeployment)
+	}{
+		{
+			name:         "Cluster",
+			coordinators: 2,
+			license:      "license",
+			results: []VolumeInspectResult{
+				{Volume: "v1", UUID: "AGNT-1", Claim: "c1"},
+				{Volume: "v2", UUID: "PRMR-1", Claim: "c2"},
+				{Volume: "v3", UUID: "PRMR-2", Claim: "c3"},
+				{Volume: "v4", Error: errors.New("failed")},
+			},
+			check: func(t *testing.T, depl deplv1.ArangoDeployment) {
+				assert.Equal(t, deplv1.DeploymentModeCluster, depl.Spec.GetMode())
+				assert.Equal(t, "image", depl.Spec.GetImage())
+				assert.Equal(t, 1, depl.Spec.Agents.GetCount())
+				assert.Equal(t, 2, depl.Spec.DBServers.GetCount())
+				assert.Equal(t, 2, depl.Spec.Coordinators.GetCount())
+				assert.Equal(t, "license", depl.Spec.License.GetSecretName())
+
+				require.Len(t, depl.Status.Members.Agents, 1)
+				assert.Equal(t, "AGNT-1", depl.Status.Members.Agents[0].ID)
+				assert.Equal(t, "c1", depl.Status.Members.Agents[0].PersistentVolumeClaimName)
+				require.Len(t, depl.Status.Members.DBServers, 2)
+				assert.Equal(t, "c3", depl.Status.Members.DBServers[1].PersistentVolumeClaimName)
+			},
+		},
+		{
+			name: "ActiveFailover",
+			results: []VolumeInspectResult{
+				{Volume: "v1", UUID: "AGNT-1"},
+				{Volume: "v2", UUID: "SNGL-1"},
+				{Volume: "v3", UUID: "SNGL-2"},
+			},
+			check: func(t *testing.T, depl deplv1.ArangoDeployment) {
+				assert.Equal(t, deplv1.DeploymentModeActiveFailover, depl.Spec.GetMode())
+				assert.Equal(t, 1, depl.Spec.Agents.GetCount())
+				assert.Equal(t, 2, depl.Spec.Single.GetCount())
+				assert.Len(t, depl.Status.Members.Single, 2)
+			},
+		},
+		{
+			name: "Single",
+			results: []VolumeInspectResult{
+				{Volume: "v1", UUID: "SNGL-1"},
+			},
+			check: func(t *testing.T, depl deplv1.ArangoDeployment) {
+				assert.Equal(t, deplv1.DeploymentModeSingle, depl.Spec.GetMode())
+				assert.Len(t, depl.Status.Members.Single, 1)
+				assert.False(t, depl.Spec.License.HasSecretName())
+			},
+		},
+		{
+			name: "Template",
+			results: []VolumeInspectResult{
+				{Volume: "v1", UUID: "AGNT-1"},
+				{Volume: "v2", UUID: "AGNT-2"},
+				{Volume: "v3", UUID: "AGNT-3"},
+				{Volume: "v4", UUID: "PRMR-1"},
+				{Volume: "v5", UUID: "PRMR-2"},
+			},
+			template: &deplv1.ArangoDeployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{"team": "db"},
+				},
+				Spec: deplv1.DeploymentSpec{
+					Environment: deplv1.NewEnvironment(deplv1.EnvironmentProduction),
+					DBServers: deplv1.ServerGroupSpec{
+						Count: util.NewInt(5),
+					},
+				},
+			},
+			check: func(t *testing.T, depl deplv1.ArangoDeployment) {
+				assert.Equal(t, map[string]string{"team": "db"}, depl.GetLabels())
+				assert.Equal(t, deplv1.EnvironmentProduction, depl.Spec.GetEnvironment())
+				// Counts are taken from the inspected volumes
+				assert.Equal(t, 3, depl.Spec.Agents.GetCount())
+				assert.Equal(t, 2, depl.Spec.DBServers.GetCount())
+			},
+		},
+		{
+			name: "Too few dbservers",
+			results: []VolumeInspectResult{
+				{Volume: "v1", UUID: "AGNT-1"},
+				{Volume: "v2", UUID: "PRMR-1"},
+			},
+			expectError: true,
+		},
+		{
+			name: "Single servers and dbservers",
+			results: []VolumeInspectResult{
+				{Volume: "v1", UUID: "SNGL-1"},
+				{Volume: "v2", UUID: "PRMR-1"},
+			},
+			expectError: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			depl, err := buildArangoDeployment("test", "image", testCase.coordinators, testCase.license, testCase.results,
+				testCase.template)
+			if testCase.expectError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, "test", depl.GetName())
+			testCase.check(t, depl)
+		})
+	}
+}
+
+func TestCheckVolumeAvailable_DryRun(t *testing.T) {
+	required := &corev1.NodeSelector{
+		NodeSelectorTerms: []corev1.NodeSelectorTerm{
+			{
+				MatchExpressions: []corev1.NodeSelectorRequirement{
+					{Key: "kubernetes.io/hostname", Operator: corev1.NodeSelectorOpIn, Values: []string{"node-1"}},
+				},
+			},
+		},
+	}
+	kube := fake.NewSimpleClientset(&corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "pv"},
+		Spec: corev1.PersistentVolumeSpec{
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				Local: &corev1.LocalVolumeSource{Path: "/var/lib/arangodb/pv"},
+			},
+			NodeAffinity:     &corev1.VolumeNodeAffinity{Required: required},
+			ClaimRef:         &corev1.ObjectReference{Name: "claim"},
+			StorageClassName: "local",
+		},
+		Status: corev1.PersistentVolumeStatus{Phase: corev1.VolumeReleased},
+	})
+
+	info, err := checkVolumeAvailable(kube, "pv", true)
+	require.NoError(t, err)
+	assert.Equal(t, "local", info.StorageClassName)
+	assert.Equal(t, "/var/lib/arangodb/pv", info.HostPath)
+	require.NotNil(t, info.Affinity)
+	assert.Equal(t, required, info.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution)
+
+	// The claim reference is kept in dry-run mode
+	pv, err := kube.CoreV1().PersistentVolumes().Get(context.Background(), "pv", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotNil(t, pv.Spec.ClaimRef)
+
+	// Volumes which are not local can not be inspected without binding them
+	_, _, err = runVolumeInspector(context.Background(), kube, "ns", "pv", "image", VolumeInfo{}, true)
+	require.Error(t, err)
+	list, err := kube.CoreV1().PersistentVolumeClaims("ns").List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, list.Items)
+}
+
+func TestDiscoverVolumes(t *testing.T) {
+	newPV := func(name string, labels map[string]string) *corev1.PersistentVolume {
+		return &corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		}
+	}
+	labels := func(deployment, role string) map[string]string {
+		l := map[string]string{k8sutil.LabelKeyArangoDeployment: deployment}
+		if role != "" {
+			l[k8sutil.LabelKeyRole] = role
+		}
+		return l
+	}
+
+	testCases := []struct {
+		name     string
+		volumes  []*corev1.PersistentVolume
+		expected map[string]deplv1.ServerGroup
+	}{
+		{
+			name:     "No volumes",
+			expected: map[string]deplv1.ServerGroup{},
+		},
+		{
+			name: "Cluster",
+			volumes: []*corev1.PersistentVolume{
+				newPV("pv-agent", labels("test", "agent")),
+				newPV("pv-dbserver-1", labels("test", "dbserver")),
+				newPV("pv-dbserver-2", labels("test", "dbserver")),
+			},
+			expected: map[string]deplv1.ServerGroup{
+				"pv-agent":      deplv1.ServerGroupAgents,
+				"pv-dbserver-1": deplv1.ServerGroupDBServers,
+				"pv-dbserver-2": deplv1.ServerGroupDBServers,
+			},
+		},
+		{
+			name: "Single",
+			volumes: []*corev1.PersistentVolume{
+				newPV("pv-single", labels("test", "single")),
+			},
+			expected: map[string]deplv1.ServerGroup{
+				"pv-single": deplv1.ServerGroupSingle,
+			},
+		},
+		{
+			name: "Volumes without known role",
+			volumes: []*corev1.PersistentVolume{
+				newPV("pv-agent", labels("test", "agent")),
+				newPV("pv-no-role", labels("test", "")),
+				newPV("pv-coordinator", labels("test", "coordinator")),
+				newPV("pv-unknown", labels("test", "unknown")),
+			},
+			expected: map[string]deplv1.ServerGroup{
+				"pv-agent": deplv1.ServerGroupAgents,
+			},
+		},
+		{
+			name: "Volumes of other deployments",
+			volumes: []*corev1.PersistentVolume{
+				newPV("pv-agent", labels("test", "agent")),
+				newPV("pv-other", labels("other", "agent")),
+				newPV("pv-unlabeled", nil),
+			},
+			expected: map[string]deplv1.ServerGroup{
+				"pv-agent": deplv1.ServerGroupAgents,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			kube := fake.NewSimpleClientset()
+			for _, pv := range testCase.volumes {
+				_, err := kube.CoreV1().PersistentVolumes().Create(context.Background(), pv, metav1.CreateOptions{})
+				require.NoError(t, err)
+			}
+
+			volumes, err := discoverVolumes(kube, "test")
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expected, volumes)
+		})
+	}
+}
+
+func TestLoadDeploymentTemplate(t *testing.T) {
+	dir := t.TempDir()
+
+	valid := filepath.Join(dir, "valid.yaml")
+	require.NoError(t, ioutil.WriteFile(valid, []byte(`apiVersion: database.arangodb.com/v1
+kind: ArangoDeployment
+metadata:
+  name: template
+  labels:
+    team: db
+spec:
+  image: arangodb/enterprise:3.8
+  environment: Production
+  coordinators:
+    count: 3
+`), 0644))
+	invalid := filepath.Join(dir, "invalid.yaml")
+	require.NoError(t, ioutil.WriteFile(invalid, []byte("spec: ["), 0644))
+
+	template, err := loadDeploymentTemplate(valid)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"team": "db"}, template.GetLabels())
+	assert.Equal(t, "arangodb/enterprise:3.8", template.Spec.GetImage())
+	assert.Equal(t, deplv1.EnvironmentProduction, template.Spec.GetEnvironment())
+	assert.Equal(t, 3, template.Spec.Coordinators.GetCount())
+
+	_, err = loadDeploymentTemplate(invalid)
+	assert.Error(t, err)
+
+	_, err = loadDeploymentTemplate(filepath.Join(dir, "missing.yaml"))
+	assert.Error(t, err)
+}
+
+func TestRebootTemplateMerging(t *testing.T) {
+	template := &deplv1.ArangoDeployment{
+		Spec: deplv1.DeploymentSpec{
+			Image: util.NewString("template-image"),
+			Coordinators: deplv1.ServerGroupSpec{
+				Count: util.NewInt(3),
+			},
+		},
+	}
+	results := []VolumeInspectResult{
+		{Volume: "v1", UUID: "AGNT-1"},
+		{Volume: "v2", UUID: "PRMR-1"},
+		{Volume: "v3", UUID: "PRMR-2"},
+	}
+
+	testCases := []struct {
+		name                 string
+		template             *deplv1.ArangoDeployment
+		imageSet             bool
+		coordinatorsSet      bool
+		expectedImage        string
+		expectedCoordinators int
+	}{
+		{
+			name:                 "Without template",
+			expectedImage:        "flag-image",
+			expectedCoordinators: 1,
+		},
+		{
+			name:                 "Template wins over defaults",
+			template:             template,
+			expectedImage:        "template-image",
+			expectedCoordinators: 3,
+		},
+		{
+			name:                 "Explicit image",
+			template:             template,
+			imageSet:             true,
+			expectedImage:        "flag-image",
+			expectedCoordinators: 3,
+		},
+		{
+			name:                 "Explicit coordinators",
+			template:             template,
+			coordinatorsSet:      true,
+			expectedImage:        "template-image",
+			expectedCoordinators: 1,
+		},
+		{
+			name:                 "Template without image and coordinators",
+			template:             &deplv1.ArangoDeployment{},
+			expectedImage:        "flag-image",
+			expectedCoordinators: 1,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			image, coordinators := rebootImageAndCoordinators(testCase.template, "flag-image", testCase.imageSet,
+				1, testCase.coordinatorsSet)
+
+			depl, err := buildArangoDeployment("test", image, coordinators, "", results, testCase.template)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expectedImage, depl.Spec.GetImage())
+			assert.Equal(t, testCase.expectedCoordinators, depl.Spec.Coordinators.GetCount())
+		})
+	}
+}
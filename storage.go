@@ -50,7 +50,8 @@ var (
 	}
 
 	storageProvisioner struct {
-		port int
+		port        int
+		enableQuota bool
 	}
 )
 
@@ -60,6 +61,7 @@ func init() {
 
 	f := cmdStorageProvisioner.Flags()
 	f.IntVar(&storageProvisioner.port, "port", provisioner.DefaultPort, "Port to listen on")
+	f.BoolVar(&storageProvisioner.enableQuota, "enable-quota", false, "Limit volumes to their capacity using XFS/ext4 project quotas")
 }
 
 // Run the provisioner
@@ -94,8 +96,9 @@ func cmdStorageProvisionerRun(cmd *cobra.Command, args []string) {
 // newProvisionerConfigAndDeps creates storage provisioner config & dependencies.
 func newProvisionerConfigAndDeps(nodeName string) (service.Config, service.Dependencies) {
 	cfg := service.Config{
-		Address:  net.JoinHostPort("0.0.0.0", strconv.Itoa(storageProvisioner.port)),
-		NodeName: nodeName,
+		Address:     net.JoinHostPort("0.0.0.0", strconv.Itoa(storageProvisioner.port)),
+		NodeName:    nodeName,
+		EnableQuota: storageProvisioner.enableQuota,
 	}
 	deps := service.Dependencies{
 		Log: logService.MustGetLogger(logging.LoggerNameProvisioner),